@@ -0,0 +1,89 @@
+package nrzerolog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+// Writer is a zerolog.LevelWriter that forwards every log event written to
+// it to New Relic before passing the event on to Out. Since zerolog events
+// arrive already serialized, the writer re-parses the JSON to extract the
+// level and message. When Context holds a transaction, the log is recorded
+// on that transaction so it is decorated with its trace and span IDs.
+//
+//	logger := zerolog.New(nrzerolog.Writer{
+//		Out: os.Stdout,
+//		App: app,
+//	})
+type Writer struct {
+	Out     io.Writer
+	App     *newrelic.Application
+	Context context.Context
+}
+
+// WithContext returns a copy of the Writer that records logs on the
+// transaction found in ctx, if any.
+func (w Writer) WithContext(ctx context.Context) Writer {
+	w.Context = ctx
+	return w
+}
+
+// Write implements io.Writer.
+func (w Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.record(parseLogData(level, p))
+
+	if w.Out == nil {
+		return len(p), nil
+	}
+	if lw, ok := w.Out.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.Out.Write(p)
+}
+
+func (w Writer) record(data newrelic.LogData) {
+	var txn *newrelic.Transaction
+	if w.Context != nil {
+		txn = newrelic.FromContext(w.Context)
+	}
+
+	if txn != nil {
+		txn.RecordLog(data)
+	} else if w.App != nil {
+		w.App.RecordLog(data)
+	}
+}
+
+// parseLogData extracts the severity and message from a serialized zerolog
+// event. The level field of the event takes precedence over the level
+// passed by zerolog. If the event is not valid JSON, the whole payload is
+// used as the message.
+func parseLogData(level zerolog.Level, p []byte) newrelic.LogData {
+	data := newrelic.LogData{}
+	if level != zerolog.NoLevel {
+		data.Severity = level.String()
+	}
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(p, &event); err != nil {
+		data.Message = string(p)
+		return data
+	}
+
+	if severity, ok := event[zerolog.LevelFieldName].(string); ok {
+		data.Severity = severity
+	}
+	if message, ok := event[zerolog.MessageFieldName].(string); ok {
+		data.Message = message
+	}
+	return data
+}
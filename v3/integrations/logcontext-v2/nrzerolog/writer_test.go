@@ -0,0 +1,101 @@
+package nrzerolog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	"github.com/newrelic/go-agent/v3/newrelic"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWriterBackgroundLog(t *testing.T) {
+	app := integrationsupport.NewTestApp(integrationsupport.SampleEverythingReplyFn,
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	out := bytes.NewBuffer([]byte{})
+	log := zerolog.New(Writer{Out: out, App: app.Application})
+	message := "Hello World!"
+	log.Warn().Str("key", "value").Msg(message)
+
+	if out.Len() == 0 {
+		t.Error("expected log to be written to the output writer")
+	}
+
+	app.ExpectLogEvents(t, []internal.WantLog{
+		{
+			Severity:  zerolog.WarnLevel.String(),
+			Message:   message,
+			Timestamp: internal.MatchAnyUnixMilli,
+		},
+	})
+}
+
+func TestWriterLogInContext(t *testing.T) {
+	app := integrationsupport.NewTestApp(integrationsupport.SampleEverythingReplyFn,
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	txn := app.StartTransaction("test txn")
+	ctx := newrelic.NewContext(context.Background(), txn)
+	w := Writer{App: app.Application}
+	log := zerolog.New(w.WithContext(ctx))
+	message := "Hello World!"
+	log.Error().Msg(message)
+
+	txn.ExpectLogEvents(t, []internal.WantLog{
+		{
+			Severity:  zerolog.ErrorLevel.String(),
+			Message:   message,
+			Timestamp: internal.MatchAnyUnixMilli,
+			SpanID:    txn.GetLinkingMetadata().SpanID,
+			TraceID:   txn.GetLinkingMetadata().TraceID,
+		},
+	})
+
+	txn.End()
+}
+
+func TestParseLogData(t *testing.T) {
+	testcases := []struct {
+		name     string
+		level    zerolog.Level
+		input    string
+		severity string
+		message  string
+	}{
+		{
+			name:     "level and message fields",
+			level:    zerolog.NoLevel,
+			input:    `{"level":"info","time":1234,"message":"hello"}`,
+			severity: "info",
+			message:  "hello",
+		},
+		{
+			name:     "level from writer",
+			level:    zerolog.DebugLevel,
+			input:    `{"message":"hello"}`,
+			severity: "debug",
+			message:  "hello",
+		},
+		{
+			name:     "not json",
+			level:    zerolog.NoLevel,
+			input:    "plain text",
+			severity: "",
+			message:  "plain text",
+		},
+	}
+
+	for _, tc := range testcases {
+		data := parseLogData(tc.level, []byte(tc.input))
+		if data.Severity != tc.severity {
+			t.Errorf("%s: expected severity %q, got %q", tc.name, tc.severity, data.Severity)
+		}
+		if data.Message != tc.message {
+			t.Errorf("%s: expected message %q, got %q", tc.name, tc.message, data.Message)
+		}
+	}
+}
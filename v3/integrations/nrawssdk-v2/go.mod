@@ -10,6 +10,8 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.17.0
 	github.com/aws/aws-sdk-go-v2/service/lambda v1.24.5
 	github.com/aws/aws-sdk-go-v2/service/s3 v1.27.10
+	github.com/aws/aws-sdk-go-v2/service/sns v1.18.0
+	github.com/aws/aws-sdk-go-v2/service/sqs v1.19.9
 	github.com/aws/smithy-go v1.13.3
 	github.com/newrelic/go-agent/v3 v3.18.2
 )
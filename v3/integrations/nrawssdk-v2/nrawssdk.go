@@ -28,9 +28,19 @@ package nrawssdk
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsmiddle "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	smithymiddle "github.com/aws/smithy-go/middleware"
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
@@ -43,6 +53,220 @@ type nrMiddleware struct {
 
 type endable interface{ End() }
 
+// lambdaClientContextLimit is the maximum size, in bytes, of the base64
+// encoded client context accepted by lambda:Invoke.
+const lambdaClientContextLimit = 3583
+
+// initializeMiddleware injects distributed trace headers into the client
+// context of lambda:Invoke calls so that the invoked function's transaction
+// is linked to the caller.
+func (m nrMiddleware) initializeMiddleware(stack *smithymiddle.Stack) error {
+	return stack.Initialize.Add(smithymiddle.InitializeMiddlewareFunc("NRInitializeMiddleware", func(
+		ctx context.Context, in smithymiddle.InitializeInput, next smithymiddle.InitializeHandler) (
+		out smithymiddle.InitializeOutput, metadata smithymiddle.Metadata, err error) {
+
+		txn := m.txn
+		if txn == nil {
+			txn = newrelic.FromContext(ctx)
+		}
+
+		if txn == nil {
+			return next.HandleInitialize(ctx, in)
+		}
+
+		switch input := in.Parameters.(type) {
+		case *lambda.InvokeInput:
+			if clientContext, ok := insertClientContextHeaders(txn, input.ClientContext); ok {
+				// Copy the input so that the caller's value is left untouched.
+				params := *input
+				params.ClientContext = &clientContext
+				in.Parameters = &params
+			}
+		case *sqs.SendMessageInput, *sqs.SendMessageBatchInput, *sns.PublishInput:
+			return produceMessage(ctx, txn, in, next)
+		}
+
+		return next.HandleInitialize(ctx, in)
+	}),
+		smithymiddle.Before)
+}
+
+// producerCallKey marks the context of the calls timed by a
+// MessageProducerSegment, for which no external segment is created.
+type producerCallKey struct{}
+
+// MessageIDAttribute is the span attribute holding the id of the message sent
+// by sqs:SendMessage and sns:Publish calls.
+const MessageIDAttribute = "aws.messageId"
+
+// messageAttributesLimit is the maximum number of attributes of a message
+// accepted by SQS and SNS.
+const messageAttributesLimit = 10
+
+// produceMessage times an SQS or SNS call sending messages with a
+// MessageProducerSegment, and adds the distributed trace headers to the
+// attributes of the messages so that the consumers' transactions are linked
+// to the sender.
+func produceMessage(ctx context.Context, txn *newrelic.Transaction, in smithymiddle.InitializeInput, next smithymiddle.InitializeHandler) (
+	smithymiddle.InitializeOutput, smithymiddle.Metadata, error) {
+
+	segment := &newrelic.MessageProducerSegment{StartTime: txn.StartSegmentNow()}
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+
+	// Copy the input so that the caller's value is left untouched.
+	switch input := in.Parameters.(type) {
+	case *sqs.SendMessageInput:
+		segment.Library = "SQS"
+		segment.DestinationType = newrelic.MessageQueue
+		segment.DestinationName = sqsQueueName(input.QueueUrl)
+		params := *input
+		params.MessageAttributes = sqsMessageAttributes(input.MessageAttributes, hdrs)
+		in.Parameters = &params
+	case *sqs.SendMessageBatchInput:
+		segment.Library = "SQS"
+		segment.DestinationType = newrelic.MessageQueue
+		segment.DestinationName = sqsQueueName(input.QueueUrl)
+		params := *input
+		params.Entries = make([]sqstypes.SendMessageBatchRequestEntry, len(input.Entries))
+		for i, entry := range input.Entries {
+			entry.MessageAttributes = sqsMessageAttributes(entry.MessageAttributes, hdrs)
+			params.Entries[i] = entry
+		}
+		in.Parameters = &params
+	case *sns.PublishInput:
+		segment.Library = "SNS"
+		segment.DestinationType = newrelic.MessageTopic
+		segment.DestinationName = snsTopicName(input)
+		// Messages sent directly to a phone number are not recorded
+		// with their destination.
+		segment.DestinationTemporary = segment.DestinationName == ""
+		params := *input
+		params.MessageAttributes = snsMessageAttributes(input.MessageAttributes, hdrs)
+		in.Parameters = &params
+	}
+
+	out, metadata, err := next.HandleInitialize(context.WithValue(ctx, producerCallKey{}, true), in)
+
+	var messageID *string
+	switch result := out.Result.(type) {
+	case *sqs.SendMessageOutput:
+		messageID = result.MessageId
+	case *sns.PublishOutput:
+		messageID = result.MessageId
+	}
+	if messageID != nil {
+		segment.AddAttribute(MessageIDAttribute, *messageID)
+	}
+	segment.End()
+	return out, metadata, err
+}
+
+// sqsQueueName returns the name of the queue, which is the last element of
+// its URL path.
+func sqsQueueName(queueURL *string) string {
+	if queueURL == nil {
+		return ""
+	}
+	return (*queueURL)[strings.LastIndex(*queueURL, "/")+1:]
+}
+
+// snsTopicName returns the name of the topic or of the endpoint which the
+// message is published to, which is the last element of its ARN.
+func snsTopicName(input *sns.PublishInput) string {
+	arn := input.TopicArn
+	if arn == nil {
+		arn = input.TargetArn
+	}
+	if arn == nil {
+		return ""
+	}
+	return (*arn)[strings.LastIndex(*arn, ":")+1:]
+}
+
+// sqsMessageAttributes returns a copy of the message attributes with the
+// distributed trace headers added.  The attributes are returned unchanged if
+// there is no room for the headers.
+func sqsMessageAttributes(attrs map[string]sqstypes.MessageAttributeValue, hdrs http.Header) map[string]sqstypes.MessageAttributeValue {
+	if len(hdrs) == 0 || len(attrs)+len(hdrs) > messageAttributesLimit {
+		return attrs
+	}
+	withHeaders := make(map[string]sqstypes.MessageAttributeValue, len(attrs)+len(hdrs))
+	for key, val := range attrs {
+		withHeaders[key] = val
+	}
+	for key := range hdrs {
+		withHeaders[key] = sqstypes.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(hdrs.Get(key)),
+		}
+	}
+	return withHeaders
+}
+
+// snsMessageAttributes is the SNS version of sqsMessageAttributes.
+func snsMessageAttributes(attrs map[string]snstypes.MessageAttributeValue, hdrs http.Header) map[string]snstypes.MessageAttributeValue {
+	if len(hdrs) == 0 || len(attrs)+len(hdrs) > messageAttributesLimit {
+		return attrs
+	}
+	withHeaders := make(map[string]snstypes.MessageAttributeValue, len(attrs)+len(hdrs))
+	for key, val := range attrs {
+		withHeaders[key] = val
+	}
+	for key := range hdrs {
+		withHeaders[key] = snstypes.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(hdrs.Get(key)),
+		}
+	}
+	return withHeaders
+}
+
+// insertClientContextHeaders returns the base64 encoded client context with
+// the distributed trace headers added to its "custom" section.  It returns
+// false if the existing client context is not a JSON object, if no headers
+// were created, or if the result would exceed the Lambda size limit.
+func insertClientContextHeaders(txn *newrelic.Transaction, existing *string) (string, bool) {
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if len(hdrs) == 0 {
+		return "", false
+	}
+
+	clientContext := map[string]interface{}{}
+	if existing != nil && *existing != "" {
+		decoded, err := base64.StdEncoding.DecodeString(*existing)
+		if err != nil {
+			return "", false
+		}
+		if err := json.Unmarshal(decoded, &clientContext); err != nil || clientContext == nil {
+			return "", false
+		}
+	}
+
+	custom := map[string]interface{}{}
+	if c := clientContext["custom"]; c != nil {
+		var ok bool
+		if custom, ok = c.(map[string]interface{}); !ok {
+			return "", false
+		}
+	}
+	for key := range hdrs {
+		custom[key] = hdrs.Get(key)
+	}
+	clientContext["custom"] = custom
+
+	js, err := json.Marshal(clientContext)
+	if err != nil {
+		return "", false
+	}
+	encoded := base64.StdEncoding.EncodeToString(js)
+	if len(encoded) > lambdaClientContextLimit {
+		return "", false
+	}
+	return encoded, true
+}
+
 // See https://aws.github.io/aws-sdk-go-v2/docs/middleware/ for a description of
 // AWS SDK V2 middleware.
 func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
@@ -64,8 +288,12 @@ func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
 		region := awsmiddle.GetRegion(ctx)
 
 		var segment endable
+		switch {
+		case ctx.Value(producerCallKey{}) != nil:
+			// The call is timed by the MessageProducerSegment started by
+			// produceMessage.
 		// Service name capitalization is different for v1 and v2.
-		if serviceName == "dynamodb" || serviceName == "DynamoDB" {
+		case serviceName == "dynamodb" || serviceName == "DynamoDB":
 			segment = &newrelic.DatastoreSegment{
 				Product:            newrelic.DatastoreDynamoDB,
 				Collection:         "", // AWS SDK V2 doesn't expose TableName
@@ -77,7 +305,7 @@ func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
 				DatabaseName:       "",
 				StartTime:          txn.StartSegmentNow(),
 			}
-		} else {
+		default:
 			segment = newrelic.StartExternalSegment(txn, httpRequest)
 		}
 
@@ -101,7 +329,9 @@ func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
 					newrelic.AttributeAWSRequestID, requestID)
 			}
 		}
-		segment.End()
+		if segment != nil {
+			segment.End()
+		}
 		return out, metadata, err
 	}),
 		smithymiddle.Before)
@@ -117,6 +347,19 @@ func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
 // events: aws.region, aws.requestId, and aws.operation. In addition,
 // http.statusCode will be added to span events.
 //
+// Calls sending messages with sqs:SendMessage, sqs:SendMessageBatch, and
+// sns:Publish are recorded with message producer segments named after the
+// queue or topic, rather than external segments.  Distributed trace headers
+// are added to the attributes of the messages so that the consumers'
+// transactions are linked to the sender, unless the messages already have too
+// many attributes.  The id of the message sent is recorded in the
+// "aws.messageId" attribute.
+//
+// For lambda:Invoke calls, distributed trace headers are added to the
+// "custom" section of the invocation's client context so that the invoked
+// function's transaction is linked to the caller.  A client context which is
+// not base64 encoded JSON is left unchanged.
+//
 // To see segments and spans for all AWS invocations, call AppendMiddlewares
 // with the AWS Config `apiOptions` and provide nil for `txn`. For example:
 //
@@ -140,5 +383,5 @@ func (m nrMiddleware) deserializeMiddleware(stack *smithymiddle.Stack) error {
 //  nraws.AppendMiddlewares(&awsConfig.APIOptions, txn)
 func AppendMiddlewares(apiOptions *[]func(*smithymiddle.Stack) error, txn *newrelic.Transaction) {
 	m := nrMiddleware{txn: txn}
-	*apiOptions = append(*apiOptions, m.deserializeMiddleware)
+	*apiOptions = append(*apiOptions, m.initializeMiddleware, m.deserializeMiddleware)
 }
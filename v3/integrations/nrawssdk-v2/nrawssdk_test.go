@@ -6,9 +6,14 @@ package nrawssdk
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,6 +22,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/newrelic/go-agent/v3/internal"
 	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -578,3 +586,246 @@ func TestNoRequestIDFound(t *testing.T) {
 		},
 	)
 }
+
+func dtTestApp() integrationsupport.ExpectApp {
+	return integrationsupport.NewTestApp(func(reply *internal.ConnectReply) {
+		integrationsupport.SampleEverythingReplyFn(reply)
+		reply.AccountID = "123"
+		reply.TrustedAccountKey = "123"
+		reply.PrimaryAppID = "456"
+	}, integrationsupport.DTEnabledCfgFn)
+}
+
+type clientContextTransport struct {
+	clientContext string
+}
+
+func (t *clientContextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.clientContext = r.Header.Get("X-Amz-Client-Context")
+	return fakeTransport{}.RoundTrip(r)
+}
+
+func TestLambdaInvokeClientContext(t *testing.T) {
+	existing := base64.StdEncoding.EncodeToString([]byte(`{"client":{"app_title":"MyApp"},"custom":{"foo":"bar"}}`))
+
+	testcases := []struct {
+		Name          string
+		ClientContext *string
+		WantCustom    map[string]string
+	}{
+		{Name: "no client context", ClientContext: nil, WantCustom: map[string]string{}},
+		{Name: "existing client context", ClientContext: aws.String(existing), WantCustom: map[string]string{"foo": "bar"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			app := dtTestApp()
+			txn := app.StartTransaction(txnName)
+			ctx := context.Background()
+
+			transport := &clientContextTransport{}
+			cfg := newConfig(ctx, txn)
+			cfg.HTTPClient = &http.Client{Transport: transport}
+			client := lambda.NewFromConfig(cfg)
+
+			input := &lambda.InvokeInput{
+				ClientContext: tc.ClientContext,
+				FunctionName:  aws.String("non-existent-function"),
+				Payload:       []byte("{}"),
+			}
+			if _, err := client.Invoke(ctx, input); err != nil {
+				t.Fatal(err)
+			}
+			txn.End()
+
+			if input.ClientContext != tc.ClientContext {
+				t.Error("input was modified")
+			}
+
+			decoded, err := base64.StdEncoding.DecodeString(transport.clientContext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var clientContext struct {
+				Client map[string]string `json:"client"`
+				Custom map[string]string `json:"custom"`
+			}
+			if err := json.Unmarshal(decoded, &clientContext); err != nil {
+				t.Fatal(err)
+			}
+			if tc.ClientContext != nil && clientContext.Client["app_title"] != "MyApp" {
+				t.Errorf("client section not preserved: %s", decoded)
+			}
+			for key, val := range tc.WantCustom {
+				if clientContext.Custom[key] != val {
+					t.Errorf("custom %s: got %q want %q", key, clientContext.Custom[key], val)
+				}
+			}
+			if clientContext.Custom[newrelic.DistributedTraceNewRelicHeader] == "" {
+				t.Errorf("missing newrelic header: %s", decoded)
+			}
+			if clientContext.Custom[newrelic.DistributedTraceW3CTraceParentHeader] == "" {
+				t.Errorf("missing traceparent header: %s", decoded)
+			}
+		})
+	}
+}
+
+func TestLambdaInvokeClientContextNotJSON(t *testing.T) {
+	app := dtTestApp()
+	txn := app.StartTransaction(txnName)
+	ctx := context.Background()
+
+	transport := &clientContextTransport{}
+	cfg := newConfig(ctx, txn)
+	cfg.HTTPClient = &http.Client{Transport: transport}
+	client := lambda.NewFromConfig(cfg)
+
+	input := &lambda.InvokeInput{
+		ClientContext: aws.String("MyApp"),
+		FunctionName:  aws.String("non-existent-function"),
+		Payload:       []byte("{}"),
+	}
+	if _, err := client.Invoke(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+	txn.End()
+
+	if transport.clientContext != "MyApp" {
+		t.Error(transport.clientContext)
+	}
+}
+
+type sqsTransport struct {
+	form url.Values
+}
+
+func (t *sqsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if t.form, err = url.ParseQuery(string(body)); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Body: ioutil.NopCloser(strings.NewReader(`<SendMessageResponse>
+			<SendMessageResult><MessageId>message-id</MessageId></SendMessageResult>
+			<ResponseMetadata><RequestId>` + requestID + `</RequestId></ResponseMetadata>
+		</SendMessageResponse>`)),
+		Header: http.Header{
+			"X-Amzn-Requestid": []string{requestID},
+		},
+	}, nil
+}
+
+// messageAttributes returns the message attributes of the SendMessage form.
+func (t *sqsTransport) messageAttributes() map[string]string {
+	attrs := map[string]string{}
+	for i := 1; t.form.Get(fmt.Sprintf("MessageAttribute.%d.Name", i)) != ""; i++ {
+		name := t.form.Get(fmt.Sprintf("MessageAttribute.%d.Name", i))
+		attrs[name] = t.form.Get(fmt.Sprintf("MessageAttribute.%d.Value.StringValue", i))
+	}
+	return attrs
+}
+
+func TestSQSSendMessage(t *testing.T) {
+	app := dtTestApp()
+	txn := app.StartTransaction(txnName)
+	ctx := context.Background()
+
+	transport := &sqsTransport{}
+	cfg := newConfig(ctx, txn)
+	cfg.HTTPClient = &http.Client{Transport: transport}
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		o.DisableMessageChecksumValidation = true
+	})
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String("https://sqs.us-west-2.amazonaws.com/123456789012/my-queue"),
+		MessageBody: aws.String("hello"),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"foo": {DataType: aws.String("String"), StringValue: aws.String("bar")},
+		},
+	}
+	if _, err := client.SendMessage(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+	txn.End()
+
+	if len(input.MessageAttributes) != 1 {
+		t.Error("input was modified:", input.MessageAttributes)
+	}
+	attrs := transport.messageAttributes()
+	if attrs["foo"] != "bar" {
+		t.Error("existing attribute missing:", attrs)
+	}
+	if attrs[newrelic.DistributedTraceNewRelicHeader] == "" {
+		t.Error("missing newrelic attribute:", attrs)
+	}
+	if attrs[newrelic.DistributedTraceW3CTraceParentHeader] == "" {
+		t.Error("missing traceparent attribute:", attrs)
+	}
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "MessageBroker/SQS/Queue/Produce/Named/my-queue", Scope: "", Forced: false, Data: nil},
+		{Name: "MessageBroker/SQS/Queue/Produce/Named/my-queue", Scope: "OtherTransaction/Go/" + txnName, Forced: false, Data: nil},
+	})
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":      "MessageBroker/SQS/Queue/Produce/Named/my-queue",
+				"category":  "generic",
+				"parentId":  internal.MatchAnything,
+				"sampled":   true,
+				"priority":  internal.MatchAnything,
+				"guid":      internal.MatchAnything,
+				"traceId":   internal.MatchAnything,
+				"timestamp": internal.MatchAnything,
+				"duration":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				MessageIDAttribute: "message-id",
+			},
+			AgentAttributes: map[string]interface{}{
+				"aws.operation":   "SendMessage",
+				"aws.region":      awsRegion,
+				"aws.requestId":   requestID,
+				"http.statusCode": "200",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/" + txnName,
+				"transaction.name": "OtherTransaction/Go/" + txnName,
+				"sampled":          true,
+				"category":         "generic",
+				"priority":         internal.MatchAnything,
+				"guid":             internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+				"nr.entryPoint":    true,
+				"timestamp":        internal.MatchAnything,
+				"duration":         internal.MatchAnything,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestMessageDestinationNames(t *testing.T) {
+	if name := sqsQueueName(aws.String("https://sqs.us-west-2.amazonaws.com/123456789012/my-queue")); name != "my-queue" {
+		t.Error(name)
+	}
+	if name := sqsQueueName(nil); name != "" {
+		t.Error(name)
+	}
+	if name := snsTopicName(&sns.PublishInput{TopicArn: aws.String("arn:aws:sns:us-west-2:123456789012:my-topic")}); name != "my-topic" {
+		t.Error(name)
+	}
+	if name := snsTopicName(&sns.PublishInput{PhoneNumber: aws.String("+15555550100")}); name != "" {
+		t.Error(name)
+	}
+}
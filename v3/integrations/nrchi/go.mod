@@ -0,0 +1,8 @@
+module github.com/newrelic/go-agent/v3/integrations/nrchi
+
+go 1.17
+
+require (
+	github.com/go-chi/chi/v5 v5.0.8
+	github.com/newrelic/go-agent/v3 v3.18.2
+)
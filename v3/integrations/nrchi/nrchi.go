@@ -0,0 +1,98 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nrchi instruments https://github.com/go-chi/chi applications.
+//
+// Use this package to instrument inbound requests handled by a chi.Router.
+// Register the nrchi.Middleware as the first middleware of your router, or
+// wrap the router with it:
+//
+//	r := chi.NewRouter()
+//	r.Use(nrchi.Middleware(app))
+//	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+//		txn := newrelic.FromContext(r.Context())
+//		// ...
+//	})
+//
+// Transactions are named after the method and the route pattern, such as
+// "GET /users/{id}", and the URL parameters of the route are recorded as
+// attributes prefixed with "request.parameters.".
+//
+// chi middlewares run before the request is routed, so the route pattern is
+// only known once the handler has returned.  Transactions are therefore
+// renamed when the request completes, and are named "NotFoundHandler" if no
+// route matched the request.
+package nrchi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/newrelic/go-agent/v3/internal"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func init() { internal.TrackUsage("integration", "framework", "chi") }
+
+// URLParamAttributePrefix is the prefix of the attributes holding the URL
+// parameters of the route.  The parameter "{id}" is recorded as the attribute
+// "request.parameters.id".
+const URLParamAttributePrefix = "request.parameters."
+
+// Middleware creates a chi middleware that creates a transaction for each
+// inbound request.  The transaction is available in the request context
+// throughout the call chain, including in any other middlewares registered
+// after this one.  For this reason, it is important for this middleware to be
+// registered first.  If the application is nil, the returned middleware does
+// nothing.
+func Middleware(app *newrelic.Application) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if nil == app {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			txn := app.StartTransaction("NotFoundHandler")
+			defer txn.End()
+			txn.SetWebRequestHTTP(r)
+			w = txn.SetWebResponse(w)
+			r = newrelic.RequestWithTransactionContext(r, txn)
+
+			// When the middleware wraps the router rather than being
+			// registered with it, provide the route context chi will
+			// use so that the route pattern can be read afterwards.
+			if nil == chi.RouteContext(r.Context()) {
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chi.NewRouteContext()))
+			}
+			// The route context is only filled while routing, after
+			// the middlewares have run.
+			defer nameTransaction(txn, r)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func nameTransaction(txn *newrelic.Transaction, r *http.Request) {
+	rctx := chi.RouteContext(r.Context())
+	txn.SetName(transactionName(r.Method, rctx))
+	if nil == rctx {
+		return
+	}
+	for i, key := range rctx.URLParams.Keys {
+		if i < len(rctx.URLParams.Values) && "*" != key {
+			txn.AddAttribute(URLParamAttributePrefix+key, rctx.URLParams.Values[i])
+		}
+	}
+}
+
+func transactionName(method string, rctx *chi.Context) string {
+	if nil == rctx || 0 == len(rctx.RoutePatterns) {
+		return "NotFoundHandler"
+	}
+	pattern := rctx.RoutePattern()
+	if "" == pattern {
+		pattern = "/"
+	}
+	return method + " " + pattern
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrchi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestParameterizedRoute(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := chi.NewRouter()
+	r.Use(Middleware(app.Application))
+	r.Get("/users/{id}/posts/{post}", func(w http.ResponseWriter, r *http.Request) {
+		// ensure that the txn is added to the context and accessible by
+		// handlers
+		newrelic.FromContext(r.Context()).NoticeError(errors.New("oops"))
+		w.Write([]byte("post " + chi.URLParam(r, "post")))
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123/posts/456", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "post 456" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /users/{id}/posts/{post}",
+		IsWeb:         true,
+		NumErrors:     1,
+		UnknownCaller: true,
+		ErrorByCaller: true,
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /users/{id}/posts/{post}",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"sampled":          false,
+				"guid":             internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"request.parameters.id":   "123",
+				"request.parameters.post": "456",
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":             200,
+				"http.statusCode":              200,
+				"request.method":               "GET",
+				"request.uri":                  "/users/123/posts/456",
+				"response.headers.contentType": "text/plain; charset=utf-8",
+			},
+		},
+	})
+}
+
+func TestMountedRouter(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	users := chi.NewRouter()
+	users.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	})
+	r := chi.NewRouter()
+	r.Use(Middleware(app.Application))
+	r.Mount("/users", users)
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /users/{id}",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestWrappedRouter(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := chi.NewRouter()
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	})
+	h := Middleware(app.Application)(r)
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /users/{id}",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestNotFound(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := chi.NewRouter()
+	r.Use(Middleware(app.Application))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/missing/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if response.Code != http.StatusNotFound {
+		t.Error("wrong response code", response.Code)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "NotFoundHandler",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestNilApp(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware(nil))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if nil != newrelic.FromContext(r.Context()) {
+			t.Error("unexpected transaction in context")
+		}
+		w.Write([]byte("user"))
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+}
@@ -11,12 +11,23 @@
 //	// Add the nrecho middleware before other middlewares or routes:
 //	e.Use(nrecho.Middleware(app))
 //
+// Use WrapRenderer to time the templates rendered with echo.Context.Render:
+//
+//	e.Renderer = nrecho.WrapRenderer(renderer)
+//
 // Example: https://github.com/newrelic/go-agent/tree/master/v3/integrations/nrecho-v4/example/main.go
 package nrecho
 
 import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/newrelic/go-agent/v3/internal"
@@ -46,6 +57,73 @@ func transactionName(c echo.Context) string {
 	return c.Request().Method + " " + c.Path()
 }
 
+// WrapRenderer wraps an echo.Renderer to create a segment named
+// "View/<name>/Rendering" around the rendering of each template, where name
+// is the name of the template.  The segment is only created when the request
+// is instrumented by the Middleware.  If the renderer is nil, nil is
+// returned.
+//
+//	e.Renderer = nrecho.WrapRenderer(renderer)
+func WrapRenderer(renderer echo.Renderer) echo.Renderer {
+	if nil == renderer {
+		return nil
+	}
+	return &templateRenderer{renderer: renderer}
+}
+
+type templateRenderer struct {
+	renderer echo.Renderer
+}
+
+func (r *templateRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	defer FromContext(c).StartSegment("View/" + name + "/Rendering").End()
+	return r.renderer.Render(w, name, data, c)
+}
+
+// WrapMiddleware wraps an Echo middleware to create a segment named
+// "Middleware/<name>" timing its execution, such as the time spent by a rate
+// limiter or an authentication middleware.  The segment ends when the
+// middleware calls the next handler, so that the handlers it runs are not
+// part of it, or when it returns without calling it.  The segment is only
+// created when the request is instrumented by the Middleware, which must
+// thus be used first.
+//
+//	e.Use(nrecho.Middleware(app))
+//	e.Use(nrecho.WrapMiddleware("RateLimiter", middleware.RateLimiter(store)))
+func WrapMiddleware(name string, mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+	// The segment is held by the echo.Context rather than the closure,
+	// the handler chain serving all the requests.
+	key := "nrecho.middleware." + name
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := mw(func(c echo.Context) error {
+			if s, ok := c.Get(key).(*middlewareSegment); ok {
+				s.end()
+			}
+			return next(c)
+		})
+		return func(c echo.Context) error {
+			s := &middlewareSegment{segment: FromContext(c).StartSegment("Middleware/" + name)}
+			c.Set(key, s)
+			defer s.end()
+			return handler(c)
+		}
+	}
+}
+
+// middlewareSegment is the segment of a middleware wrapped by
+// WrapMiddleware, ended once.
+type middlewareSegment struct {
+	segment *newrelic.Segment
+	ended   bool
+}
+
+func (s *middlewareSegment) end() {
+	if !s.ended {
+		s.ended = true
+		s.segment.End()
+	}
+}
+
 // Skipper defines a function to skip middleware. Returning true skips processing
 // the middleware.
 type Skipper func(c echo.Context) bool
@@ -57,14 +135,418 @@ type Config struct {
 
 	// Skipper defines a function to skip middleware.
 	Skipper Skipper
+
+	// OnTransactionStart is called with the transaction right after it is
+	// started, once it has been added to the request context.
+	OnTransactionStart TransactionHook
+
+	// OnTransactionEnd is called with the transaction right before it is
+	// ended, once the response code has been recorded.
+	OnTransactionEnd TransactionHook
+
+	// RecordProtocol records the protocol and scheme of the request in the
+	// RequestProtocolAttribute and RequestSchemeAttribute attributes.
+	RecordProtocol bool
+
+	// ProtocolInName appends the protocol and scheme of the request to the
+	// transaction name, when RecordProtocol is set.
+	ProtocolInName bool
+
+	// ContextAttributes lists the values of the Echo context recorded as
+	// transaction attributes.
+	ContextAttributes []ContextAttribute
+
+	// RequestIDHeader is the header holding the id of the request, which
+	// is generated when the request lacks it.  No request id is recorded
+	// when it is empty.
+	RequestIDHeader string
+
+	// CacheHeader is the response header recorded in the
+	// ResponseCacheAttribute attribute.  Nothing is recorded when it is
+	// empty.
+	CacheHeader string
+
+	// HandlerSegment times the handler with a segment named
+	// HandlerSegmentName.
+	HandlerSegment bool
+
+	// TimeToFirstByte records the time to the first write of the response
+	// in the TimeToFirstByteAttribute attribute.
+	TimeToFirstByte bool
+}
+
+// ContextAttribute records the value stored in the Echo context under
+// ContextKey as the transaction attribute Name.
+type ContextAttribute struct {
+	ContextKey string
+	Name       string
 }
 
+// TransactionHook is a function run by the middleware with the transaction of
+// the request, for instance to add attributes to it.
+type TransactionHook func(c echo.Context, txn *newrelic.Transaction)
+
 type ConfigOption func(*Config)
 
 func WithSkipper(skipper Skipper) ConfigOption {
 	return func(cfg *Config) { cfg.Skipper = skipper }
 }
 
+// WithOnTransactionStart sets a hook called with the transaction right after
+// it is started.
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithOnTransactionStart(
+//		func(c echo.Context, txn *newrelic.Transaction) {
+//			txn.AddAttribute("tenant", c.Request().Header.Get("X-Tenant"))
+//		},
+//	)))
+func WithOnTransactionStart(hook TransactionHook) ConfigOption {
+	return func(cfg *Config) { cfg.OnTransactionStart = hook }
+}
+
+// WithOnTransactionEnd sets a hook called with the transaction right before it
+// is ended, after the handler has returned.
+func WithOnTransactionEnd(hook TransactionHook) ConfigOption {
+	return func(cfg *Config) { cfg.OnTransactionEnd = hook }
+}
+
+// WithRequestProtocol records the protocol of the request, such as HTTP/1.1 or
+// HTTP/2.0, in the RequestProtocolAttribute attribute and its scheme, http or
+// https, in the RequestSchemeAttribute attribute, so that the traffic of each
+// protocol can be told apart.  The scheme tells HTTP/2 over TLS from
+// cleartext HTTP/2 (h2c): the latter is recorded with the http scheme.  When
+// inName is true, the protocol and scheme are also appended to the
+// transaction name, as in "GET /hello (HTTP/2.0 https)".
+func WithRequestProtocol(inName bool) ConfigOption {
+	return func(cfg *Config) {
+		cfg.RecordProtocol = true
+		cfg.ProtocolInName = inName
+	}
+}
+
+// WithContextAttribute records the value stored in the Echo context under
+// contextKey, for instance the id of the user set by an authentication
+// middleware, as the transaction attribute attrName.  The value is read once
+// the handler has returned, so it may be set by the middlewares registered
+// before or after the nrecho middleware.  Numbers, strings and booleans are
+// recorded as is, and values implementing fmt.Stringer as the result of their
+// String method.  The attribute is not recorded when the value is missing or
+// of another type.
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithContextAttribute("user_id", "user.id")))
+func WithContextAttribute(contextKey string, attrName string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ContextAttributes = append(cfg.ContextAttributes, ContextAttribute{
+			ContextKey: contextKey,
+			Name:       attrName,
+		})
+	}
+}
+
+// WithGeneratedRequestID correlates every transaction with a request id read
+// from the headerName request header, echo.HeaderXRequestID if empty.  When
+// the request lacks the header, a random UUID is generated.  The id is set
+// in the headerName response header, recorded in the RequestIDAttribute
+// transaction attribute and stored in the Echo context under RequestIDKey,
+// for the handlers:
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithGeneratedRequestID("")))
+//	e.GET("/", func(c echo.Context) error {
+//		return c.String(http.StatusOK, c.Get(nrecho.RequestIDKey).(string))
+//	})
+func WithGeneratedRequestID(headerName string) ConfigOption {
+	return func(cfg *Config) {
+		if headerName == "" {
+			headerName = echo.HeaderXRequestID
+		}
+		cfg.RequestIDHeader = headerName
+	}
+}
+
+const (
+	// RequestIDAttribute is the transaction attribute holding the id of
+	// the request when WithGeneratedRequestID is used.
+	RequestIDAttribute = "request.id"
+	// RequestIDKey is the key of the Echo context holding the id of the
+	// request when WithGeneratedRequestID is used.
+	RequestIDKey = "nrecho.request_id"
+)
+
+// WithCacheHeader records the value of the headerName response header, "X-Cache"
+// if empty, set by the handler to tell whether the response was served from a
+// cache, in the ResponseCacheAttribute transaction attribute.  The header is
+// read once the handler has returned, and the attribute is not recorded when
+// the header is missing.
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithCacheHeader("")))
+//	e.GET("/", func(c echo.Context) error {
+//		c.Response().Header().Set("X-Cache", "HIT")
+//		return c.String(http.StatusOK, cached)
+//	})
+func WithCacheHeader(headerName string) ConfigOption {
+	return func(cfg *Config) {
+		if headerName == "" {
+			headerName = "X-Cache"
+		}
+		cfg.CacheHeader = headerName
+	}
+}
+
+// HandlerSegmentName is the name of the segment timing the handler when
+// WithHandlerSegment is used.
+const HandlerSegmentName = "handler"
+
+// WithHandlerSegment times the handler with a segment named
+// HandlerSegmentName, to tell the time spent in the handler from the time
+// spent by the middleware: the work done by the hooks and the middlewares
+// registered before the nrecho middleware is not part of it.  The
+// middlewares registered after the nrecho middleware run within the segment,
+// use WrapMiddleware to time them with their own segments.
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithHandlerSegment()))
+func WithHandlerSegment() ConfigOption {
+	return func(cfg *Config) { cfg.HandlerSegment = true }
+}
+
+// WithTimeToFirstByte records the time, in milliseconds, from the start of the
+// transaction to the first write of the response in the
+// TimeToFirstByteAttribute attribute, to tell the latency of the response
+// from the total response time.
+//
+//	e.Use(nrecho.Middleware(app, nrecho.WithTimeToFirstByte()))
+func WithTimeToFirstByte() ConfigOption {
+	return func(cfg *Config) { cfg.TimeToFirstByte = true }
+}
+
+// ResponseCacheAttribute is the transaction attribute holding the value of
+// the response header set by WithCacheHeader, such as HIT or MISS.
+const ResponseCacheAttribute = "response.cache"
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// contextAttributeValue returns the value to record for the value of the Echo
+// context, and false if it cannot be recorded.
+func contextAttributeValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return val, true
+	case fmt.Stringer:
+		return val.String(), true
+	default:
+		return nil, false
+	}
+}
+
+const (
+	// RequestProtocolAttribute is the transaction attribute holding the
+	// protocol of the request when WithRequestProtocol is used.
+	RequestProtocolAttribute = "request.protocol"
+	// RequestSchemeAttribute is the transaction attribute holding the
+	// scheme of the request when WithRequestProtocol is used.  It is read
+	// with echo.Context.Scheme, which accounts for the headers set by TLS
+	// terminating proxies.
+	RequestSchemeAttribute = "request.scheme"
+)
+
+// webRequest returns the request to record on the transaction.  The
+// request.headers.contentLength attribute is read from the Content-Length
+// header, which is missing when the request was not read from the wire.  It is
+// added from the request ContentLength in that case, unless the length is
+// unknown, as it is for chunked requests.
+func webRequest(r *http.Request) *http.Request {
+	if r.ContentLength <= 0 || r.Header.Get("Content-Length") != "" {
+		return r
+	}
+	wr := *r
+	wr.Header = r.Header.Clone()
+	if nil == wr.Header {
+		wr.Header = make(http.Header)
+	}
+	wr.Header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	return &wr
+}
+
+// TimeToFirstByteAttribute is the transaction attribute holding the time, in
+// milliseconds, from the start of the transaction to the first write of the
+// response, recorded when WithTimeToFirstByte is used.  It is not recorded
+// if nothing was written.
+const TimeToFirstByteAttribute = "response.ttfb_ms"
+
+// firstByteWriter records the time of the first write of the response.  Use
+// upgrade to only expose the optional interfaces of the writer it wraps.
+type firstByteWriter struct {
+	http.ResponseWriter
+	firstByte time.Time
+}
+
+func (w *firstByteWriter) markFirstByte() {
+	if w.firstByte.IsZero() {
+		w.firstByte = time.Now()
+	}
+}
+
+func (w *firstByteWriter) WriteHeader(code int) {
+	w.markFirstByte()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *firstByteWriter) Write(b []byte) (int, error) {
+	w.markFirstByte()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *firstByteWriter) Flush() {
+	w.markFirstByte()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *firstByteWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *firstByteWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *firstByteWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.markFirstByte()
+	return w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+}
+
+func (w *firstByteWriter) upgrade() http.ResponseWriter {
+	// GENERATED CODE DO NOT MODIFY
+	// This code generated by internal/tools/interface-wrapping
+	var (
+		i0 int32 = 1 << 0
+		i1 int32 = 1 << 1
+		i2 int32 = 1 << 2
+		i3 int32 = 1 << 3
+	)
+	var interfaceSet int32
+	if _, ok := w.ResponseWriter.(http.Flusher); ok {
+		interfaceSet |= i0
+	}
+	if _, ok := w.ResponseWriter.(http.Hijacker); ok {
+		interfaceSet |= i1
+	}
+	if _, ok := w.ResponseWriter.(http.Pusher); ok {
+		interfaceSet |= i2
+	}
+	if _, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		interfaceSet |= i3
+	}
+	switch interfaceSet {
+	default: // No optional interfaces implemented
+		return struct {
+			http.ResponseWriter
+		}{w}
+	case i0:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{w, w}
+	case i1:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+		}{w, w}
+	case i0 | i1:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{w, w, w}
+	case i2:
+		return struct {
+			http.ResponseWriter
+			http.Pusher
+		}{w, w}
+	case i0 | i2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{w, w, w}
+	case i1 | i2:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{w, w, w}
+	case i0 | i1 | i2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w}
+	case i3:
+		return struct {
+			http.ResponseWriter
+			io.ReaderFrom
+		}{w, w}
+	case i0 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{w, w, w}
+	case i1 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w}
+	case i0 | i1 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w, w}
+	case i2 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w}
+	case i0 | i2 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case i1 | i2 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case i0 | i1 | i2 | i3:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	}
+}
+
 // Middleware creates Echo middleware with provided config that
 // instruments requests.
 //
@@ -72,6 +554,11 @@ func WithSkipper(skipper Skipper) ConfigOption {
 //	// Add the nrecho middleware before other middlewares or routes:
 //	e.Use(nrecho.MiddlewareWithConfig(nrecho.Config{App: app}))
 //
+// Errors returned by the next handlers are noticed, unless they are
+// *echo.HTTPError, and passed to the Echo HTTPErrorHandler by the middleware,
+// so that the status code recorded is the one written by the error handler.
+// They are then returned unchanged.
+//
 func Middleware(app *newrelic.Application, opts ...ConfigOption) func(echo.HandlerFunc) echo.HandlerFunc {
 	if app == nil {
 		return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -100,33 +587,86 @@ func Middleware(app *newrelic.Application, opts ...ConfigOption) func(echo.Handl
 				return next(c)
 			}
 
+			start := time.Now()
 			rw := c.Response().Writer
-			txn := config.App.StartTransaction(transactionName(c))
+			writer := rw
+			var fbw *firstByteWriter
+			if config.TimeToFirstByte {
+				fbw = &firstByteWriter{ResponseWriter: rw}
+				writer = fbw.upgrade()
+			}
+			name := transactionName(c)
+			if config.RecordProtocol && config.ProtocolInName {
+				name += " (" + c.Request().Proto + " " + c.Scheme() + ")"
+			}
+			txn := config.App.StartTransaction(name)
 			defer txn.End()
 
-			txn.SetWebRequestHTTP(c.Request())
+			txn.SetWebRequestHTTP(webRequest(c.Request()))
+			if config.RecordProtocol {
+				txn.AddAttribute(RequestProtocolAttribute, c.Request().Proto)
+				txn.AddAttribute(RequestSchemeAttribute, c.Scheme())
+			}
+			if config.RequestIDHeader != "" {
+				id := c.Request().Header.Get(config.RequestIDHeader)
+				if id == "" {
+					id = newRequestID()
+				}
+				c.Response().Header().Set(config.RequestIDHeader, id)
+				txn.AddAttribute(RequestIDAttribute, id)
+				c.Set(RequestIDKey, id)
+			}
 
-			c.Response().Writer = txn.SetWebResponse(rw)
+			c.Response().Writer = txn.SetWebResponse(writer)
+			defer func() { c.Response().Writer = rw }()
 
 			// Add txn to c.Request().Context()
 			c.SetRequest(c.Request().WithContext(newrelic.NewContext(c.Request().Context(), txn)))
 
-			err = next(c)
+			if config.OnTransactionEnd != nil {
+				defer config.OnTransactionEnd(c, txn)
+			}
+			if config.OnTransactionStart != nil {
+				config.OnTransactionStart(c, txn)
+			}
+
+			if config.HandlerSegment {
+				segment := txn.StartSegment(HandlerSegmentName)
+				err = next(c)
+				segment.End()
+			} else {
+				err = next(c)
+			}
 
-			// Record the response code. The response headers are not captured
-			// in this case because they are set after this middleware returns.
-			// Designed to mimic the logic in echo.DefaultHTTPErrorHandler.
-			if nil != err && !c.Response().Committed {
+			// Handle the error here rather than after this middleware
+			// returns, so that the response written by the Echo
+			// HTTPErrorHandler, which may be a custom one, goes through the
+			// transaction's response writer and its final status code is
+			// recorded.  As in echo's Recover middleware, nil is then
+			// returned so that the error is not handled a second time.  The
+			// error is recorded through the status code of the response.
+			if nil != err {
+				c.Error(err)
+				err = nil
+			}
 
-				c.Response().Writer = rw
+			for _, attr := range config.ContextAttributes {
+				if val, ok := contextAttributeValue(c.Get(attr.ContextKey)); ok {
+					txn.AddAttribute(attr.Name, val)
+				}
+			}
 
-				if httperr, ok := err.(*echo.HTTPError); ok {
-					txn.SetWebResponse(nil).WriteHeader(httperr.Code)
-				} else {
-					txn.SetWebResponse(nil).WriteHeader(http.StatusInternalServerError)
+			if config.CacheHeader != "" {
+				if val := c.Response().Header().Get(config.CacheHeader); val != "" {
+					txn.AddAttribute(ResponseCacheAttribute, val)
 				}
 			}
 
+			if fbw != nil && !fbw.firstByte.IsZero() {
+				txn.AddAttribute(TimeToFirstByteAttribute,
+					float64(fbw.firstByte.Sub(start))/float64(time.Millisecond))
+			}
+
 			return
 		}
 	}
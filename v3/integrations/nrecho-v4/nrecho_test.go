@@ -4,14 +4,21 @@
 package nrecho
 
 import (
+	"crypto/tls"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/newrelic/go-agent/v3/internal"
 	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 func TestBasicRoute(t *testing.T) {
@@ -251,10 +258,11 @@ func TestReturnsHTTPError(t *testing.T) {
 			"priority":         "*",
 		},
 		AgentAttributes: map[string]interface{}{
-			"httpResponseCode": "418",
-			"http.statusCode":  "418",
-			"request.method":   "GET",
-			"request.uri":      "/hello",
+			"httpResponseCode":             "418",
+			"http.statusCode":              "418",
+			"request.method":               "GET",
+			"response.headers.contentType": "application/json; charset=UTF-8",
+			"request.uri":                  "/hello",
 		},
 		UserAttributes: map[string]interface{}{},
 	}})
@@ -293,8 +301,57 @@ func TestReturnsError(t *testing.T) {
 			"priority":         "*",
 		},
 		AgentAttributes: map[string]interface{}{
-			"httpResponseCode": "500",
-			"http.statusCode":  "500",
+			"httpResponseCode":             "500",
+			"http.statusCode":              "500",
+			"request.method":               "GET",
+			"response.headers.contentType": "application/json; charset=UTF-8",
+			"request.uri":                  "/hello",
+		},
+		UserAttributes: map[string]interface{}{},
+	}})
+}
+
+func TestCustomHTTPErrorHandler(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		code := http.StatusInternalServerError
+		if httperr, ok := err.(*echo.HTTPError); ok {
+			code = httperr.Code
+		}
+		if code == http.StatusInternalServerError {
+			code = http.StatusServiceUnavailable
+		}
+		c.NoContent(code)
+	}
+	e.Use(Middleware(app.Application))
+	e.GET("/hello", func(c echo.Context) error {
+		return errors.New("ooooooooops")
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.ServeHTTP(response, req)
+	if response.Code != http.StatusServiceUnavailable {
+		t.Error("wrong response code", response.Code)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/GET /hello",
+			"nr.apdexPerfZone": "F",
+			"sampled":          false,
+			"guid":             "*",
+			"traceId":          "*",
+			"priority":         "*",
+		},
+		AgentAttributes: map[string]interface{}{
+			"httpResponseCode": "503",
+			"http.statusCode":  "503",
 			"request.method":   "GET",
 			"request.uri":      "/hello",
 		},
@@ -302,6 +359,67 @@ func TestReturnsError(t *testing.T) {
 	}})
 }
 
+func TestErrorHandledOnce(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	var calls int
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		calls++
+		c.String(http.StatusInternalServerError, "error")
+	}
+	e.Use(Middleware(app.Application))
+	e.GET("/hello", func(c echo.Context) error {
+		return errors.New("ooooooooops")
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.ServeHTTP(response, req)
+	if calls != 1 {
+		t.Error("error handler called", calls, "times")
+	}
+	if body := response.Body.String(); body != "error" {
+		t.Error("wrong response body", body)
+	}
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName: "WebTransaction/Go/GET /hello",
+		Msg:     "Internal Server Error",
+		Klass:   "500",
+	}})
+}
+
+func TestErrorResponseWriterRestored(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	handler := Middleware(app.Application)(func(c echo.Context) error {
+		return errors.New("ooooooooops")
+	})
+
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := httptest.NewRecorder()
+	c := e.NewContext(req, response)
+	c.SetPath("/hello")
+	c.SetHandler(handler)
+	if err := handler(c); err != nil {
+		t.Error("handled error returned", err)
+	}
+	if c.Response().Writer != response {
+		t.Error("response writer not restored")
+	}
+	if response.Code != http.StatusInternalServerError {
+		t.Error("wrong response code", response.Code)
+	}
+}
+
 func TestResponseCode(t *testing.T) {
 	app := integrationsupport.NewBasicTestApp()
 
@@ -344,3 +462,561 @@ func TestResponseCode(t *testing.T) {
 		UserAttributes: map[string]interface{}{},
 	}})
 }
+
+type fakeRenderer struct{}
+
+func (fakeRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	_, err := io.WriteString(w, name+" rendered")
+	return err
+}
+
+func TestWrapRenderer(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	e.Renderer = WrapRenderer(fakeRenderer{})
+	e.Use(Middleware(app.Application))
+	e.GET("/hello", func(c echo.Context) error {
+		return c.Render(http.StatusOK, "hello.html", nil)
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "hello.html rendered" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Custom/View/hello.html/Rendering", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/View/hello.html/Rendering", Scope: "WebTransaction/Go/GET /hello", Forced: false, Data: nil},
+	})
+}
+
+func TestWrapRendererNil(t *testing.T) {
+	if r := WrapRenderer(nil); nil != r {
+		t.Error("expected nil renderer", r)
+	}
+}
+
+func TestWrapMiddleware(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	e.Use(Middleware(app.Application))
+	e.Use(WrapMiddleware("Auth", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return c.NoContent(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}))
+	e.GET("/hello", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "text/html", []byte("Hello, World!"))
+	})
+
+	for _, auth := range []string{"token", ""} {
+		response := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/hello", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", auth)
+		e.ServeHTTP(response, req)
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Custom/Middleware/Auth", Scope: "", Forced: false, Data: []float64{2}},
+		{Name: "Custom/Middleware/Auth", Scope: "WebTransaction/Go/GET /hello", Forced: false, Data: []float64{2}},
+	})
+}
+
+func TestTransactionHooks(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	var started, ended *newrelic.Transaction
+	e := echo.New()
+	e.Use(Middleware(app.Application,
+		WithOnTransactionStart(func(c echo.Context, txn *newrelic.Transaction) {
+			started = txn
+			if FromContext(c) != txn {
+				t.Error("transaction missing from the request context")
+			}
+			txn.AddAttribute("tenant", c.Request().Header.Get("X-Tenant"))
+		}),
+		WithOnTransactionEnd(func(c echo.Context, txn *newrelic.Transaction) {
+			ended = txn
+			txn.AddAttribute("handled", c.Get("handled"))
+		}),
+	))
+	e.GET("/hello", func(c echo.Context) error {
+		c.Set("handled", true)
+		return c.Blob(http.StatusOK, "text/html", []byte("Hello, World!"))
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Tenant", "acme")
+
+	e.ServeHTTP(response, req)
+	if started == nil || started != ended {
+		t.Fatal("hooks not called with the same transaction", started, ended)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/GET /hello",
+			"nr.apdexPerfZone": "S",
+			"sampled":          false,
+			"guid":             "*",
+			"traceId":          "*",
+			"priority":         "*",
+		},
+		AgentAttributes: map[string]interface{}{
+			"httpResponseCode":             "200",
+			"http.statusCode":              "200",
+			"request.method":               "GET",
+			"response.headers.contentType": "text/html",
+			"request.uri":                  "/hello",
+		},
+		UserAttributes: map[string]interface{}{
+			"tenant":  "acme",
+			"handled": true,
+		},
+	}})
+}
+
+func TestTimeToFirstByte(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	e.Use(Middleware(app.Application, WithTimeToFirstByte()))
+	e.GET("/hello", func(c echo.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return c.Blob(http.StatusOK, "text/html", []byte("Hello, World!"))
+	})
+	e.GET("/empty", func(c echo.Context) error {
+		return nil
+	})
+
+	for _, path := range []string{"/hello", "/empty"} {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /hello",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			UserAttributes: map[string]interface{}{
+				TimeToFirstByteAttribute: internal.MatchAnything,
+			},
+		},
+		{
+			// Nothing is written when the handler does not write the
+			// response.
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /empty",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestRequestProtocol(t *testing.T) {
+	testcases := []struct {
+		name       string
+		inName     bool
+		proto      string
+		tls        bool
+		txnName    string
+		protoAttr  string
+		schemeAttr string
+	}{
+		{
+			name:       "HTTP/1.1",
+			proto:      "HTTP/1.1",
+			txnName:    "WebTransaction/Go/GET /hello",
+			protoAttr:  "HTTP/1.1",
+			schemeAttr: "http",
+		},
+		{
+			name:       "HTTP/2 over TLS",
+			proto:      "HTTP/2.0",
+			tls:        true,
+			txnName:    "WebTransaction/Go/GET /hello",
+			protoAttr:  "HTTP/2.0",
+			schemeAttr: "https",
+		},
+		{
+			name:       "h2c in name",
+			inName:     true,
+			proto:      "HTTP/2.0",
+			txnName:    "WebTransaction/Go/GET /hello (HTTP/2.0 http)",
+			protoAttr:  "HTTP/2.0",
+			schemeAttr: "http",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := integrationsupport.NewBasicTestApp()
+			e := echo.New()
+			e.Use(Middleware(app.Application, WithRequestProtocol(tc.inName)))
+			e.GET("/hello", func(c echo.Context) error {
+				return nil
+			})
+
+			req, err := http.NewRequest("GET", "/hello", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Proto = tc.proto
+			req.ProtoMajor, req.ProtoMinor, _ = http.ParseHTTPVersion(tc.proto)
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			app.ExpectTxnEvents(t, []internal.WantEvent{{
+				Intrinsics: map[string]interface{}{
+					"name":             tc.txnName,
+					"nr.apdexPerfZone": "S",
+					"sampled":          false,
+					"guid":             "*",
+					"traceId":          "*",
+					"priority":         "*",
+				},
+				UserAttributes: map[string]interface{}{
+					RequestProtocolAttribute: tc.protoAttr,
+					RequestSchemeAttribute:   tc.schemeAttr,
+				},
+			}})
+		})
+	}
+}
+
+type accountID int
+
+func (id accountID) String() string { return "acct-" + strconv.Itoa(int(id)) }
+
+func TestContextAttribute(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", 123)
+			c.Set("account", accountID(7))
+			c.Set("roles", []string{"admin"})
+			return next(c)
+		}
+	})
+	e.Use(Middleware(app.Application,
+		WithContextAttribute("user_id", "user.id"),
+		WithContextAttribute("account", "account.id"),
+		WithContextAttribute("roles", "user.roles"),
+		WithContextAttribute("missing", "user.missing"),
+	))
+	e.GET("/hello", func(c echo.Context) error {
+		return nil
+	})
+
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/GET /hello",
+			"nr.apdexPerfZone": "S",
+			"sampled":          false,
+			"guid":             "*",
+			"traceId":          "*",
+			"priority":         "*",
+		},
+		UserAttributes: map[string]interface{}{
+			"user.id":    123,
+			"account.id": "acct-7",
+		},
+	}})
+}
+
+func TestGeneratedRequestID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	testcases := []struct {
+		name     string
+		received string
+	}{
+		{name: "generated when absent"},
+		{name: "passed through when present", received: "client-id-123"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := integrationsupport.NewBasicTestApp()
+			e := echo.New()
+			e.Use(Middleware(app.Application, WithGeneratedRequestID("X-Correlation-ID")))
+			var handlerID interface{}
+			e.GET("/hello", func(c echo.Context) error {
+				handlerID = c.Get(RequestIDKey)
+				return nil
+			})
+
+			req, err := http.NewRequest("GET", "/hello", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.received != "" {
+				req.Header.Set("X-Correlation-ID", tc.received)
+			}
+			rw := httptest.NewRecorder()
+			e.ServeHTTP(rw, req)
+
+			id := rw.Header().Get("X-Correlation-ID")
+			if tc.received != "" && id != tc.received {
+				t.Errorf("request id not passed through: %q", id)
+			}
+			if tc.received == "" && !uuidPattern.MatchString(id) {
+				t.Errorf("generated request id is not a UUID: %q", id)
+			}
+			if handlerID != id {
+				t.Errorf("wrong request id in the context: %v", handlerID)
+			}
+			app.ExpectTxnEvents(t, []internal.WantEvent{{
+				Intrinsics: map[string]interface{}{
+					"name":             "WebTransaction/Go/GET /hello",
+					"nr.apdexPerfZone": "S",
+					"sampled":          false,
+					"guid":             "*",
+					"traceId":          "*",
+					"priority":         "*",
+				},
+				UserAttributes: map[string]interface{}{
+					RequestIDAttribute: id,
+				},
+			}})
+		})
+	}
+}
+
+func TestCacheHeader(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	e := echo.New()
+	e.Use(Middleware(app.Application, WithCacheHeader("")))
+	e.GET("/hit", func(c echo.Context) error {
+		c.Response().Header().Set("X-Cache", "HIT")
+		return c.String(http.StatusOK, "cached")
+	})
+	e.GET("/uncached", func(c echo.Context) error {
+		return nil
+	})
+
+	for _, path := range []string{"/hit", "/uncached"} {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /hit",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			UserAttributes: map[string]interface{}{
+				ResponseCacheAttribute: "HIT",
+			},
+		},
+		{
+			// Nothing is recorded when the handler does not set the
+			// header.
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /uncached",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestHandlerSegment(t *testing.T) {
+	app := integrationsupport.NewTestApp(nil, integrationsupport.ConfigFullTraces)
+	e := echo.New()
+	e.Use(Middleware(app.Application,
+		WithHandlerSegment(),
+		WithOnTransactionStart(func(c echo.Context, txn *newrelic.Transaction) {
+			txn.StartSegment("pre").End()
+		}),
+		WithOnTransactionEnd(func(c echo.Context, txn *newrelic.Transaction) {
+			txn.StartSegment("post").End()
+		}),
+	))
+	e.GET("/hello", func(c echo.Context) error {
+		FromContext(c).StartSegment("work").End()
+		return c.String(http.StatusOK, "hello")
+	})
+
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The segments of the hooks are siblings of the handler segment, the
+	// segments of the handler its children.
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName: "WebTransaction/Go/GET /hello",
+		Root: internal.WantTraceSegment{
+			SegmentName: "ROOT",
+			Attributes:  map[string]interface{}{},
+			Children: []internal.WantTraceSegment{{
+				SegmentName: "WebTransaction/Go/GET /hello",
+				Attributes:  map[string]interface{}{"exclusive_duration_millis": internal.MatchAnything},
+				Children: []internal.WantTraceSegment{
+					{SegmentName: "Custom/pre", Attributes: map[string]interface{}{}},
+					{
+						SegmentName: "Custom/" + HandlerSegmentName,
+						Attributes:  map[string]interface{}{},
+						Children: []internal.WantTraceSegment{
+							{SegmentName: "Custom/work", Attributes: map[string]interface{}{}},
+						},
+					},
+					{SegmentName: "Custom/post", Attributes: map[string]interface{}{}},
+				},
+			}},
+		},
+	}})
+}
+
+func TestFirstByteWriter(t *testing.T) {
+	w := &firstByteWriter{ResponseWriter: httptest.NewRecorder()}
+	if !w.firstByte.IsZero() {
+		t.Fatal("first byte recorded before writing")
+	}
+	before := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	w.WriteHeader(http.StatusOK)
+	firstByte := w.firstByte
+	if firstByte.Sub(before) < 10*time.Millisecond {
+		t.Error("first byte recorded too early:", firstByte.Sub(before))
+	}
+	w.Write([]byte("hello"))
+	if w.firstByte != firstByte {
+		t.Error("first byte recorded again")
+	}
+}
+
+func TestFirstByteWriterUpgrade(t *testing.T) {
+	// httptest.ResponseRecorder only implements http.Flusher.
+	w := (&firstByteWriter{ResponseWriter: httptest.NewRecorder()}).upgrade()
+	if _, ok := w.(http.Flusher); !ok {
+		t.Error("writer does not implement http.Flusher")
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		t.Error("writer implements http.Hijacker")
+	}
+	if _, ok := w.(http.Pusher); ok {
+		t.Error("writer implements http.Pusher")
+	}
+	if _, ok := w.(io.ReaderFrom); ok {
+		t.Error("writer implements io.ReaderFrom")
+	}
+}
+
+func TestRequestContentAttributes(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+
+	e := echo.New()
+	e.Use(Middleware(app.Application))
+	e.POST("/items", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+
+	body := `{"name":"item"}`
+	req := httptest.NewRequest("POST", "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if cl := req.Header.Get("Content-Length"); cl != "" {
+		t.Error("request header modified:", cl)
+	}
+
+	// A chunked request has an unknown length.
+	req = httptest.NewRequest("POST", "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/POST /items",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":              "201",
+				"http.statusCode":               "201",
+				"request.method":                "POST",
+				"request.uri":                   "/items",
+				"request.headers.contentType":   "application/json",
+				"request.headers.contentLength": len(body),
+				"request.headers.host":          "example.com",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/POST /items",
+				"nr.apdexPerfZone": "S",
+				"sampled":          false,
+				"guid":             "*",
+				"traceId":          "*",
+				"priority":         "*",
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":            "201",
+				"http.statusCode":             "201",
+				"request.method":              "POST",
+				"request.uri":                 "/items",
+				"request.headers.contentType": "application/json",
+				"request.headers.host":        "example.com",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrfasthttp
+
+import (
+	"net/http"
+
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/valyala/fasthttp"
+)
+
+// DoRequest performs the request with the fasthttp.Client, as
+// fasthttp.Client.Do does, while timing it with an external segment.  The
+// distributed tracing headers of the transaction are added to the request,
+// and the status code of the response is recorded on the segment.  If the
+// transaction is nil, the request is performed without instrumentation.
+//
+//	req := fasthttp.AcquireRequest()
+//	resp := fasthttp.AcquireResponse()
+//	req.SetRequestURI("http://example.com/users")
+//	err := nrfasthttp.DoRequest(txn, client, req, resp)
+func DoRequest(txn *newrelic.Transaction, client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if nil == txn {
+		return client.Do(req, resp)
+	}
+	seg := &newrelic.ExternalSegment{
+		StartTime: txn.StartSegmentNow(),
+		URL:       req.URI().String(),
+		Procedure: string(req.Header.Method()),
+		Library:   "fasthttp",
+	}
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	for key := range hdrs {
+		req.Header.Set(key, hdrs.Get(key))
+	}
+
+	err := client.Do(req, resp)
+	if nil == err {
+		seg.SetStatusCode(resp.StatusCode())
+	}
+	seg.End()
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrfasthttp
+
+import (
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDoRequest(t *testing.T) {
+	var traceparent string
+	client := serve(t, func(ctx *fasthttp.RequestCtx) {
+		traceparent = string(ctx.Request.Header.Peek("traceparent"))
+		ctx.SetStatusCode(fasthttp.StatusTeapot)
+	})
+
+	app := testApp()
+	txn := app.StartTransaction("client")
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://example.com/users")
+	if err := DoRequest(txn, client, req, resp); nil != err {
+		t.Fatal(err)
+	}
+	txn.End()
+
+	if resp.StatusCode() != fasthttp.StatusTeapot {
+		t.Error("wrong status code", resp.StatusCode())
+	}
+	if "" == traceparent {
+		t.Error("traceparent header was not inserted")
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "External/all", Scope: "", Forced: true, Data: nil},
+		{Name: "External/example.com/all", Scope: "", Forced: false, Data: nil},
+		{Name: "External/example.com/fasthttp/GET", Scope: "OtherTransaction/Go/client", Forced: false, Data: nil},
+	})
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":      "External/example.com/fasthttp/GET",
+				"category":  "http",
+				"component": "fasthttp",
+				"span.kind": "client",
+				"parentId":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{
+				"http.statusCode": fasthttp.StatusTeapot,
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/client",
+				"category":         "generic",
+				"nr.entryPoint":    true,
+				"transaction.name": "OtherTransaction/Go/client",
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestDoRequestNilTransaction(t *testing.T) {
+	client := serve(t, func(ctx *fasthttp.RequestCtx) {
+		if v := ctx.Request.Header.Peek("traceparent"); len(v) > 0 {
+			t.Error("unexpected traceparent header", string(v))
+		}
+	})
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://example.com/users")
+	if err := DoRequest(nil, client, req, resp); nil != err {
+		t.Fatal(err)
+	}
+}
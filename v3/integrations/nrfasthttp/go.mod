@@ -0,0 +1,8 @@
+module github.com/newrelic/go-agent/v3/integrations/nrfasthttp
+
+go 1.17
+
+require (
+	github.com/newrelic/go-agent/v3 v3.18.2
+	github.com/valyala/fasthttp v1.44.0
+)
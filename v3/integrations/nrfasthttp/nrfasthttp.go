@@ -0,0 +1,115 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nrfasthttp instruments https://github.com/valyala/fasthttp
+// applications.
+//
+// Use WrapHandler to instrument inbound requests handled by a
+// fasthttp.RequestHandler:
+//
+//	handler := nrfasthttp.WrapHandler(app, "/users", func(ctx *fasthttp.RequestCtx) {
+//		txn := nrfasthttp.FromContext(ctx)
+//		// ...
+//	})
+//	fasthttp.ListenAndServe(":8000", handler)
+//
+// fasthttp does not use net/http types, so the transaction is stored in the
+// user values of the fasthttp.RequestCtx rather than in a context.Context.
+// Use FromContext to retrieve it, and newrelic.NewContext to pass it to APIs
+// expecting a context.Context:
+//
+//	txn := nrfasthttp.FromContext(ctx)
+//	rows, err := db.QueryContext(newrelic.NewContext(ctx, txn), "SELECT * FROM users")
+//
+// Use DoRequest in place of fasthttp.Client.Do to instrument outbound
+// requests with an external segment:
+//
+//	err := nrfasthttp.DoRequest(txn, client, req, resp)
+package nrfasthttp
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/valyala/fasthttp"
+)
+
+func init() { internal.TrackUsage("integration", "framework", "fasthttp") }
+
+type contextKeyType struct{}
+
+var transactionKey = contextKeyType{}
+
+// FromContext returns the transaction started by WrapHandler for the request,
+// or nil if there is none.
+func FromContext(ctx *fasthttp.RequestCtx) *newrelic.Transaction {
+	if nil == ctx {
+		return nil
+	}
+	txn, _ := ctx.UserValue(transactionKey).(*newrelic.Transaction)
+	return txn
+}
+
+// WrapHandler wraps a fasthttp.RequestHandler to start a web transaction named
+// name for each request.  The transaction records the request method, URI and
+// headers, accepts the distributed tracing headers of the request, and
+// records the status code of the response.  It is available to the handler
+// through FromContext.
+//
+// fasthttp reuses a fasthttp.RequestCtx for subsequent requests: the
+// transaction is removed from it once the handler returns.  Do not use the
+// transaction after the handler has returned.  If the application is nil,
+// the handler is returned unchanged.
+func WrapHandler(app *newrelic.Application, name string, handler fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if nil == app {
+		return handler
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		txn := app.StartTransaction(name)
+		defer txn.End()
+		txn.SetWebRequest(webRequest(ctx))
+
+		ctx.SetUserValue(transactionKey, txn)
+		defer ctx.RemoveUserValue(transactionKey)
+
+		handler(ctx)
+
+		hdr := http.Header{}
+		ctx.Response.Header.VisitAll(func(key, value []byte) {
+			hdr.Add(string(key), string(value))
+		})
+		txn.SetWebResponse(headerWriter(hdr)).WriteHeader(ctx.Response.StatusCode())
+	}
+}
+
+func webRequest(ctx *fasthttp.RequestCtx) newrelic.WebRequest {
+	hdr := http.Header{}
+	ctx.Request.Header.VisitAll(func(key, value []byte) {
+		hdr.Add(string(key), string(value))
+	})
+	uri := ctx.URI()
+	transport := newrelic.TransportHTTP
+	if ctx.IsTLS() {
+		transport = newrelic.TransportHTTPS
+	}
+	return newrelic.WebRequest{
+		Header: hdr,
+		URL: &url.URL{
+			Path:     string(uri.Path()),
+			RawQuery: string(uri.QueryString()),
+		},
+		Method:    string(ctx.Method()),
+		Transport: transport,
+		Host:      string(ctx.Host()),
+	}
+}
+
+// headerWriter is an http.ResponseWriter exposing the headers of a fasthttp
+// response, used to record the response with Transaction.SetWebResponse.
+type headerWriter http.Header
+
+func (w headerWriter) Header() http.Header         { return http.Header(w) }
+func (w headerWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w headerWriter) WriteHeader(int)             {}
@@ -0,0 +1,155 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrfasthttp
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+var replyFn = func(reply *internal.ConnectReply) {
+	reply.SetSampleEverything()
+	reply.AccountID = "123"
+	reply.TrustedAccountKey = "123"
+	reply.PrimaryAppID = "456"
+}
+
+func testApp() integrationsupport.ExpectApp {
+	return integrationsupport.NewTestApp(replyFn, integrationsupport.DTEnabledCfgFn)
+}
+
+// serve starts an in-memory fasthttp server running handler and returns a
+// client connected to it.
+func serve(t *testing.T, handler fasthttp.RequestHandler) *fasthttp.Client {
+	ln := fasthttputil.NewInmemoryListener()
+	srv := &fasthttp.Server{Handler: handler}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Shutdown() })
+	return &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+func doRequest(t *testing.T, client *fasthttp.Client, uri string, hdr http.Header) *fasthttp.Response {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(uri)
+	for key := range hdr {
+		req.Header.Set(key, hdr.Get(key))
+	}
+	resp := &fasthttp.Response{}
+	if err := client.Do(req, resp); nil != err {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestWrapHandler(t *testing.T) {
+	app := testApp()
+	client := serve(t, WrapHandler(app.Application, "GET /users", func(ctx *fasthttp.RequestCtx) {
+		if nil == FromContext(ctx) {
+			t.Error("expected transaction in context")
+		}
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+		ctx.SetContentType("text/plain")
+		ctx.WriteString("users")
+	}))
+
+	resp := doRequest(t, client, "http://example.com/users?id=1", nil)
+	if body := string(resp.Body()); body != "users" {
+		t.Error("wrong response body", body)
+	}
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "WebTransaction/Go/GET /users", Scope: "", Forced: true, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/HTTP/allWeb", Scope: "", Forced: false, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /users",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"sampled":          internal.MatchAnything,
+				"guid":             internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":             201,
+				"http.statusCode":              201,
+				"request.method":               "GET",
+				"request.uri":                  "/users",
+				"request.headers.host":         "example.com",
+				"response.headers.contentType": "text/plain",
+			},
+		},
+	})
+}
+
+func TestWrapHandlerDistributedTracing(t *testing.T) {
+	producerApp := testApp()
+	producerTxn := producerApp.StartTransaction("producer")
+	hdr := http.Header{}
+	producerTxn.InsertDistributedTraceHeaders(hdr)
+	producerTxn.End()
+
+	app := testApp()
+	client := serve(t, WrapHandler(app.Application, "GET /users", func(ctx *fasthttp.RequestCtx) {}))
+	doRequest(t, client, "http://example.com/users", hdr)
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Supportability/TraceContext/Accept/Success", Scope: "", Forced: true, Data: nil},
+		{Name: "TransportDuration/App/123/456/HTTP/all", Scope: "", Forced: false, Data: nil},
+	})
+}
+
+func TestWrapHandlerReusedContext(t *testing.T) {
+	app := testApp()
+	var txns []*newrelic.Transaction
+	wrapped := WrapHandler(app.Application, "GET /users", func(ctx *fasthttp.RequestCtx) {
+		txns = append(txns, FromContext(ctx))
+	})
+	client := serve(t, func(ctx *fasthttp.RequestCtx) {
+		wrapped(ctx)
+		if nil != FromContext(ctx) {
+			t.Error("transaction left in the reused context")
+		}
+	})
+
+	// Both requests use the same connection, and thus the same
+	// fasthttp.RequestCtx.
+	doRequest(t, client, "http://example.com/users", nil)
+	doRequest(t, client, "http://example.com/users", nil)
+
+	if len(txns) != 2 || nil == txns[0] || txns[0] == txns[1] {
+		t.Errorf("expected a distinct transaction per request: %v", txns)
+	}
+}
+
+func TestWrapHandlerNilApp(t *testing.T) {
+	var called bool
+	client := serve(t, WrapHandler(nil, "GET /users", func(ctx *fasthttp.RequestCtx) {
+		called = true
+		if nil != FromContext(ctx) {
+			t.Error("unexpected transaction in context")
+		}
+	}))
+	doRequest(t, client, "http://example.com/users", nil)
+	if !called {
+		t.Error("handler was not called")
+	}
+}
+
+func TestFromContextNil(t *testing.T) {
+	if txn := FromContext(nil); nil != txn {
+		t.Error("expected nil transaction")
+	}
+}
@@ -11,6 +11,12 @@
 //	// Add the nrgin middleware before other middlewares or routes:
 //	router.Use(nrgin.Middleware(app))
 //
+// Binding errors are recorded with their own class using WithBindErrors.
+// Handlers using the ShouldBind methods must store the errors with the
+// gin.ErrorTypeBind type for them to be recorded:
+//
+//	c.Error(err).SetType(gin.ErrorTypeBind)
+//
 // Example: https://github.com/newrelic/go-agent/tree/master/v3/integrations/nrgin/example/main.go
 package nrgin
 
@@ -94,10 +100,34 @@ func Transaction(c Context) *newrelic.Transaction {
 	return nil
 }
 
+// FromContext returns the transaction added to the gin.Context by the
+// middleware, or nil if there is none.
+func FromContext(c *gin.Context) *newrelic.Transaction {
+	if nil == c {
+		return nil
+	}
+	return Transaction(c)
+}
+
+// FromContextOrNoop returns the transaction added to the gin.Context by the
+// middleware.  If there is none, it returns a transaction whose methods do
+// nothing, so that it never returns nil.
+func FromContextOrNoop(c *gin.Context) *newrelic.Transaction {
+	if txn := FromContext(c); nil != txn {
+		return txn
+	}
+	return &newrelic.Transaction{}
+}
+
 type handlerNamer interface {
 	HandlerName() string
 }
 
+// notFoundTxnName names the transactions of the requests matching no route,
+// whose full path is empty, as nrecho does.  Using the request path instead
+// would create a transaction name per path requested.
+const notFoundTxnName = "NotFoundHandler"
+
 func getName(c handlerNamer, useNewNames bool) string {
 	if useNewNames {
 		if fp, ok := c.(interface{ FullPath() string }); ok {
@@ -107,6 +137,78 @@ func getName(c handlerNamer, useNewNames bool) string {
 	return c.HandlerName()
 }
 
+func transactionName(c *gin.Context, useNewNames bool) string {
+	name := getName(c, useNewNames)
+	if name == "" {
+		return notFoundTxnName
+	}
+	return c.Request.Method + " " + name
+}
+
+// Skipper is a function deciding whether a request is skipped by the
+// middleware.  Returning true skips the request: no transaction is created
+// for it.
+type Skipper func(c *gin.Context) bool
+
+type config struct {
+	skipper    Skipper
+	bindErrors bool
+}
+
+// ConfigOption configures the middleware.
+type ConfigOption func(*config)
+
+// WithSkipper sets a function deciding whether a request is skipped by the
+// middleware, for instance to leave health checks or streaming endpoints such
+// as server-sent events and long polling out of the instrumentation.  No
+// request is skipped by default.
+//
+//	router.Use(nrgin.Middleware(app, nrgin.WithSkipper(func(c *gin.Context) bool {
+//		return c.FullPath() == "/health"
+//	})))
+func WithSkipper(skipper Skipper) ConfigOption {
+	return func(cfg *config) { cfg.skipper = skipper }
+}
+
+// BindErrorClass is the class of the errors recorded for the binding and
+// validation errors of a request when WithBindErrors is used.
+const BindErrorClass = "gin.BindError"
+
+// WithBindErrors records the binding and validation errors of the request on
+// the transaction, with the BindErrorClass class, so that they can be told
+// apart from the other errors.  Only the errors of the gin.ErrorTypeBind
+// type stored in the gin.Context are recorded.  The Bind methods, such as
+// gin.Context.BindJSON, store their errors with this type.  The ShouldBind
+// methods, such as gin.Context.ShouldBindJSON, only return their errors:
+// these are not recorded unless the handler stores them and sets their type
+// to gin.ErrorTypeBind itself.  An error stored with c.Error(err) alone has
+// the gin.ErrorTypePrivate type, and is not recorded either:
+//
+//	router.Use(nrgin.Middleware(app, nrgin.WithBindErrors()))
+//	router.POST("/users", func(c *gin.Context) {
+//		var user User
+//		if err := c.ShouldBindJSON(&user); err != nil {
+//			c.Error(err).SetType(gin.ErrorTypeBind)
+//			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+//			return
+//		}
+//	})
+//
+// The errors are recorded once the handlers have returned.
+func WithBindErrors() ConfigOption {
+	return func(cfg *config) { cfg.bindErrors = true }
+}
+
+// noticeBindErrors records the binding errors stored in the gin.Context.
+func noticeBindErrors(txn *newrelic.Transaction, c *gin.Context) {
+	for _, err := range c.Errors.ByType(gin.ErrorTypeBind) {
+		txn.NoticeError(newrelic.Error{
+			Message: err.Error(),
+			Class:   BindErrorClass,
+		})
+	}
+}
+
 // Middleware creates a Gin middleware that instruments requests.
 //
 //	router := gin.Default()
@@ -118,9 +220,10 @@ func getName(c handlerNamer, useNewNames bool) string {
 // gin.Context.FullPath if available and fall back to the original
 // gin.Context.HandlerName if not.  If you are using Gin v1.5.0 and wish to
 // continue using the old transaction names, use
-// nrgin.MiddlewareHandlerTxnNames.
-func Middleware(app *newrelic.Application) gin.HandlerFunc {
-	return middleware(app, true)
+// nrgin.MiddlewareHandlerTxnNames.  The requests matching no route are named
+// "NotFoundHandler".
+func Middleware(app *newrelic.Application, opts ...ConfigOption) gin.HandlerFunc {
+	return middleware(app, true, opts)
 }
 
 // MiddlewareHandlerTxnNames creates a Gin middleware that instruments
@@ -134,30 +237,40 @@ func Middleware(app *newrelic.Application) gin.HandlerFunc {
 // in a future release.  Available in Gin v1.5.0 and newer is the
 // gin.Context.FullPath method which allows for much improved transaction
 // names.  Use nrgin.Middleware to take full advantage of this new naming!
-func MiddlewareHandlerTxnNames(app *newrelic.Application) gin.HandlerFunc {
-	return middleware(app, false)
+func MiddlewareHandlerTxnNames(app *newrelic.Application, opts ...ConfigOption) gin.HandlerFunc {
+	return middleware(app, false, opts)
 }
 
-func middleware(app *newrelic.Application, useNewNames bool) gin.HandlerFunc {
+func middleware(app *newrelic.Application, useNewNames bool, opts []ConfigOption) gin.HandlerFunc {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	return func(c *gin.Context) {
-		if app != nil {
-			name := c.Request.Method + " " + getName(c, useNewNames)
-
-			w := &headerResponseWriter{w: c.Writer}
-			txn := app.StartTransaction(name, newrelic.WithFunctionLocation(c.Handler()))
-			txn.SetWebRequestHTTP(c.Request)
-			defer txn.End()
-
-			repl := &replacementResponseWriter{
-				ResponseWriter: c.Writer,
-				replacement:    txn.SetWebResponse(w),
-				code:           http.StatusOK,
-			}
-			c.Writer = repl
-			defer repl.flushHeader()
-
-			c.Set(internal.GinTransactionContextKey, txn)
+		if app == nil || (cfg.skipper != nil && cfg.skipper(c)) {
+			c.Next()
+			return
+		}
+		name := transactionName(c, useNewNames)
+
+		w := &headerResponseWriter{w: c.Writer}
+		txn := app.StartTransaction(name, newrelic.WithFunctionLocation(c.Handler()))
+		txn.SetWebRequestHTTP(c.Request)
+		defer txn.End()
+
+		repl := &replacementResponseWriter{
+			ResponseWriter: c.Writer,
+			replacement:    txn.SetWebResponse(w),
+			code:           http.StatusOK,
 		}
+		c.Writer = repl
+		defer repl.flushHeader()
+
+		c.Set(internal.GinTransactionContextKey, txn)
 		c.Next()
+
+		if cfg.bindErrors {
+			noticeBindErrors(txn, c)
+		}
 	}
 }
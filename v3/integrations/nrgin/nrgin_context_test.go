@@ -128,3 +128,56 @@ func TestNewContextTransaction(t *testing.T) {
 		ErrorByCaller: true,
 	})
 }
+
+func accessTransactionNrginFromContext(c *gin.Context) {
+	txn := FromContext(c)
+	txn.NoticeError(errors.New("problem"))
+	c.Writer.WriteString("accessTransactionNrginFromContext")
+}
+
+func TestNrginFromContext(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := gin.Default()
+	router.Use(Middleware(app.Application))
+	router.GET("/txn", accessTransactionNrginFromContext)
+
+	txnName := "GET " + pkg + ".accessTransactionNrginFromContext"
+	if useFullPathVersion(gin.Version) {
+		txnName = "GET /txn"
+	}
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/txn", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "accessTransactionNrginFromContext" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          txnName,
+		IsWeb:         true,
+		NumErrors:     1,
+		UnknownCaller: true,
+		ErrorByCaller: true,
+	})
+}
+
+func TestFromContextWithoutTransaction(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if txn := FromContext(c); nil != txn {
+		t.Error("didn't expect a transaction", txn)
+	}
+	if txn := FromContext(nil); nil != txn {
+		t.Error("didn't expect a transaction", txn)
+	}
+	txn := FromContextOrNoop(c)
+	if nil == txn {
+		t.Fatal("expected a no-op transaction")
+	}
+	// The no-op transaction must be safe to use.
+	txn.NoticeError(errors.New("problem"))
+	txn.StartSegment("segment").End()
+	txn.End()
+}
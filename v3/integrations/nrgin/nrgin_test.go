@@ -393,3 +393,163 @@ func TestMiddlewareOldNaming(t *testing.T) {
 		UnknownCaller: true,
 	})
 }
+
+func TestWithSkipper(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := gin.Default()
+	router.Use(Middleware(app.Application, WithSkipper(func(c *gin.Context) bool {
+		return c.Request.URL.Path == "/health"
+	})))
+	router.GET("/health", func(c *gin.Context) {
+		if txn := FromContext(c); nil != txn {
+			t.Error("transaction found for skipped request")
+		}
+		c.Writer.WriteString("ok")
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "ok" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{})
+}
+
+func TestWithSkipperNotSkipped(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := gin.Default()
+	router.Use(Middleware(app.Application, WithSkipper(func(c *gin.Context) bool {
+		return c.Request.URL.Path == "/health"
+	})))
+	router.GET("/hello", hello)
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /hello",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestNotFoundRoute(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := gin.Default()
+	router.Use(Middleware(app.Application))
+	router.GET("/hello", hello)
+
+	if !useFullPathVersion(gin.Version) {
+		t.Skip("gin.Context.FullPath is not available")
+	}
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/unregistered/12345", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if response.Code != http.StatusNotFound {
+		t.Error("wrong response code", response.Code)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "NotFoundHandler",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestWithBindErrors(t *testing.T) {
+	// The 400 response code is ignored so that only the bind error is
+	// recorded.
+	app := integrationsupport.NewTestApp(nil, func(cfg *newrelic.Config) {
+		cfg.ErrorCollector.IgnoreStatusCodes = append(cfg.ErrorCollector.IgnoreStatusCodes, http.StatusBadRequest)
+	})
+	router := gin.Default()
+	router.Use(Middleware(app.Application, WithBindErrors()))
+	router.POST("/users", func(c *gin.Context) {
+		var user struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&user); err != nil {
+			c.Error(err).SetType(gin.ErrorTypeBind)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Writer.WriteString("created")
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/users", strings.NewReader(`{"name": 42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if response.Code != http.StatusBadRequest {
+		t.Error("wrong response code", response.Code)
+	}
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName: "WebTransaction/Go/POST /users",
+		Msg:     "json: cannot unmarshal number into Go struct field .name of type string",
+		Klass:   BindErrorClass,
+	}})
+}
+
+func TestWithBindErrorsPrivateType(t *testing.T) {
+	// The errors of the ShouldBind methods stored without setting their
+	// type are not recorded.
+	app := integrationsupport.NewTestApp(nil, func(cfg *newrelic.Config) {
+		cfg.ErrorCollector.IgnoreStatusCodes = append(cfg.ErrorCollector.IgnoreStatusCodes, http.StatusBadRequest)
+	})
+	router := gin.Default()
+	router.Use(Middleware(app.Application, WithBindErrors()))
+	router.POST("/users", func(c *gin.Context) {
+		var user struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&user); err != nil {
+			c.Error(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/users", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	app.ExpectErrors(t, []internal.WantError{})
+}
+
+func TestWithBindErrorsNotUsed(t *testing.T) {
+	app := integrationsupport.NewTestApp(nil, func(cfg *newrelic.Config) {
+		cfg.ErrorCollector.IgnoreStatusCodes = append(cfg.ErrorCollector.IgnoreStatusCodes, http.StatusBadRequest)
+	})
+	router := gin.Default()
+	router.Use(Middleware(app.Application))
+	router.POST("/users", func(c *gin.Context) {
+		var user struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&user); err != nil {
+			c.Error(err).SetType(gin.ErrorTypeBind)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+	})
+
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/users", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	app.ExpectErrors(t, []internal.WantError{})
+}
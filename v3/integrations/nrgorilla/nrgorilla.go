@@ -98,9 +98,24 @@ func InstrumentRoutes(r *mux.Router, app *newrelic.Application) *mux.Router {
 // expected and nothing to worry about.  This method includes in the
 // transaction total time request time that is spent in other custom
 // middlewares whereas InstrumentRoutes does not.
+//
+// mux.MiddlewareFuncs run once the request has been matched, so the
+// transaction is named after the template of the matched route, as returned
+// by mux.CurrentRoute, including routes registered on subrouters.  If the
+// request context already holds a transaction, for example because this
+// middleware is registered on both a router and one of its subrouters or
+// because the router is wrapped with newrelic.WrapHandle, no new transaction
+// is started: the existing transaction is renamed after the matched route.
 func Middleware(app *newrelic.Application) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if txn := newrelic.FromContext(r.Context()); nil != txn {
+				if nil != mux.CurrentRoute(r) {
+					txn.SetName(routeName(r))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
 			name := routeName(r)
 			txn := app.StartTransaction(name)
 			defer txn.End()
@@ -255,3 +255,86 @@ func TestMiddlewareMethodNotAllowedHandler(t *testing.T) {
 	// make sure no txn events were created
 	app.ExpectTxnEvents(t, []internal.WantEvent{})
 }
+
+func TestMiddlewareNestedSubrouter(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	v1 := api.PathPrefix("/v1").Subrouter()
+	v1.Handle("/users/{id}", makeHandler("user"))
+	r.Use(Middleware(app.Application))
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /api/v1/users/{id}",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestMiddlewareRouterAndSubrouter(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	v1 := api.PathPrefix("/v1").Subrouter()
+	v1.Handle("/users/{id}", makeHandler("user"))
+	r.Use(Middleware(app.Application))
+	v1.Use(Middleware(app.Application))
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	// Only a single transaction must be created.
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/GET /api/v1/users/{id}",
+			"nr.apdexPerfZone": internal.MatchAnything,
+			"sampled":          internal.MatchAnything,
+			"guid":             internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+			"priority":         internal.MatchAnything,
+		},
+		AgentAttributes: map[string]interface{}{
+			"httpResponseCode":             200,
+			"http.statusCode":              200,
+			"request.method":               "GET",
+			"request.uri":                  "/api/v1/users/123",
+			"response.headers.contentType": "text/plain; charset=utf-8",
+		},
+	}})
+}
+
+func TestMiddlewareWrappedRouter(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	r := mux.NewRouter()
+	users := r.PathPrefix("/users").Subrouter()
+	users.Handle("/{id}", makeHandler("user"))
+	users.Use(Middleware(app.Application))
+	_, h := newrelic.WrapHandle(app.Application, "/", r)
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "GET /users/{id}",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
@@ -0,0 +1,9 @@
+module github.com/newrelic/go-agent/v3/integrations/nrgqlgen
+
+go 1.17
+
+require (
+	github.com/99designs/gqlgen v0.17.20
+	github.com/newrelic/go-agent/v3 v3.18.2
+	github.com/vektah/gqlparser/v2 v2.5.1
+)
@@ -0,0 +1,222 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nrgqlgen instruments https://github.com/99designs/gqlgen
+// applications.
+//
+// This package provides an Extension that names the transaction after the
+// GraphQL operation being executed and creates a segment for each field
+// resolver.  The operation type (query, mutation or subscription) is recorded
+// as the "graphql.operation.type" attribute.  Transactions are named after the
+// operation name, or a hash of the query for anonymous operations, never
+// after the query text itself.  Use WithOperationNamer to customize the
+// transaction names.
+//
+// Each error returned in the GraphQL response is noticed on the transaction.
+// Errors raised while parsing or validating the request use the error class
+// "GraphQLValidationError", all other errors use the class
+// "GraphQLResolverError" and carry the path of the field that failed, such as
+// "user.posts.0.title", in the "graphql.field.path" error attribute.
+//
+// Add the Extension to your gqlgen server:
+//
+//	srv := handler.NewDefaultServer(generated.NewExecutableSchema(cfg))
+//	srv.Use(nrgqlgen.Extension{})
+//	http.Handle(newrelic.WrapHandle(app, "/query", srv))
+//
+// Please note that you must also instrument your web request handlers
+// and put the transaction into the context object in order to
+// utilize this instrumentation. For example, you could use
+// newrelic.WrapHandle (https://godoc.org/github.com/newrelic/go-agent/v3/newrelic#WrapHandle)
+// or newrelic.WrapHandleFunc (https://godoc.org/github.com/newrelic/go-agent/v3/newrelic#WrapHandleFunc)
+// or you could use a New Relic integration for the web framework you are using
+// if it is available (for example,
+// https://godoc.org/github.com/newrelic/go-agent/v3/integrations/nrgorilla)
+package nrgqlgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func init() { internal.TrackUsage("integration", "framework", "gqlgen") }
+
+const (
+	// AttributeOperationType is the attribute holding the type of the GraphQL
+	// operation: query, mutation or subscription.
+	AttributeOperationType = "graphql.operation.type"
+	// AttributeOperationName is the attribute holding the name of the GraphQL
+	// operation.  It is not added for anonymous operations.
+	AttributeOperationName = "graphql.operation.name"
+	// AttributeFieldPath is the error attribute holding the path of the
+	// field whose resolver failed.
+	AttributeFieldPath = "graphql.field.path"
+)
+
+const (
+	// ValidationErrorClass is the class of errors raised while parsing or
+	// validating a GraphQL request.
+	ValidationErrorClass = "GraphQLValidationError"
+	// ResolverErrorClass is the class of errors raised while resolving
+	// fields.
+	ResolverErrorClass = "GraphQLResolverError"
+)
+
+// OperationNamer returns the transaction name for a GraphQL operation.  Names
+// should have a bounded cardinality: avoid using the query text.
+type OperationNamer func(oc *graphql.OperationContext) string
+
+// DefaultOperationNamer names transactions "GraphQL/<type>/<name>", where type
+// is the operation type and name is the operation name.  Anonymous operations
+// are named after a hash of the query instead, such as
+// "GraphQL/query/Anonymous/3f1c6c4e9a2b7d10".
+func DefaultOperationNamer(oc *graphql.OperationContext) string {
+	name := "GraphQL/" + string(oc.Operation.Operation) + "/"
+	if "" != oc.Operation.Name {
+		return name + oc.Operation.Name
+	}
+	sum := sha256.Sum256([]byte(oc.RawQuery))
+	return name + "Anonymous/" + hex.EncodeToString(sum[:8])
+}
+
+// Extension is a gqlgen extension that names transactions after the GraphQL
+// operation and creates a segment for each field resolver.  The zero value
+// uses DefaultOperationNamer, use NewExtension to configure it.
+type Extension struct {
+	namer OperationNamer
+}
+
+// Option configures an Extension created by NewExtension.
+type Option func(*Extension)
+
+// WithOperationNamer sets the function used to name transactions after
+// GraphQL operations in place of DefaultOperationNamer.
+func WithOperationNamer(namer OperationNamer) Option {
+	return func(e *Extension) { e.namer = namer }
+}
+
+// NewExtension creates an Extension configured with the given options.
+//
+//	srv.Use(nrgqlgen.NewExtension(nrgqlgen.WithOperationNamer(namer)))
+func NewExtension(opts ...Option) Extension {
+	var e Extension
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+	graphql.ResponseInterceptor
+} = Extension{}
+
+// ExtensionName returns the name of the extension
+func (Extension) ExtensionName() string {
+	return "NewRelic"
+}
+
+// Validate is called when the extension is added to a server - in this case,
+// a noop
+func (Extension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation names the transaction found in the context after the
+// operation and records the operation type and name as attributes.
+func (e Extension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	txn := newrelic.FromContext(ctx)
+	if nil == txn || !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if nil == oc.Operation {
+		return next(ctx)
+	}
+	namer := e.namer
+	if nil == namer {
+		namer = DefaultOperationNamer
+	}
+	txn.SetName(namer(oc))
+	txn.AddAttribute(AttributeOperationType, string(oc.Operation.Operation))
+	if "" != oc.Operation.Name {
+		txn.AddAttribute(AttributeOperationName, oc.Operation.Name)
+	}
+	return next(ctx)
+}
+
+// InterceptField creates a segment named "ResolveField:<Object>.<field>" for
+// each field with a resolver.
+func (Extension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	txn := newrelic.FromContext(ctx)
+	fc := graphql.GetFieldContext(ctx)
+	if nil == txn || nil == fc || !fc.IsResolver {
+		return next(ctx)
+	}
+	// gqlgen may run resolvers concurrently, therefore each resolver gets
+	// its own goroutine transaction.
+	txn = txn.NewGoroutine()
+	seg := txn.StartSegment("ResolveField:" + fc.Object + "." + fc.Field.Name)
+	defer seg.End()
+	return next(newrelic.NewContext(ctx, txn))
+}
+
+// InterceptResponse notices each error of the GraphQL response on the
+// transaction found in the context.
+func (Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	txn := newrelic.FromContext(ctx)
+	if nil == txn || nil == resp {
+		return resp
+	}
+	for _, err := range resp.Errors {
+		txn.NoticeError(responseError(err))
+	}
+	return resp
+}
+
+func responseError(err *gqlerror.Error) newrelic.Error {
+	if errcode.KindProtocol == errcode.GetErrorKind(gqlerror.List{err}) {
+		return newrelic.Error{
+			Message: err.Message,
+			Class:   ValidationErrorClass,
+		}
+	}
+	e := newrelic.Error{
+		Message: err.Message,
+		Class:   ResolverErrorClass,
+	}
+	if len(err.Path) > 0 {
+		e.Attributes = map[string]interface{}{
+			AttributeFieldPath: fieldPath(err.Path),
+		}
+	}
+	return e
+}
+
+// fieldPath formats the path of a field with its elements separated by dots,
+// list indexes included: "user.posts.0.title".
+func fieldPath(path ast.Path) string {
+	elems := make([]string, len(path))
+	for i, elem := range path {
+		switch v := elem.(type) {
+		case ast.PathIndex:
+			elems[i] = strconv.Itoa(int(v))
+		case ast.PathName:
+			elems[i] = string(v)
+		}
+	}
+	return strings.Join(elems, ".")
+}
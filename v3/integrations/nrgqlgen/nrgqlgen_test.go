@@ -0,0 +1,347 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrgqlgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+type Query {
+	hello: String!
+	user: User!
+}
+
+type User {
+	name: String!
+	posts: [Post!]!
+}
+
+type Post {
+	title: String!
+}
+
+type Mutation {
+	rename(name: String!): User!
+}
+`
+
+type resolverFunc func(ctx context.Context) (interface{}, error)
+
+// testExecutableSchema returns a graphql.ExecutableSchema that resolves every
+// field of testSchema with the matching entry of resolvers, keyed by
+// "<Object>.<field>", the same way gqlgen generated code does: each field is
+// run through the resolver middleware with its own graphql.FieldContext.
+func testExecutableSchema(resolvers map[string]resolverFunc) graphql.ExecutableSchema {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: testSchema})
+	return &graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return schema },
+		ComplexityFunc: func(typeName string, fieldName string, childComplexity int, args map[string]interface{}) (int, bool) {
+			return 0, false
+		},
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			first := true
+			return func(ctx context.Context) *graphql.Response {
+				if !first {
+					return nil
+				}
+				first = false
+				rc := graphql.GetOperationContext(ctx)
+				object := "Query"
+				if rc.Operation.Operation == ast.Mutation {
+					object = "Mutation"
+				}
+				data, _ := json.Marshal(executeSelection(ctx, rc, nil, object, rc.Operation.SelectionSet, resolvers))
+				return &graphql.Response{Data: data}
+			}
+		},
+	}
+}
+
+func executeSelection(ctx context.Context, rc *graphql.OperationContext, parent *graphql.FieldContext, object string, sel ast.SelectionSet, resolvers map[string]resolverFunc) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, field := range graphql.CollectFields(rc, sel, []string{object}) {
+		fc := &graphql.FieldContext{
+			Parent:     parent,
+			Object:     object,
+			Field:      field,
+			IsResolver: true,
+		}
+		fieldCtx := graphql.WithFieldContext(ctx, fc)
+		resolver := resolvers[object+"."+field.Name]
+		res, err := rc.ResolverMiddleware(fieldCtx, func(ctx context.Context) (interface{}, error) {
+			return resolver(ctx)
+		})
+		if nil != err {
+			graphql.AddError(fieldCtx, err)
+			out[field.Alias] = nil
+			continue
+		}
+		fieldType := field.Definition.Type
+		switch {
+		case nil != fieldType.Elem:
+			var list []interface{}
+			for i := range res.([]interface{}) {
+				idx := i
+				ifc := &graphql.FieldContext{Parent: fc, Index: &idx}
+				list = append(list, executeSelection(graphql.WithFieldContext(fieldCtx, ifc), rc, ifc, fieldType.Elem.Name(), field.Selections, resolvers))
+			}
+			out[field.Alias] = list
+		case len(field.Selections) > 0:
+			out[field.Alias] = executeSelection(fieldCtx, rc, fc, fieldType.Name(), field.Selections, resolvers)
+		default:
+			out[field.Alias] = res
+		}
+	}
+	return out
+}
+
+func value(v interface{}) resolverFunc {
+	return func(context.Context) (interface{}, error) { return v, nil }
+}
+
+var testResolvers = map[string]resolverFunc{
+	"Query.hello":     value("world"),
+	"Query.user":      value(struct{}{}),
+	"User.name":       value("gopher"),
+	"User.posts":      value([]interface{}{struct{}{}, struct{}{}}),
+	"Post.title":      value("title"),
+	"Mutation.rename": value(struct{}{}),
+}
+
+func doQuery(app integrationsupport.ExpectApp, resolvers map[string]resolverFunc, body string, opts ...Option) *httptest.ResponseRecorder {
+	srv := handler.New(testExecutableSchema(resolvers))
+	srv.AddTransport(transport.POST{})
+	srv.Use(NewExtension(opts...))
+
+	txn := app.StartTransaction("query")
+	req := httptest.NewRequest("POST", "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = newrelic.RequestWithTransactionContext(req, txn)
+	rw := httptest.NewRecorder()
+	srv.ServeHTTP(rw, req)
+	txn.End()
+	return rw
+}
+
+func TestExtension(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	rw := doQuery(app, testResolvers, `{"query": "query GetUser { hello user { name posts { title } } }"}`)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("unexpected response code %d: %s", rw.Code, rw.Body.String())
+	}
+	if body := rw.Body.String(); !strings.Contains(body, `"name":"gopher"`) {
+		t.Errorf("unexpected response body: %s", body)
+	}
+
+	scope := "OtherTransaction/Go/GraphQL/query/GetUser"
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "OtherTransaction/all", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime", Scope: "", Forced: true, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/all", Scope: "", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/allOther", Scope: "", Forced: false, Data: nil},
+		{Name: scope, Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime/Go/GraphQL/query/GetUser", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/ResolveField:Query.hello", Scope: "", Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:Query.hello", Scope: scope, Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:Query.user", Scope: "", Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:Query.user", Scope: scope, Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:User.name", Scope: "", Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:User.name", Scope: scope, Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:User.posts", Scope: "", Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:User.posts", Scope: scope, Forced: false, Data: []float64{1}},
+		{Name: "Custom/ResolveField:Post.title", Scope: "", Forced: false, Data: []float64{2}},
+		{Name: "Custom/ResolveField:Post.title", Scope: scope, Forced: false, Data: []float64{2}},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     scope,
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeOperationType: "query",
+				AttributeOperationName: "GetUser",
+			},
+		},
+	})
+}
+
+func TestExtensionMutation(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	doQuery(app, testResolvers, `{"query": "mutation Rename { rename(name: \"gopher\") { name } }"}`)
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/GraphQL/mutation/Rename", Scope: "", Forced: true, Data: nil},
+		{Name: "Custom/ResolveField:Mutation.rename", Scope: "OtherTransaction/Go/GraphQL/mutation/Rename", Forced: false, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     "OtherTransaction/Go/GraphQL/mutation/Rename",
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeOperationType: "mutation",
+				AttributeOperationName: "Rename",
+			},
+		},
+	})
+}
+
+func TestExtensionAnonymousOperation(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	query := "{ hello }"
+	doQuery(app, testResolvers, `{"query": "`+query+`"}`)
+
+	sum := sha256.Sum256([]byte(query))
+	name := "OtherTransaction/Go/GraphQL/query/Anonymous/" + hex.EncodeToString(sum[:8])
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     name,
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeOperationType: "query",
+			},
+		},
+	})
+}
+
+func TestDefaultOperationNamerAnonymous(t *testing.T) {
+	// Anonymous operations with different queries must not share a name,
+	// while the same query must always get the same name.
+	names := make(map[string]bool)
+	for _, query := range []string{"{ hello }", "{ user { name } }", "{ hello }"} {
+		oc := &graphql.OperationContext{
+			RawQuery:  query,
+			Operation: &ast.OperationDefinition{Operation: ast.Query},
+		}
+		names[DefaultOperationNamer(oc)] = true
+	}
+	if len(names) != 2 {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestExtensionWithOperationNamer(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	doQuery(app, testResolvers, `{"query": "query GetUser { hello }"}`,
+		WithOperationNamer(func(oc *graphql.OperationContext) string {
+			return "api/" + oc.Operation.Name
+		}))
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     "OtherTransaction/Go/api/GetUser",
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeOperationType: "query",
+				AttributeOperationName: "GetUser",
+			},
+		},
+	})
+}
+
+func TestExtensionResolverError(t *testing.T) {
+	resolvers := make(map[string]resolverFunc)
+	for name, resolver := range testResolvers {
+		resolvers[name] = resolver
+	}
+	var calls int
+	resolvers["Post.title"] = func(context.Context) (interface{}, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("title unavailable")
+		}
+		return "title", nil
+	}
+
+	app := integrationsupport.NewBasicTestApp()
+	rw := doQuery(app, resolvers, `{"query": "query GetUser { user { posts { title } } }"}`)
+
+	if body := rw.Body.String(); !strings.Contains(body, `"path":["user","posts",1,"title"]`) {
+		t.Errorf("unexpected response body: %s", body)
+	}
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName: "OtherTransaction/Go/GraphQL/query/GetUser",
+		Msg:     "title unavailable",
+		Klass:   ResolverErrorClass,
+		UserAttributes: map[string]interface{}{
+			AttributeFieldPath:     "user.posts.1.title",
+			AttributeOperationType: "query",
+			AttributeOperationName: "GetUser",
+		},
+	}})
+}
+
+func TestExtensionValidationError(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	rw := doQuery(app, testResolvers, `{"query": "query GetUser { missing }"}`)
+
+	if rw.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected response code %d: %s", rw.Code, rw.Body.String())
+	}
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName:        "OtherTransaction/Go/query",
+		Msg:            `Cannot query field "missing" on type "Query".`,
+		Klass:          ValidationErrorClass,
+		UserAttributes: map[string]interface{}{},
+	}})
+}
+
+func TestFieldPath(t *testing.T) {
+	path := ast.Path{ast.PathName("user"), ast.PathName("posts"), ast.PathIndex(0), ast.PathName("title")}
+	if p := fieldPath(path); p != "user.posts.0.title" {
+		t.Errorf("unexpected field path: %q", p)
+	}
+}
+
+func TestExtensionNoTransaction(t *testing.T) {
+	srv := handler.New(testExecutableSchema(testResolvers))
+	srv.AddTransport(transport.POST{})
+	srv.Use(Extension{})
+
+	req := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query": "{ hello }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rw := httptest.NewRecorder()
+	srv.ServeHTTP(rw, req)
+
+	if body := rw.Body.String(); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("unexpected response body: %s", body)
+	}
+}
@@ -32,6 +32,18 @@ func getURL(method, target string) *url.URL {
 	}
 }
 
+// clientCallKey is the context key of the clientCall of the calls made
+// through the client interceptors.
+type clientCallKey struct{}
+
+// clientCall counts the attempts of a call made through the client
+// interceptors, which NewClientStatsHandler times.  The attempts of a call,
+// including hedged ones, may overlap, so attempts is updated atomically.
+type clientCall struct {
+	method   string
+	attempts int32
+}
+
 // startClientSegment starts an ExternalSegment and adds Distributed Trace
 // headers to the outgoing grpc metadata in the context.
 func startClientSegment(ctx context.Context, method, target string) (*newrelic.ExternalSegment, context.Context) {
@@ -43,6 +55,7 @@ func startClientSegment(ctx context.Context, method, target string) (*newrelic.E
 		seg.Host = getURL(method, target).Host
 		seg.Library = "gRPC"
 		seg.Procedure = method
+		ctx = context.WithValue(ctx, clientCallKey{}, &clientCall{method: method})
 
 		hdrs := http.Header{}
 		txn.InsertDistributedTraceHeaders(hdrs)
@@ -28,6 +28,11 @@
 //     grpc.StreamInterceptor(nrgrpc.StreamServerInterceptor(app)),
 //  )
 //
+// Servers using the stats handler API rather than interceptors may use
+// NewStatsHandler instead, which records the same transactions:
+//
+//  server := grpc.NewServer(grpc.StatsHandler(nrgrpc.NewStatsHandler(app)))
+//
 // The disposition of each, in terms of how to report each of the various
 // gRPC status codes, is determined by a built-in set of defaults:
 //   OK       OK
@@ -102,6 +107,12 @@
 //	ctx := newrelic.NewContext(context.Background(), txn)
 //	msg, err := client.handler(ctx, &pb.Message{"Hello World"})
 //
+// Each call is recorded with a single external segment, including the calls
+// retried following the retry policy of the service config.  To see the
+// attempts of the calls in their traces, also use NewClientStatsHandler:
+//
+//	grpc.WithStatsHandler(nrgrpc.NewClientStatsHandler())
+//
 // Full client example:
 // https://github.com/newrelic/go-agent/blob/master/v3/integrations/nrgrpc/example/client/client.go
 package nrgrpc
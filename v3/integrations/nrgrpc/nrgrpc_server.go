@@ -42,9 +42,38 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
+// PeerAddressAttribute is the transaction attribute holding the address of
+// the client which made the call, when it is known.
+const PeerAddressAttribute = "peer.address"
+
+// CompressionAttribute is the transaction attribute holding the compression
+// algorithm of the request messages, such as "gzip", or "identity" when they
+// are not compressed.
+const CompressionAttribute = "rpc.grpc.compression"
+
+// compressionAlgorithm returns the algorithm the request messages of the call
+// are compressed with, read from the grpc-encoding header.  gRPC does not
+// pass this reserved header in the incoming metadata, it is read from the
+// server transport stream instead.
+func compressionAlgorithm(ctx context.Context, hdrs http.Header) (string, bool) {
+	encoding := hdrs.Get("grpc-encoding")
+	if encoding == "" {
+		stream, ok := grpc.ServerTransportStreamFromContext(ctx).(interface{ RecvCompress() string })
+		if !ok {
+			return "", false
+		}
+		encoding = stream.RecvCompress()
+	}
+	if encoding == "" {
+		encoding = "identity"
+	}
+	return encoding, true
+}
+
 func startTransaction(ctx context.Context, app *newrelic.Application, fullMethod string) *newrelic.Transaction {
 	method := strings.TrimPrefix(fullMethod, "/")
 
@@ -70,6 +99,13 @@ func startTransaction(ctx context.Context, app *newrelic.Application, fullMethod
 	txn := app.StartTransaction(method)
 	txn.SetWebRequest(webReq)
 
+	if p, ok := peer.FromContext(ctx); ok && nil != p.Addr {
+		txn.AddAttribute(PeerAddressAttribute, p.Addr.String())
+	}
+	if encoding, ok := compressionAlgorithm(ctx, hdrs); ok {
+		txn.AddAttribute(CompressionAttribute, encoding)
+	}
+
 	return txn
 }
 
@@ -111,11 +147,53 @@ var interceptorStatusHandlerRegistry = statusHandlerMap{
 	codes.Unauthenticated:    InfoInterceptorStatusHandler,
 }
 
+//
+// interceptorConfig holds the settings of a server interceptor.
+//
+type interceptorConfig struct {
+	handlers       statusHandlerMap
+	ignoredMethods map[string]struct{}
+}
+
+//
+// interceptorDefaults holds the settings used by each interceptor, which
+// may be changed by Configure.
+//
+var interceptorDefaults = interceptorConfig{
+	handlers:       interceptorStatusHandlerRegistry,
+	ignoredMethods: make(map[string]struct{}),
+}
+
+//
+// newInterceptorConfig copies the current defaults and applies the options.
+//
+func newInterceptorConfig(options []HandlerOption) *interceptorConfig {
+	cfg := &interceptorConfig{
+		handlers:       make(statusHandlerMap),
+		ignoredMethods: make(map[string]struct{}),
+	}
+	for code, handler := range interceptorDefaults.handlers {
+		cfg.handlers[code] = handler
+	}
+	for method := range interceptorDefaults.ignoredMethods {
+		cfg.ignoredMethods[method] = struct{}{}
+	}
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
+
+func (cfg *interceptorConfig) isIgnored(fullMethod string) bool {
+	_, ok := cfg.ignoredMethods[fullMethod]
+	return ok
+}
+
 //
 // HandlerOption is the type for options passed to the interceptor
-// functions to specify gRPC status handlers.
+// functions to specify gRPC status handlers and other settings.
 //
-type HandlerOption func(statusHandlerMap)
+type HandlerOption func(*interceptorConfig)
 
 //
 // WithStatusHandler indicates a handler function to be used to
@@ -149,8 +227,29 @@ type HandlerOption func(statusHandlerMap)
 // to your Configure, StreamServiceInterceptor, or UnaryServiceInterceptor function.
 //
 func WithStatusHandler(c codes.Code, h ErrorHandler) HandlerOption {
-	return func(m statusHandlerMap) {
-		m[c] = h
+	return func(cfg *interceptorConfig) {
+		cfg.handlers[c] = h
+	}
+}
+
+//
+// WithIgnoredMethods indicates gRPC methods for which no transaction is
+// created, such as health checks.  The methods are given by their full name,
+// as found in grpc.UnaryServerInfo.FullMethod, with or without the leading
+// slash:
+//   nrgrpc.WithIgnoredMethods("/grpc.health.v1.Health/Check")
+// The handlers of ignored methods are called without a transaction in their
+// context.  By default no method is ignored.  This option may be given to the
+// Configure, StreamServerInterceptor, or UnaryServerInterceptor functions.
+//
+func WithIgnoredMethods(methods ...string) HandlerOption {
+	return func(cfg *interceptorConfig) {
+		for _, method := range methods {
+			if !strings.HasPrefix(method, "/") {
+				method = "/" + method
+			}
+			cfg.ignoredMethods[method] = struct{}{}
+		}
 	}
 }
 
@@ -163,7 +262,7 @@ func WithStatusHandler(c codes.Code, h ErrorHandler) HandlerOption {
 //
 func Configure(options ...HandlerOption) {
 	for _, option := range options {
-		option(interceptorStatusHandlerRegistry)
+		option(&interceptorDefaults)
 	}
 }
 
@@ -291,6 +390,11 @@ func reportInterceptorStatus(ctx context.Context, txn *newrelic.Transaction, han
 // In this case, those two handlers are used (along with the current defaults for the other status
 // codes) only for that interceptor.
 //
+// To not create transactions for some methods, such as health checks, add
+// WithIgnoredMethods in the same way:
+//   grpc.UnaryInterceptor(nrgrpc.UnaryServerInterceptor(app,
+//     nrgrpc.WithIgnoredMethods("/grpc.health.v1.Health/Check")))
+//
 func UnaryServerInterceptor(app *newrelic.Application, options ...HandlerOption) grpc.UnaryServerInterceptor {
 	if app == nil {
 		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -298,21 +402,19 @@ func UnaryServerInterceptor(app *newrelic.Application, options ...HandlerOption)
 		}
 	}
 
-	localHandlerMap := make(statusHandlerMap)
-	for code, handler := range interceptorStatusHandlerRegistry {
-		localHandlerMap[code] = handler
-	}
-	for _, option := range options {
-		option(localHandlerMap)
-	}
+	cfg := newInterceptorConfig(options)
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if cfg.isIgnored(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
 		txn := startTransaction(ctx, app, info.FullMethod)
 		defer txn.End()
 
 		ctx = newrelic.NewContext(ctx, txn)
 		resp, err = handler(ctx, req)
-		reportInterceptorStatus(ctx, txn, localHandlerMap, err)
+		reportInterceptorStatus(ctx, txn, cfg.handlers, err)
 		return
 	}
 }
@@ -351,20 +453,18 @@ func StreamServerInterceptor(app *newrelic.Application, options ...HandlerOption
 		}
 	}
 
-	localHandlerMap := make(statusHandlerMap)
-	for code, handler := range interceptorStatusHandlerRegistry {
-		localHandlerMap[code] = handler
-	}
-	for _, option := range options {
-		option(localHandlerMap)
-	}
+	cfg := newInterceptorConfig(options)
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if cfg.isIgnored(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
 		txn := startTransaction(ss.Context(), app, info.FullMethod)
 		defer txn.End()
 
 		err := handler(srv, newWrappedServerStream(ss, txn))
-		reportInterceptorStatus(ss.Context(), txn, localHandlerMap, err)
+		reportInterceptorStatus(ss.Context(), txn, cfg.handlers, err)
 		return err
 	}
 }
@@ -8,11 +8,16 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strings"
 	"testing"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/newrelic/go-agent/v3/integrations/nrgrpc/testapp"
@@ -100,7 +105,10 @@ func TestUnaryServerInterceptor(t *testing.T) {
 			"sampled":                  internal.MatchAnything,
 			"traceId":                  internal.MatchAnything,
 		},
-		UserAttributes: map[string]interface{}{},
+		UserAttributes: map[string]interface{}{
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
@@ -128,7 +136,10 @@ func TestUnaryServerInterceptor(t *testing.T) {
 				"parentId":         internal.MatchAnything,
 				"trustedParentId":  internal.MatchAnything,
 			},
-			UserAttributes: map[string]interface{}{},
+			UserAttributes: map[string]interface{}{
+				"peer.address":         "bufconn",
+				"rpc.grpc.compression": "identity",
+			},
 			AgentAttributes: map[string]interface{}{
 				"httpResponseCode":            0,
 				"http.statusCode":             0,
@@ -184,9 +195,11 @@ func TestUnaryServerInterceptorError(t *testing.T) {
 			"traceId":          internal.MatchAnything,
 		},
 		UserAttributes: map[string]interface{}{
-			"grpcStatusMessage": "oooooops!",
-			"grpcStatusCode":    "DataLoss",
-			"grpcStatusLevel":   "error",
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+			"grpcStatusMessage":    "oooooops!",
+			"grpcStatusCode":       "DataLoss",
+			"grpcStatusLevel":      "error",
 		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
@@ -217,9 +230,11 @@ func TestUnaryServerInterceptorError(t *testing.T) {
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnaryError",
 		},
 		UserAttributes: map[string]interface{}{
-			"grpcStatusMessage": "oooooops!",
-			"grpcStatusCode":    "DataLoss",
-			"grpcStatusLevel":   "error",
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+			"grpcStatusMessage":    "oooooops!",
+			"grpcStatusCode":       "DataLoss",
+			"grpcStatusLevel":      "error",
 		},
 	}})
 }
@@ -285,7 +300,10 @@ func TestUnaryStreamServerInterceptor(t *testing.T) {
 			"sampled":                  internal.MatchAnything,
 			"traceId":                  internal.MatchAnything,
 		},
-		UserAttributes: map[string]interface{}{},
+		UserAttributes: map[string]interface{}{
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
@@ -313,7 +331,10 @@ func TestUnaryStreamServerInterceptor(t *testing.T) {
 				"parentId":         internal.MatchAnything,
 				"trustedParentId":  internal.MatchAnything,
 			},
-			UserAttributes: map[string]interface{}{},
+			UserAttributes: map[string]interface{}{
+				"peer.address":         "bufconn",
+				"rpc.grpc.compression": "identity",
+			},
 			AgentAttributes: map[string]interface{}{
 				"httpResponseCode":            0,
 				"http.statusCode":             0,
@@ -389,7 +410,10 @@ func TestStreamUnaryServerInterceptor(t *testing.T) {
 			"sampled":                  internal.MatchAnything,
 			"traceId":                  internal.MatchAnything,
 		},
-		UserAttributes: map[string]interface{}{},
+		UserAttributes: map[string]interface{}{
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
@@ -417,7 +441,10 @@ func TestStreamUnaryServerInterceptor(t *testing.T) {
 				"parentId":         internal.MatchAnything,
 				"trustedParentId":  internal.MatchAnything,
 			},
-			UserAttributes: map[string]interface{}{},
+			UserAttributes: map[string]interface{}{
+				"peer.address":         "bufconn",
+				"rpc.grpc.compression": "identity",
+			},
 			AgentAttributes: map[string]interface{}{
 				"httpResponseCode":            0,
 				"http.statusCode":             0,
@@ -514,7 +541,10 @@ func TestStreamStreamServerInterceptor(t *testing.T) {
 			"sampled":                  internal.MatchAnything,
 			"traceId":                  internal.MatchAnything,
 		},
-		UserAttributes: map[string]interface{}{},
+		UserAttributes: map[string]interface{}{
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
 			"http.statusCode":             0,
@@ -542,7 +572,10 @@ func TestStreamStreamServerInterceptor(t *testing.T) {
 				"parentId":         internal.MatchAnything,
 				"trustedParentId":  internal.MatchAnything,
 			},
-			UserAttributes: map[string]interface{}{},
+			UserAttributes: map[string]interface{}{
+				"peer.address":         "bufconn",
+				"rpc.grpc.compression": "identity",
+			},
 			AgentAttributes: map[string]interface{}{
 				"httpResponseCode":            0,
 				"http.statusCode":             0,
@@ -602,9 +635,11 @@ func TestStreamServerInterceptorError(t *testing.T) {
 			"traceId":          internal.MatchAnything,
 		},
 		UserAttributes: map[string]interface{}{
-			"grpcStatusLevel":   "error",
-			"grpcStatusMessage": "oooooops!",
-			"grpcStatusCode":    "DataLoss",
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+			"grpcStatusLevel":      "error",
+			"grpcStatusMessage":    "oooooops!",
+			"grpcStatusCode":       "DataLoss",
 		},
 		AgentAttributes: map[string]interface{}{
 			"httpResponseCode":            0,
@@ -635,9 +670,11 @@ func TestStreamServerInterceptorError(t *testing.T) {
 			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryStreamError",
 		},
 		UserAttributes: map[string]interface{}{
-			"grpcStatusLevel":   "error",
-			"grpcStatusMessage": "oooooops!",
-			"grpcStatusCode":    "DataLoss",
+			"peer.address":         "bufconn",
+			"rpc.grpc.compression": "identity",
+			"grpcStatusLevel":      "error",
+			"grpcStatusMessage":    "oooooops!",
+			"grpcStatusCode":       "DataLoss",
 		},
 	}})
 }
@@ -699,3 +736,171 @@ func TestInterceptorsNilAppReturnNonNil(t *testing.T) {
 		t.Error("StreamServerInterceptor returned nil")
 	}
 }
+
+func TestServerInterceptorIgnoredMethods(t *testing.T) {
+	app := testApp()
+
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(app.Application,
+			WithIgnoredMethods("/grpc.health.v1.Health/Check"))),
+		grpc.StreamInterceptor(StreamServerInterceptor(app.Application,
+			WithIgnoredMethods("grpc.health.v1.Health/Watch"))),
+	)
+	testapp.RegisterTestApplicationServer(s, &testapp.Server{})
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		s.Serve(lis)
+	}()
+	defer s.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		t.Fatal("failure to create ClientConn", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	healthClient := healthpb.NewHealthClient(conn)
+	if _, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal("unable to call health Check", err)
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	stream, err := healthClient.Watch(watchCtx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal("unable to call health Watch", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal("unable to receive health Watch", err)
+	}
+	cancel()
+
+	client := testapp.NewTestApplicationClient(conn)
+	if _, err := client.DoUnaryUnary(ctx, &testapp.Message{}); err != nil {
+		t.Fatal("unable to call client DoUnaryUnary", err)
+	}
+
+	// Only the DoUnaryUnary call is recorded.
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "Apdex", Scope: "", Forced: true, Data: nil},
+		{Name: "Apdex/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/DoUnaryUnary", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/DoUnaryUnary", Scope: "WebTransaction/Go/TestApplication/DoUnaryUnary", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/HTTP/all", Scope: "", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/HTTP/allWeb", Scope: "", Forced: false, Data: nil},
+		{Name: "HttpDispatcher", Scope: "", Forced: true, Data: nil},
+		{Name: "WebTransaction", Scope: "", Forced: true, Data: nil},
+		{Name: "WebTransaction/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: true, Data: nil},
+		{Name: "WebTransactionTotalTime", Scope: "", Forced: true, Data: nil},
+		{Name: "WebTransactionTotalTime/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: false, Data: nil},
+	})
+}
+
+func TestUnaryServerInterceptorPeerAddress(t *testing.T) {
+	app := testApp()
+	interceptor := UnaryServerInterceptor(app.Application)
+	info := &grpc.UnaryServerInfo{FullMethod: "/TestApplication/DoUnaryUnary"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4321}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	// Calls without peer information do not record the attribute.
+	if _, err := interceptor(context.Background(), nil, info, handler); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"guid":             internal.MatchAnything,
+				"name":             "WebTransaction/Go/TestApplication/DoUnaryUnary",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+				"sampled":          internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				PeerAddressAttribute: "10.0.0.1:4321",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"guid":             internal.MatchAnything,
+				"name":             "WebTransaction/Go/TestApplication/DoUnaryUnary",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+				"sampled":          internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestUnaryServerInterceptorCompression(t *testing.T) {
+	app := testApp()
+
+	s, conn := newTestServerAndConn(t, app.Application)
+	defer s.Stop()
+	defer conn.Close()
+
+	client := testapp.NewTestApplicationClient(conn)
+	_, err := client.DoUnaryUnary(context.Background(), &testapp.Message{}, grpc.UseCompressor(gzip.Name))
+	if err != nil {
+		t.Fatal("unable to call client DoUnaryUnary", err)
+	}
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"guid":             internal.MatchAnything,
+			"name":             "WebTransaction/Go/TestApplication/DoUnaryUnary",
+			"nr.apdexPerfZone": internal.MatchAnything,
+			"priority":         internal.MatchAnything,
+			"sampled":          internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"peer.address":       "bufconn",
+			CompressionAttribute: "gzip",
+		},
+		AgentAttributes: map[string]interface{}{
+			"httpResponseCode":            0,
+			"http.statusCode":             0,
+			"request.headers.contentType": "application/grpc",
+			"request.method":              "TestApplication/DoUnaryUnary",
+			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnary",
+		},
+	}})
+}
+
+func TestCompressionAlgorithm(t *testing.T) {
+	testcases := []struct {
+		name     string
+		hdrs     http.Header
+		encoding string
+		ok       bool
+	}{
+		{name: "no stream", ok: false},
+		{name: "metadata", hdrs: http.Header{"Grpc-Encoding": {"gzip"}}, encoding: "gzip", ok: true},
+		{name: "metadata identity", hdrs: http.Header{"Grpc-Encoding": {"identity"}}, encoding: "identity", ok: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoding, ok := compressionAlgorithm(context.Background(), tc.hdrs)
+			if encoding != tc.encoding || ok != tc.ok {
+				t.Errorf("got %q, %v; want %q, %v", encoding, ok, tc.encoding, tc.ok)
+			}
+		})
+	}
+}
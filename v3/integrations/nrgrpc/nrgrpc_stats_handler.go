@@ -0,0 +1,135 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrgrpc
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"google.golang.org/grpc/stats"
+)
+
+type statsHandlerKey struct{}
+
+// statsHandler is the stats.Handler returned by NewStatsHandler.
+type statsHandler struct {
+	app *newrelic.Application
+	cfg *interceptorConfig
+}
+
+// NewStatsHandler creates a stats.Handler instrumenting server RPCs, for
+// servers using the stats handler API rather than interceptors:
+//
+//	server := grpc.NewServer(grpc.StatsHandler(nrgrpc.NewStatsHandler(app)))
+//
+// It records each unary and streaming call with a transaction like
+// UnaryServerInterceptor and StreamServerInterceptor do, and accepts the
+// same options.  The transaction is added to the call context, so it may be
+// accessed in your method handlers using newrelic.FromContext.  The handler
+// must not be used with both the interceptors, which would record each call
+// twice, nor on the client side.
+func NewStatsHandler(app *newrelic.Application, options ...HandlerOption) stats.Handler {
+	return &statsHandler{
+		app: app,
+		cfg: newInterceptorConfig(options),
+	}
+}
+
+// TagRPC starts the transaction of the call.
+func (h *statsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if h.app == nil || h.cfg.isIgnored(info.FullMethodName) {
+		return ctx
+	}
+	txn := startTransaction(ctx, h.app, info.FullMethodName)
+	// The transaction is also held under a key of the handler so that
+	// HandleRPC only ends the transactions it started.
+	ctx = context.WithValue(ctx, statsHandlerKey{}, txn)
+	return newrelic.NewContext(ctx, txn)
+}
+
+// HandleRPC reports the status of the call and ends its transaction once the
+// call has ended.
+func (h *statsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || end.IsClient() {
+		return
+	}
+	txn, ok := ctx.Value(statsHandlerKey{}).(*newrelic.Transaction)
+	if !ok {
+		return
+	}
+	reportInterceptorStatus(ctx, txn, h.cfg.handlers, end.Error)
+	txn.End()
+}
+
+// TagConn implements stats.Handler.
+func (h *statsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (h *statsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// AttemptAttribute is the attribute of the segments recorded by the handler
+// returned by NewClientStatsHandler holding the number of the attempt, from
+// 1 for the first attempt of the call.
+const AttemptAttribute = "grpc.attempt"
+
+type clientAttemptKey struct{}
+
+// clientStatsHandler is the stats.Handler returned by NewClientStatsHandler.
+type clientStatsHandler struct{}
+
+// NewClientStatsHandler creates a stats.Handler timing each attempt of the
+// client calls with a segment, for clients retrying calls with a retry
+// policy in their service config:
+//
+//	conn, err := grpc.Dial(
+//		"localhost:8080",
+//		grpc.WithUnaryInterceptor(nrgrpc.UnaryClientInterceptor),
+//		grpc.WithStreamInterceptor(nrgrpc.StreamClientInterceptor),
+//		grpc.WithStatsHandler(nrgrpc.NewClientStatsHandler()),
+//	)
+//
+// The interceptors record a single external segment for each call, whatever
+// its number of attempts.  The handler records the attempts as children of
+// that segment, named after the method followed by "/attempt", with their
+// number in the AttemptAttribute attribute, so that the retries of the call
+// are visible in its trace.  Calls not made through the interceptors are not
+// recorded.  The handler must not be used on the server side.
+func NewClientStatsHandler() stats.Handler {
+	return clientStatsHandler{}
+}
+
+// TagRPC starts the segment of the attempt.
+func (clientStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	call, ok := ctx.Value(clientCallKey{}).(*clientCall)
+	if !ok {
+		return ctx
+	}
+	attempt := atomic.AddInt32(&call.attempts, 1)
+	seg := newrelic.FromContext(ctx).StartSegment(call.method + "/attempt")
+	seg.AddAttribute(AttemptAttribute, int(attempt))
+	return context.WithValue(ctx, clientAttemptKey{}, seg)
+}
+
+// HandleRPC ends the segment of the attempt once the attempt has ended.
+func (clientStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || !end.IsClient() {
+		return
+	}
+	if seg, ok := ctx.Value(clientAttemptKey{}).(*newrelic.Segment); ok {
+		seg.End()
+	}
+}
+
+// TagConn implements stats.Handler.
+func (clientStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (clientStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,280 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrgrpc
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/newrelic/go-agent/v3/integrations/nrgrpc/testapp"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// handleStatsRPC drives the handler with the stats of a server call, as
+// gRPC does, and returns the context of the call.
+func handleStatsRPC(h stats.Handler, ctx context.Context, method string, err error) context.Context {
+	ctx = h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: method})
+	begin := time.Now()
+	h.HandleRPC(ctx, &stats.Begin{BeginTime: begin})
+	h.HandleRPC(ctx, &stats.InHeader{FullMethod: method})
+	h.HandleRPC(ctx, &stats.End{BeginTime: begin, EndTime: time.Now(), Error: err})
+	return ctx
+}
+
+func TestStatsHandler(t *testing.T) {
+	app := testApp()
+	h := NewStatsHandler(app.Application)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4321}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		":authority", "bufnet",
+		"content-type", "application/grpc",
+		"grpc-encoding", "gzip",
+	))
+
+	tagged := h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/TestApplication/DoUnaryUnary"})
+	txn := newrelic.FromContext(tagged)
+	if txn == nil {
+		t.Fatal("transaction missing from the call context")
+	}
+	txn.StartSegment("DoUnaryUnary").End()
+	h.HandleRPC(tagged, &stats.End{Error: status.Error(codes.Internal, "oooooops!")})
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "WebTransaction/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: true, Data: nil},
+		{Name: "Custom/DoUnaryUnary", Scope: "WebTransaction/Go/TestApplication/DoUnaryUnary", Forced: false, Data: nil},
+		{Name: "Errors/WebTransaction/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: true, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"guid":             internal.MatchAnything,
+			"name":             "WebTransaction/Go/TestApplication/DoUnaryUnary",
+			"nr.apdexPerfZone": internal.MatchAnything,
+			"priority":         internal.MatchAnything,
+			"sampled":          internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+			"error":            true,
+		},
+		UserAttributes: map[string]interface{}{
+			PeerAddressAttribute: "10.0.0.1:4321",
+			CompressionAttribute: "gzip",
+			"grpcStatusLevel":    "error",
+			"grpcStatusMessage":  "oooooops!",
+			"grpcStatusCode":     "Internal",
+		},
+		AgentAttributes: map[string]interface{}{
+			"httpResponseCode":            0,
+			"http.statusCode":             0,
+			"request.headers.contentType": "application/grpc",
+			"request.method":              "TestApplication/DoUnaryUnary",
+			"request.uri":                 "grpc://bufnet/TestApplication/DoUnaryUnary",
+		},
+	}})
+}
+
+func TestStatsHandlerServer(t *testing.T) {
+	app := testApp()
+	s := grpc.NewServer(grpc.StatsHandler(NewStatsHandler(app.Application)))
+	testapp.RegisterTestApplicationServer(s, &testapp.Server{})
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		t.Fatal("failure to create ClientConn", err)
+	}
+	defer conn.Close()
+
+	client := testapp.NewTestApplicationClient(conn)
+	if _, err := client.DoUnaryUnary(context.Background(), &testapp.Message{}); err != nil {
+		t.Fatal("unable to call client DoUnaryUnary", err)
+	}
+	// The transaction ends once the server has sent the response.
+	s.GracefulStop()
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "WebTransaction/Go/TestApplication/DoUnaryUnary", Scope: "", Forced: true, Data: nil},
+		{Name: "Custom/DoUnaryUnary", Scope: "WebTransaction/Go/TestApplication/DoUnaryUnary", Forced: false, Data: nil},
+	})
+}
+
+func TestStatsHandlerIgnoredMethods(t *testing.T) {
+	app := testApp()
+	h := NewStatsHandler(app.Application, WithIgnoredMethods("/grpc.health.v1.Health/Check"))
+
+	ctx := handleStatsRPC(h, context.Background(), "/grpc.health.v1.Health/Check", nil)
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		t.Error("transaction started for an ignored method")
+	}
+	handleStatsRPC(h, context.Background(), "/TestApplication/DoUnaryUnary", nil)
+
+	app.ExpectTxnMetrics(t, internal.WantTxn{
+		Name:          "TestApplication/DoUnaryUnary",
+		IsWeb:         true,
+		UnknownCaller: true,
+	})
+}
+
+func TestStatsHandlerNilApp(t *testing.T) {
+	h := NewStatsHandler(nil)
+	ctx := handleStatsRPC(h, context.Background(), "/TestApplication/DoUnaryUnary", nil)
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		t.Error("transaction started without an application")
+	}
+}
+
+func TestClientStatsHandlerRetries(t *testing.T) {
+	// The server fails the first attempt of the call with a status retried
+	// by the retry policy of the client.
+	var calls int32
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, status.Error(codes.Unavailable, "try again")
+		}
+		return handler(ctx, req)
+	}))
+	testapp.RegisterTestApplicationServer(s, &testapp.Server{})
+	lis := bufconn.Listen(1024 * 1024)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor),
+		grpc.WithStatsHandler(NewClientStatsHandler()),
+		grpc.WithDefaultServiceConfig(`{
+			"methodConfig": [{
+				"name": [{"service": "TestApplication"}],
+				"retryPolicy": {
+					"maxAttempts": 2,
+					"initialBackoff": "0.001s",
+					"maxBackoff": "0.001s",
+					"backoffMultiplier": 1,
+					"retryableStatusCodes": ["UNAVAILABLE"]
+				}
+			}]
+		}`),
+	)
+	if err != nil {
+		t.Fatal("failure to create ClientConn", err)
+	}
+	defer conn.Close()
+
+	app := testApp()
+	txn := app.StartTransaction("retries")
+	ctx := newrelic.NewContext(context.Background(), txn)
+	client := testapp.NewTestApplicationClient(conn)
+	if _, err := client.DoUnaryUnary(ctx, &testapp.Message{}); err != nil {
+		t.Fatal("client call to DoUnaryUnary failed", err)
+	}
+	txn.End()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"category": "generic",
+				"name":     "Custom/TestApplication/DoUnaryUnary/attempt",
+				"parentId": internal.MatchAnything,
+			},
+			UserAttributes:  map[string]interface{}{AttemptAttribute: 1},
+			AgentAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"category": "generic",
+				"name":     "Custom/TestApplication/DoUnaryUnary/attempt",
+				"parentId": internal.MatchAnything,
+			},
+			UserAttributes:  map[string]interface{}{AttemptAttribute: 2},
+			AgentAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"category":  "http",
+				"component": "gRPC",
+				"name":      "External/bufnet/gRPC/TestApplication/DoUnaryUnary",
+				"parentId":  internal.MatchAnything,
+				"span.kind": "client",
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"category":         "generic",
+				"name":             "OtherTransaction/Go/retries",
+				"transaction.name": "OtherTransaction/Go/retries",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName: "OtherTransaction/Go/retries",
+		Root: internal.WantTraceSegment{
+			SegmentName: "ROOT",
+			Attributes:  map[string]interface{}{},
+			Children: []internal.WantTraceSegment{{
+				SegmentName: "OtherTransaction/Go/retries",
+				Attributes:  map[string]interface{}{"exclusive_duration_millis": internal.MatchAnything},
+				Children: []internal.WantTraceSegment{{
+					SegmentName: "External/bufnet/gRPC/TestApplication/DoUnaryUnary",
+					Attributes:  map[string]interface{}{},
+					Children: []internal.WantTraceSegment{
+						{
+							SegmentName: "Custom/TestApplication/DoUnaryUnary/attempt",
+							Attributes:  map[string]interface{}{},
+						},
+						{
+							SegmentName: "Custom/TestApplication/DoUnaryUnary/attempt",
+							Attributes:  map[string]interface{}{},
+						},
+					},
+				}},
+			}},
+		},
+	}})
+}
+
+func TestClientStatsHandlerWithoutInterceptor(t *testing.T) {
+	app := testApp()
+	txn := app.StartTransaction("no-interceptor")
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	h := NewClientStatsHandler()
+	tagged := h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/TestApplication/DoUnaryUnary"})
+	h.HandleRPC(tagged, &stats.End{Client: true})
+	txn.End()
+
+	app.ExpectTxnMetrics(t, internal.WantTxn{Name: "no-interceptor", UnknownCaller: true})
+}
@@ -36,6 +36,13 @@
 //   	http.ListenAndServe(":8000", router)
 //   }
 //
+// Transactions are named after the method and the route template, such as
+// "GET /hello/:name", rather than the concrete request path.  This keeps the
+// number of transaction names bounded.  To record the values of the path
+// parameters as attributes, create the Router with the WithPathParams option:
+//
+//   router := nrhttprouter.New(app, nrhttprouter.WithPathParams())
+//
 // Runnable example: https://github.com/newrelic/go-agent/tree/master/v3/integrations/nrhttprouter/example/main.go
 package nrhttprouter
 
@@ -49,20 +56,48 @@ import (
 
 func init() { internal.TrackUsage("integration", "framework", "httprouter") }
 
+// PathParamAttributePrefix is the prefix of the attributes holding the path
+// parameters of the request when the WithPathParams option is used.  The
+// parameter ":id" is recorded as the attribute "request.parameters.id".
+const PathParamAttributePrefix = "request.parameters."
+
 // Router should be used in place of httprouter.Router.  Create it using
 // New().
 type Router struct {
 	*httprouter.Router
 
 	application *newrelic.Application
+	pathParams  bool
+}
+
+// Option configures a Router created by New.
+type Option func(*Router)
+
+// WithPathParams records the values of the path parameters of each request as
+// transaction attributes, prefixed with PathParamAttributePrefix.
+func WithPathParams() Option {
+	return func(r *Router) { r.pathParams = true }
 }
 
 // New creates a new Router to be used in place of httprouter.Router.
-func New(app *newrelic.Application) *Router {
-	return &Router{
+func New(app *newrelic.Application, opts ...Option) *Router {
+	r := &Router{
 		Router:      httprouter.New(),
 		application: app,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Router) addPathParams(txn *newrelic.Transaction, ps httprouter.Params) {
+	if !r.pathParams {
+		return
+	}
+	for _, p := range ps {
+		txn.AddAttribute(PathParamAttributePrefix+p.Key, p.Value)
+	}
 }
 
 func txnName(method, path string) string {
@@ -77,6 +112,7 @@ func (r *Router) handle(method string, path string, original httprouter.Handle)
 			txn.SetWebRequestHTTP(req)
 			w = txn.SetWebResponse(w)
 			defer txn.End()
+			r.addPathParams(txn, ps)
 
 			req = newrelic.RequestWithTransactionContext(req, txn)
 
@@ -128,6 +164,15 @@ func (r *Router) Handle(method, path string, h httprouter.Handle) {
 
 // Handler replaces httprouter.Router.Handler.
 func (r *Router) Handler(method, path string, handler http.Handler) {
+	if r.pathParams {
+		original := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if txn := newrelic.FromContext(req.Context()); nil != txn {
+				r.addPathParams(txn, httprouter.ParamsFromContext(req.Context()))
+			}
+			original.ServeHTTP(w, req)
+		})
+	}
 	_, h := newrelic.WrapHandle(r.application, path, handler)
 	r.Router.Handler(method, path, h)
 }
@@ -310,3 +310,84 @@ func TestNotFoundNotSet(t *testing.T) {
 		UnknownCaller: true,
 	})
 }
+
+func TestHandleWithPathParams(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := New(app.Application, WithPathParams())
+
+	router.GET("/users/:id/posts/:post", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Write([]byte("post " + ps.ByName("post")))
+	})
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123/posts/456", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "post 456" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /users/:id/posts/:post",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"sampled":          false,
+				"guid":             internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"request.parameters.id":   "123",
+				"request.parameters.post": "456",
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":             200,
+				"http.statusCode":              200,
+				"request.method":               "GET",
+				"request.uri":                  "/users/123/posts/456",
+				"response.headers.contentType": "text/plain; charset=utf-8",
+			},
+		},
+	})
+}
+
+func TestHandlerWithPathParams(t *testing.T) {
+	app := integrationsupport.NewBasicTestApp()
+	router := New(app.Application, WithPathParams())
+
+	router.Handler("GET", "/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	}))
+	response := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users/123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(response, req)
+	if respBody := response.Body.String(); respBody != "user" {
+		t.Error("wrong response body", respBody)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/GET /users/:id",
+				"nr.apdexPerfZone": internal.MatchAnything,
+				"sampled":          false,
+				"guid":             internal.MatchAnything,
+				"traceId":          internal.MatchAnything,
+				"priority":         internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"request.parameters.id": "123",
+			},
+			AgentAttributes: map[string]interface{}{
+				"httpResponseCode":             200,
+				"http.statusCode":              200,
+				"request.method":               "GET",
+				"request.uri":                  "/users/123",
+				"response.headers.contentType": "text/plain; charset=utf-8",
+			},
+		},
+	})
+}
@@ -28,11 +28,27 @@
 //
 //	ctx = newrelic.NewContext(context.Background(), txn)
 //	resp, err := collection.InsertOne(ctx, bson.M{"name": "pi", "value": 3.14159})
+//
+// The database of each command is recorded in the "db.instance" attribute.
+// When a client serves many databases holding collections of the same name,
+// use WithDatabaseName to also qualify the collections with their database,
+// so that their metrics are distinct:
+//
+//	nrMon := nrmongo.NewCommandMonitor(nil, nrmongo.WithDatabaseName())
+//
+// The commands run within a multi-document MongoDB transaction are grouped
+// under a "mongo transaction" segment, which ends with the commitTransaction
+// or abortTransaction command of the transaction.
+//
+// The segments of the commands which failed because they timed out, for
+// instance because the deadline of their context expired or the server
+// exceeded their maxTimeMS, have the "db.timed_out" attribute set to true.
 package nrmongo
 
 import (
 	"context"
 	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/newrelic/go-agent/v3/internal"
@@ -43,11 +59,74 @@ import (
 func init() { internal.TrackUsage("integration", "datastore", "mongo") }
 
 type mongoMonitor struct {
-	segmentMap  map[int64]*newrelic.DatastoreSegment
-	origCommMon *event.CommandMonitor
+	segmentMap     map[int64]*newrelic.DatastoreSegment
+	origCommMon    *event.CommandMonitor
+	collectionName CollectionFormatter
+	// txnSegments holds the "mongo transaction" segment of the current
+	// transaction of each session, keyed by the session id.
+	txnSegments map[string]*txnSegment
+	// txnEnds holds the session id of the commitTransaction and
+	// abortTransaction commands in progress, keyed by request id.
+	txnEnds map[int64]string
 	sync.Mutex
 }
 
+// txnSegment is the segment grouping the commands of a MongoDB transaction.
+type txnSegment struct {
+	txnNumber int64
+	sgmt      *newrelic.Segment
+}
+
+const txnSegmentName = "mongo transaction"
+
+// TimedOutAttribute is the datastore segment attribute set to true when a
+// command fails because it timed out.
+const TimedOutAttribute = "db.timed_out"
+
+// timeoutFailures are the messages of the failures of the commands that
+// timed out: the context of the operation expired, the server exceeded the
+// maxTimeMS of the command, or the connection timed out.
+var timeoutFailures = []string{
+	"context deadline exceeded",
+	"MaxTimeMSExpired",
+	"i/o timeout",
+}
+
+func isTimeout(failure string) bool {
+	for _, f := range timeoutFailures {
+		if strings.Contains(failure, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionFormatter returns the collection recorded for a command run on
+// the collection of the database.
+type CollectionFormatter func(database, collection string) string
+
+// Option configures the `*event.CommandMonitor` returned by
+// NewCommandMonitor.
+type Option func(*mongoMonitor)
+
+// WithCollectionFormatter sets the function formatting the collection
+// recorded for each command.  It is not called for commands that do not run
+// on a collection.
+func WithCollectionFormatter(formatter CollectionFormatter) Option {
+	return func(m *mongoMonitor) { m.collectionName = formatter }
+}
+
+// WithDatabaseName qualifies the collection recorded for each command with
+// the name of its database: "<database>.<collection>".
+func WithDatabaseName() Option {
+	return WithCollectionFormatter(func(database, collection string) string {
+		if "" == database {
+			return collection
+		}
+		return database + "." + collection
+	})
+}
+
 // The Mongo connection ID is constructed as: `fmt.Sprintf("%s[-%d]", addr, nextConnectionID())`,
 // where addr is of the form `host:port` (or `a.sock` for unix sockets)
 // See https://github.com/mongodb/mongo-go-driver/blob/b39cd78ce7021252efee2fb44aa6e492d67680ef/x/mongo/driver/topology/connection.go#L68
@@ -59,7 +138,7 @@ var connIDPattern = regexp.MustCompile(`([^:\[]+)(?::(\d+))?\[-\d+]`)
 // provided, the original `*event.CommandMonitor` will be called as well.  The
 // returned `*event.CommandMonitor` creates `newrelic.DatastoreSegment`s
 // (https://godoc.org/github.com/newrelic/go-agent#DatastoreSegment) for each
-// database call.
+// database call.  Options may be passed to customize the segments.
 //
 //	// Use `SetMonitor` to register the CommandMonitor.
 //	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(nrmongo.NewCommandMonitor(nil)))
@@ -75,11 +154,14 @@ var connIDPattern = regexp.MustCompile(`([^:\[]+)(?::(\d+))?\[-\d+]`)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
-func NewCommandMonitor(original *event.CommandMonitor) *event.CommandMonitor {
+func NewCommandMonitor(original *event.CommandMonitor, opts ...Option) *event.CommandMonitor {
 	m := mongoMonitor{
 		segmentMap:  make(map[int64]*newrelic.DatastoreSegment),
 		origCommMon: original,
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
 	return &event.CommandMonitor{
 		Started:   m.started,
 		Succeeded: m.succeeded,
@@ -95,11 +177,12 @@ func (m *mongoMonitor) started(ctx context.Context, e *event.CommandStartedEvent
 	if txn == nil {
 		return
 	}
+	m.startTxnSgmt(txn, e)
 	host, port := calcHostAndPort(e.ConnectionID)
 	sgmt := newrelic.DatastoreSegment{
 		StartTime:    txn.StartSegmentNow(),
 		Product:      newrelic.DatastoreMongoDB,
-		Collection:   collName(e),
+		Collection:   m.collection(e),
 		Operation:    e.CommandName,
 		Host:         host,
 		PortPathOrID: port,
@@ -108,6 +191,90 @@ func (m *mongoMonitor) started(ctx context.Context, e *event.CommandStartedEvent
 	m.addSgmt(e, &sgmt)
 }
 
+// sessionTxn returns the session id and transaction number of a command run
+// within a multi-document transaction.  Such commands have autocommit set to
+// false, unlike the retryable writes which also have a transaction number.
+func sessionTxn(e *event.CommandStartedEvent) (string, int64, bool) {
+	if autocommit, ok := e.Command.Lookup("autocommit").BooleanOK(); !ok || autocommit {
+		return "", 0, false
+	}
+	txnNumber, ok := e.Command.Lookup("txnNumber").AsInt64OK()
+	if !ok {
+		return "", 0, false
+	}
+	id, err := e.Command.LookupErr("lsid", "id")
+	if err != nil {
+		return "", 0, false
+	}
+	_, data, ok := id.BinaryOK()
+	if !ok {
+		return "", 0, false
+	}
+	return string(data), txnNumber, true
+}
+
+func isTxnEnd(commandName string) bool {
+	return commandName == "commitTransaction" || commandName == "abortTransaction"
+}
+
+// startTxnSgmt starts the "mongo transaction" segment on the first command of
+// a MongoDB transaction, so that the segments of its commands are its
+// children.  The segment of a previous transaction of the session that was
+// neither committed nor aborted is ended.
+func (m *mongoMonitor) startTxnSgmt(txn *newrelic.Transaction, e *event.CommandStartedEvent) {
+	session, txnNumber, ok := sessionTxn(e)
+	if !ok {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	if isTxnEnd(e.CommandName) {
+		if nil == m.txnEnds {
+			m.txnEnds = make(map[int64]string)
+		}
+		m.txnEnds[e.RequestID] = session
+		return
+	}
+	current := m.txnSegments[session]
+	if nil != current && current.txnNumber == txnNumber {
+		return
+	}
+	if nil != current {
+		current.sgmt.End()
+	}
+	if nil == m.txnSegments {
+		m.txnSegments = make(map[string]*txnSegment)
+	}
+	m.txnSegments[session] = &txnSegment{
+		txnNumber: txnNumber,
+		sgmt:      txn.StartSegment(txnSegmentName),
+	}
+}
+
+// endTxnSgmtIfExists ends the "mongo transaction" segment of the session when
+// its commitTransaction or abortTransaction command completes.
+func (m *mongoMonitor) endTxnSgmtIfExists(id int64) {
+	m.Lock()
+	defer m.Unlock()
+	session, ok := m.txnEnds[id]
+	if !ok {
+		return
+	}
+	delete(m.txnEnds, id)
+	if current := m.txnSegments[session]; nil != current {
+		current.sgmt.End()
+		delete(m.txnSegments, session)
+	}
+}
+
+func (m *mongoMonitor) collection(e *event.CommandStartedEvent) string {
+	coll := collName(e)
+	if nil == m.collectionName || "" == coll {
+		return coll
+	}
+	return m.collectionName(e.DatabaseName, coll)
+}
+
 func collName(e *event.CommandStartedEvent) string {
 	coll := e.Command.Lookup(e.CommandName)
 	collName, _ := coll.StringValueOK()
@@ -128,6 +295,9 @@ func (m *mongoMonitor) succeeded(ctx context.Context, e *event.CommandSucceededE
 }
 
 func (m *mongoMonitor) failed(ctx context.Context, e *event.CommandFailedEvent) {
+	if isTimeout(e.Failure) {
+		m.getSgmt(e.RequestID).AddAttribute(TimedOutAttribute, true)
+	}
 	m.endSgmtIfExists(e.RequestID)
 	if m.origCommMon != nil && m.origCommMon.Failed != nil {
 		m.origCommMon.Failed(ctx, e)
@@ -136,6 +306,13 @@ func (m *mongoMonitor) failed(ctx context.Context, e *event.CommandFailedEvent)
 
 func (m *mongoMonitor) endSgmtIfExists(id int64) {
 	m.getAndRemoveSgmt(id).End()
+	m.endTxnSgmtIfExists(id)
+}
+
+func (m *mongoMonitor) getSgmt(id int64) *newrelic.DatastoreSegment {
+	m.Lock()
+	defer m.Unlock()
+	return m.segmentMap[id]
 }
 
 func (m *mongoMonitor) getAndRemoveSgmt(id int64) *newrelic.DatastoreSegment {
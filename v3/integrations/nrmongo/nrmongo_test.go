@@ -68,6 +68,37 @@ func TestOrigMonitorsAreCalled(t *testing.T) {
 	}
 }
 
+func TestOrigMonitorsAreCalledWithTransaction(t *testing.T) {
+	var started []*event.CommandStartedEvent
+	var succeeded []*event.CommandSucceededEvent
+	origMonitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			started = append(started, e)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			succeeded = append(succeeded, e)
+		},
+	}
+	nrMonitor := NewCommandMonitor(origMonitor)
+
+	app := createTestApp()
+	txn := app.StartTransaction("txnName")
+	ctx := newrelic.NewContext(context.Background(), txn)
+	nrMonitor.Started(ctx, ste)
+	nrMonitor.Succeeded(ctx, se)
+	txn.End()
+
+	if len(started) != 1 || started[0] != ste {
+		t.Error("original monitor did not receive the started event:", started)
+	}
+	if len(succeeded) != 1 || succeeded[0] != se {
+		t.Error("original monitor did not receive the succeeded event:", succeeded)
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Datastore/statement/MongoDB/collName/commName", Scope: "OtherTransaction/Go/txnName", Forced: false, Data: []float64{1.0}},
+	})
+}
+
 func TestClientOptsWithNullFunctions(t *testing.T) {
 	origMonitor := &event.CommandMonitor{} // the monitor isn't nil, but its functions are.
 	ctx := context.Background()
@@ -213,6 +244,68 @@ func TestMonitor(t *testing.T) {
 	})
 }
 
+func TestMonitorTimeout(t *testing.T) {
+	testcases := []struct {
+		failure  string
+		timedOut bool
+	}{
+		{failure: "context deadline exceeded", timedOut: true},
+		{failure: "(MaxTimeMSExpired) operation exceeded time limit", timedOut: true},
+		{failure: "connection(localhost:27017[-1]) incomplete read of message header: read tcp 127.0.0.1:50000->127.0.0.1:27017: i/o timeout", timedOut: true},
+		{failure: "failureCause", timedOut: false},
+	}
+	for _, tc := range testcases {
+		nrMonitor := mongoMonitor{
+			segmentMap: make(map[int64]*newrelic.DatastoreSegment),
+		}
+		app := createTestApp()
+		txn := app.StartTransaction("txnName")
+		ctx := newrelic.NewContext(context.Background(), txn)
+		nrMonitor.started(ctx, ste)
+		nrMonitor.failed(ctx, &event.CommandFailedEvent{
+			CommandFinishedEvent: finishedEvent,
+			Failure:              tc.failure,
+		})
+		txn.End()
+
+		userAttributes := map[string]interface{}{}
+		if tc.timedOut {
+			userAttributes[TimedOutAttribute] = true
+		}
+		app.ExpectSpanEvents(t, []internal.WantEvent{
+			{
+				Intrinsics: map[string]interface{}{
+					"name":      "Datastore/statement/MongoDB/collName/commName",
+					"sampled":   true,
+					"category":  "datastore",
+					"component": "MongoDB",
+					"span.kind": "client",
+					"parentId":  internal.MatchAnything,
+				},
+				UserAttributes: userAttributes,
+				AgentAttributes: map[string]interface{}{
+					"peer.address":  thisHost + ":27017",
+					"peer.hostname": thisHost,
+					"db.statement":  "'commName' on 'collName' using 'MongoDB'",
+					"db.instance":   "testdb",
+					"db.collection": "collName",
+				},
+			},
+			{
+				Intrinsics: map[string]interface{}{
+					"name":             "OtherTransaction/Go/txnName",
+					"transaction.name": "OtherTransaction/Go/txnName",
+					"sampled":          true,
+					"category":         "generic",
+					"nr.entryPoint":    true,
+				},
+				UserAttributes:  map[string]interface{}{},
+				AgentAttributes: map[string]interface{}{},
+			},
+		})
+	}
+}
+
 func TestCollName(t *testing.T) {
 	command := "find"
 	ex1, _ := bson.Marshal(bson.D{{Key: command, Value: "numbers"}, {Key: "$db", Value: "testing"}})
@@ -234,6 +327,156 @@ func TestCollName(t *testing.T) {
 
 }
 
+func TestMonitorWithDatabaseName(t *testing.T) {
+	app := createTestApp()
+	txn := app.StartTransaction("txnName")
+	ctx := newrelic.NewContext(context.Background(), txn)
+	nrMonitor := NewCommandMonitor(nil, WithDatabaseName())
+
+	otherRaw, _ := bson.Marshal(bson.D{{Key: "commName", Value: "collName"}, {Key: "$db", Value: "otherdb"}})
+	otherSte := &event.CommandStartedEvent{
+		Command:      otherRaw,
+		DatabaseName: "otherdb",
+		CommandName:  "commName",
+		RequestID:    reqID + 1,
+		ConnectionID: connID,
+	}
+	otherSe := &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName:  "name",
+			RequestID:    reqID + 1,
+			ConnectionID: connID,
+		},
+	}
+
+	nrMonitor.Started(ctx, ste)
+	nrMonitor.Succeeded(ctx, se)
+	nrMonitor.Started(ctx, otherSte)
+	nrMonitor.Succeeded(ctx, otherSe)
+	txn.End()
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Datastore/statement/MongoDB/testdb.collName/commName", Scope: "", Forced: false, Data: []float64{1.0}},
+		{Name: "Datastore/statement/MongoDB/otherdb.collName/commName", Scope: "", Forced: false, Data: []float64{1.0}},
+	})
+}
+
+func TestCollectionFormatter(t *testing.T) {
+	m := mongoMonitor{}
+	WithCollectionFormatter(func(database, collection string) string {
+		return collection + "@" + database
+	})(&m)
+	if coll := m.collection(ste); coll != "collName@testdb" {
+		t.Errorf("Wrong collection name: %s", coll)
+	}
+
+	noColl, _ := bson.Marshal(bson.D{{Key: "filter", Value: ""}})
+	e := &event.CommandStartedEvent{
+		Command:      noColl,
+		DatabaseName: "testdb",
+		CommandName:  "find",
+	}
+	if coll := m.collection(e); coll != "" {
+		t.Errorf("Wrong collection name: %s", coll)
+	}
+}
+
+func txnCommand(t *testing.T, command bson.E, extra ...bson.E) bson.Raw {
+	doc := bson.D{command}
+	doc = append(doc, extra...)
+	doc = append(doc,
+		bson.E{Key: "lsid", Value: bson.D{{Key: "id", Value: primitive.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}}}},
+		bson.E{Key: "txnNumber", Value: int64(1)},
+		bson.E{Key: "autocommit", Value: false},
+	)
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestMonitorGroupsTransactionCommands(t *testing.T) {
+	app := createTestApp()
+	txn := app.StartTransaction("txnName")
+	ctx := newrelic.NewContext(context.Background(), txn)
+	nrMonitor := NewCommandMonitor(nil)
+
+	commands := []*event.CommandStartedEvent{
+		{
+			Command:      txnCommand(t, bson.E{Key: "insert", Value: "numbers"}, bson.E{Key: "startTransaction", Value: true}),
+			DatabaseName: "testdb",
+			CommandName:  "insert",
+		},
+		{
+			Command:      txnCommand(t, bson.E{Key: "find", Value: "numbers"}),
+			DatabaseName: "testdb",
+			CommandName:  "find",
+		},
+		{
+			Command:      txnCommand(t, bson.E{Key: "commitTransaction", Value: 1}),
+			DatabaseName: "admin",
+			CommandName:  "commitTransaction",
+		},
+	}
+	for i, e := range commands {
+		e.RequestID = reqID + int64(i)
+		e.ConnectionID = connID
+		nrMonitor.Started(ctx, e)
+		nrMonitor.Succeeded(ctx, &event.CommandSucceededEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{
+				CommandName:  e.CommandName,
+				RequestID:    e.RequestID,
+				ConnectionID: connID,
+			},
+		})
+	}
+	txn.End()
+
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName: "OtherTransaction/Go/txnName",
+		Root: internal.WantTraceSegment{
+			SegmentName: "ROOT",
+			Attributes:  map[string]interface{}{},
+			Children: []internal.WantTraceSegment{{
+				SegmentName: "OtherTransaction/Go/txnName",
+				Attributes:  map[string]interface{}{"exclusive_duration_millis": internal.MatchAnything},
+				Children: []internal.WantTraceSegment{{
+					SegmentName: "Custom/mongo transaction",
+					Children: []internal.WantTraceSegment{
+						{SegmentName: "Datastore/statement/MongoDB/numbers/insert"},
+						{SegmentName: "Datastore/statement/MongoDB/numbers/find"},
+						{SegmentName: "Datastore/operation/MongoDB/commitTransaction"},
+					},
+				}},
+			}},
+		},
+	}})
+
+}
+
+func TestSessionTxn(t *testing.T) {
+	if _, _, ok := sessionTxn(ste); ok {
+		t.Error("command without a session found in a transaction")
+	}
+	// Retryable writes have a transaction number but no autocommit field.
+	retryable, _ := bson.Marshal(bson.D{
+		{Key: "insert", Value: "numbers"},
+		{Key: "lsid", Value: bson.D{{Key: "id", Value: primitive.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}}}},
+		{Key: "txnNumber", Value: int64(3)},
+	})
+	if _, _, ok := sessionTxn(&event.CommandStartedEvent{Command: retryable, CommandName: "insert"}); ok {
+		t.Error("retryable write found in a transaction")
+	}
+	session, txnNumber, ok := sessionTxn(&event.CommandStartedEvent{
+		Command:     txnCommand(t, bson.E{Key: "find", Value: "numbers"}),
+		CommandName: "find",
+	})
+	if !ok || session != "0123456789abcdef" || txnNumber != 1 {
+		t.Errorf("wrong session transaction: %q %d %t", session, txnNumber, ok)
+	}
+}
+
 func createTestApp() integrationsupport.ExpectApp {
 	return integrationsupport.NewTestApp(replyFn, integrationsupport.ConfigFullTraces)
 }
@@ -43,6 +43,11 @@
 //	ctx := newrelic.NewContext(context.Background(), txn)
 //	row := db.QueryRowContext(ctx, "SELECT count(*) from tables")
 //
+// Queries returning multiple result sets, such as stored procedure calls,
+// record an additional segment each time rows.NextResultSet advances to the
+// next result set.  These segments have a "db.resultSet" attribute holding the
+// 1-based index of the result set.
+//
 // A working example is shown here:
 // https://github.com/newrelic/go-agent/tree/master/v3/integrations/nrmysql/example/main.go
 package nrmysql
@@ -50,6 +55,7 @@ package nrmysql
 import (
 	"database/sql"
 	"net"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/newrelic/go-agent/v3/internal"
@@ -75,11 +81,61 @@ func init() {
 func parseDSN(s *newrelic.DatastoreSegment, dsn string) {
 	cfg, err := mysql.ParseDSN(dsn)
 	if nil != err {
-		return
+		// mysql.ParseDSN rejects DSNs whose parameters it cannot resolve,
+		// such as a tls config that has not been registered yet.  The
+		// address and database name are still usable in that case.
+		cfg = parseDSNAddress(dsn)
+		if nil == cfg {
+			return
+		}
 	}
 	parseConfig(s, cfg)
 }
 
+// parseDSNAddress extracts the network, address, and database name from a
+// DSN of the form [user[:password]@][net[(addr)]]/dbname[?params], applying
+// the same defaults as mysql.ParseDSN.  It returns nil if the DSN does not
+// have that form.
+func parseDSNAddress(dsn string) *mysql.Config {
+	if idx := strings.LastIndex(dsn, "?"); idx >= 0 {
+		dsn = dsn[:idx]
+	}
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return nil
+	}
+
+	cfg := &mysql.Config{DBName: dsn[slash+1:]}
+	addr := dsn[:slash]
+	if idx := strings.LastIndex(addr, "@"); idx >= 0 {
+		addr = addr[idx+1:]
+	}
+	if idx := strings.Index(addr, "("); idx >= 0 {
+		if !strings.HasSuffix(addr, ")") {
+			return nil
+		}
+		cfg.Net = addr[:idx]
+		cfg.Addr = addr[idx+1 : len(addr)-1]
+	} else {
+		cfg.Net = addr
+	}
+
+	if cfg.Net == "" {
+		cfg.Net = "tcp"
+	}
+	switch {
+	case cfg.Addr == "" && cfg.Net == "tcp":
+		cfg.Addr = "127.0.0.1:3306"
+	case cfg.Addr == "" && cfg.Net == "unix":
+		cfg.Addr = "/tmp/mysql.sock"
+	case cfg.Net == "tcp":
+		if _, _, err := net.SplitHostPort(cfg.Addr); nil != err {
+			cfg.Addr = net.JoinHostPort(cfg.Addr, "3306")
+		}
+	}
+	return cfg
+}
+
 func parseConfig(s *newrelic.DatastoreSegment, cfg *mysql.Config) {
 	s.DatabaseName = cfg.DBName
 
@@ -84,6 +84,54 @@ func TestParseDSN(t *testing.T) {
 			expPortPathOrID: "3306",
 			expDatabaseName: "",
 		},
+		{
+			dsn:             "user:password@tcp(db.example.com:3307)/dbname?tls=unregistered&parseTime=true",
+			expHost:         "db.example.com",
+			expPortPathOrID: "3307",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@tcp(db.example.com)/dbname?tls=unregistered",
+			expHost:         "db.example.com",
+			expPortPathOrID: "3306",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@/dbname?tls=unregistered",
+			expHost:         "127.0.0.1",
+			expPortPathOrID: "3306",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@unix(/var/run/mysqld/mysqld.sock)/dbname?tls=unregistered",
+			expHost:         "localhost",
+			expPortPathOrID: "/var/run/mysqld/mysqld.sock",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user@unix/dbname",
+			expHost:         "localhost",
+			expPortPathOrID: "/tmp/mysql.sock",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@unix(/var/run/mysqld/mysqld.sock)/dbname?charset=utf8mb4&parseTime=true&loc=Local",
+			expHost:         "localhost",
+			expPortPathOrID: "/var/run/mysqld/mysqld.sock",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@tcp(localhost:3306)/dbname?tls=true",
+			expHost:         "localhost",
+			expPortPathOrID: "3306",
+			expDatabaseName: "dbname",
+		},
+		{
+			dsn:             "user:password@mynet(db.internal:4000)/dbname?timeout=5s",
+			expHost:         "db.internal",
+			expPortPathOrID: "4000",
+			expDatabaseName: "dbname",
+		},
 		{
 			dsn:             "this is not a dsn",
 			expHost:         "",
@@ -4,6 +4,8 @@
 package nrnats
 
 import (
+	"context"
+	"net/http"
 	"strings"
 
 	nats "github.com/nats-io/nats.go"
@@ -37,6 +39,48 @@ func StartPublishSegment(txn *newrelic.Transaction, nc *nats.Conn, subject strin
 	}
 }
 
+// Publish publishes data to the subject using the `nats.Conn` while timing the
+// call with a segment created by StartPublishSegment.  When the server
+// supports message headers, the distributed tracing headers of the
+// transaction are added to the published message so that subscribers using
+// WrapHandler continue the trace.  Servers that do not support headers
+// receive the data without any headers.  If the transaction is nil, the data
+// is published without instrumentation.
+func Publish(txn *newrelic.Transaction, nc *nats.Conn, subject string, data []byte) error {
+	if nil == nc {
+		return nats.ErrInvalidConnection
+	}
+	if nil == txn {
+		return nc.Publish(subject, data)
+	}
+	seg := StartPublishSegment(txn, nc, subject)
+	defer seg.End()
+
+	if !nc.HeadersSupported() {
+		return nc.Publish(subject, data)
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	InsertDistributedTraceHeaders(txn, msg)
+	return nc.PublishMsg(msg)
+}
+
+// InsertDistributedTraceHeaders adds the distributed tracing headers of the
+// transaction to the headers of the message.
+func InsertDistributedTraceHeaders(txn *newrelic.Transaction, msg *nats.Msg) {
+	if nil == txn || nil == msg {
+		return
+	}
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if nil == msg.Header {
+		msg.Header = nats.Header{}
+	}
+	for key := range hdrs {
+		msg.Header.Set(key, hdrs.Get(key))
+	}
+}
+
 // SubWrapper can be used to wrap the function for nats.Subscribe (https://godoc.org/github.com/nats-io/go-nats#Conn.Subscribe
 // or https://godoc.org/github.com/nats-io/go-nats#EncodedConn.Subscribe)
 // and nats.QueueSubscribe (https://godoc.org/github.com/nats-io/go-nats#Conn.QueueSubscribe or
@@ -64,3 +108,59 @@ func SubWrapper(app *newrelic.Application, f func(msg *nats.Msg)) func(msg *nats
 		f(msg)
 	}
 }
+
+// WrapHandler wraps a handler for nats.Subscribe or nats.QueueSubscribe.  If
+// the `newrelic.Application` parameter is non-nil, a `newrelic.Transaction` is
+// started for each message received, added to the context passed to the
+// handler, and ended when the handler returns.  The transaction is named after
+// the subject parameter, which should be the subject used to subscribe so
+// that wildcard subscriptions produce a single transaction name.  If the
+// subject parameter is empty, the subject of the message is used instead.
+// Distributed tracing headers found in the headers of the message are
+// accepted, and the message subject and reply subject are recorded as
+// attributes.
+//
+//	nc.Subscribe("orders.*", nrnats.WrapHandler(app, "orders.*", func(ctx context.Context, msg *nats.Msg) {
+//		// ...
+//	}))
+func WrapHandler(app *newrelic.Application, subject string, handler func(ctx context.Context, msg *nats.Msg)) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		ctx := context.Background()
+		if nil != app {
+			txn := startSubscriberTransaction(app, subject, msg)
+			defer txn.End()
+			ctx = newrelic.NewContext(ctx, txn)
+		}
+		handler(ctx, msg)
+	}
+}
+
+func startSubscriberTransaction(app *newrelic.Application, subject string, msg *nats.Msg) *newrelic.Transaction {
+	if "" == subject {
+		subject = msg.Subject
+	}
+	namer := internal.MessageMetricKey{
+		Library:         "NATS",
+		DestinationType: string(newrelic.MessageTopic),
+		DestinationName: subject,
+		Consumer:        true,
+	}
+	txn := app.StartTransaction(namer.Name())
+
+	// NATS headers are case-sensitive, so they are copied one value at a
+	// time to obtain canonical http.Header keys.
+	hdrs := http.Header{}
+	for key, values := range msg.Header {
+		for _, value := range values {
+			hdrs.Add(key, value)
+		}
+	}
+	txn.AcceptDistributedTraceHeaders(newrelic.TransportQueue, hdrs)
+
+	integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageRoutingKey, msg.Subject, nil)
+	integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageReplyTo, msg.Reply, nil)
+	if nil != msg.Sub {
+		integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageQueueName, msg.Sub.Queue, nil)
+	}
+	return txn
+}
@@ -5,7 +5,7 @@
 //
 // This package can be used to simplify instrumenting NATS publishers and subscribers. Currently due to the nature of
 // the NATS framework we are limited to two integration points: `StartPublishSegment` for publishers, and `SubWrapper`
-// for subscribers.
+// or `WrapHandler` for subscribers.
 //
 // NATS publishers
 //
@@ -31,6 +31,13 @@
 //	nc.Publish(subject, []byte("Hello World"))
 //
 //
+// To also propagate distributed tracing headers to subscribers, use `Publish`,
+// which creates the segment, adds the headers to the message, and publishes
+// it.  Servers that do not support message headers receive the data without
+// headers:
+//
+//	err := nrnats.Publish(txn, nc, subject, []byte("Hello World"))
+//
 // StartPublishSegment can be used with a NATS Streamming Connection as well
 // (https://github.com/nats-io/stan.go).  Use the `NatsConn()` method on the
 // `stan.Conn` interface (https://godoc.org/github.com/nats-io/stan#Conn) to
@@ -57,6 +64,15 @@
 //	subject := "testing.subject"
 //	nc.Subscribe(subject, nrnats.SubWrapper(app, myMessageHandler))
 //
+// To access the transaction from the handler, and to continue distributed
+// traces from the headers of received messages, use `nrnats.WrapHandler`
+// instead.  The handler receives a context containing the transaction:
+//
+//	nc.Subscribe("orders.*", nrnats.WrapHandler(app, "orders.*", func(ctx context.Context, msg *nats.Msg) {
+//		txn := newrelic.FromContext(ctx)
+//		// ...
+//	}))
+//
 // Full Publisher/Subscriber example:
 // https://github.com/newrelic/go-agent/blob/master/v3/integrations/nrnats/examples/main.go
 package nrnats
@@ -4,7 +4,10 @@
 package nrnats
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -224,3 +227,159 @@ func WgWrapper(wg *sync.WaitGroup, nrWrap func(msg *nats.Msg)) func(msg *nats.Ms
 		wg.Done()
 	}
 }
+
+func TestWrapHandler(t *testing.T) {
+	dtReplyFn := func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+		reply.AccountID = "123"
+		reply.TrustedAccountKey = "123"
+		reply.PrimaryAppID = "456"
+	}
+	producerApp := integrationsupport.NewTestApp(dtReplyFn, cfgFn)
+	producerTxn := producerApp.StartTransaction("producer")
+	hdrs := http.Header{}
+	producerTxn.InsertDistributedTraceHeaders(hdrs)
+	producerTxn.End()
+
+	msg := &nats.Msg{
+		Subject: "orders.created",
+		Reply:   "_INBOX.reply",
+		Header:  nats.Header{},
+	}
+	for key := range hdrs {
+		// NATS headers are case-sensitive, use lowercase keys to ensure
+		// they are found regardless of case.
+		msg.Header.Set(strings.ToLower(key), hdrs.Get(key))
+	}
+
+	app := integrationsupport.NewTestApp(dtReplyFn, cfgFn)
+	var called bool
+	handler := nrnats.WrapHandler(app.Application, "orders.*", func(ctx context.Context, m *nats.Msg) {
+		called = true
+		if nil == newrelic.FromContext(ctx) {
+			t.Error("expected transaction in context")
+		}
+	})
+	handler(msg)
+
+	if !called {
+		t.Fatal("handler was not called")
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/Message/NATS/Topic/Named/orders.*", Scope: "", Forced: true, Data: nil},
+		{Name: "Supportability/TraceContext/Accept/Success", Scope: "", Forced: true, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":                     "OtherTransaction/Go/Message/NATS/Topic/Named/orders.*",
+				"guid":                     internal.MatchAnything,
+				"priority":                 internal.MatchAnything,
+				"sampled":                  internal.MatchAnything,
+				"traceId":                  internal.MatchAnything,
+				"parentId":                 internal.MatchAnything,
+				"parentSpanId":             internal.MatchAnything,
+				"parent.type":              "App",
+				"parent.account":           "123",
+				"parent.app":               "456",
+				"parent.transportType":     "Queue",
+				"parent.transportDuration": internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "orders.created",
+				"message.replyTo":    "_INBOX.reply",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestWrapHandlerNilApp(t *testing.T) {
+	var called bool
+	handler := nrnats.WrapHandler(nil, "", func(ctx context.Context, m *nats.Msg) {
+		called = true
+		if nil != newrelic.FromContext(ctx) {
+			t.Error("expected no transaction in context")
+		}
+	})
+	handler(&nats.Msg{Subject: "orders.created"})
+	if !called {
+		t.Fatal("handler was not called")
+	}
+}
+
+func TestInsertDistributedTraceHeaders(t *testing.T) {
+	app := integrationsupport.NewTestApp(func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+		reply.AccountID = "123"
+		reply.TrustedAccountKey = "123"
+		reply.PrimaryAppID = "456"
+	}, cfgFn)
+	txn := app.StartTransaction("producer")
+	msg := nats.NewMsg("orders.created")
+	nrnats.InsertDistributedTraceHeaders(txn, msg)
+	txn.End()
+
+	if msg.Header.Get(newrelic.DistributedTraceW3CTraceParentHeader) == "" {
+		t.Error("traceparent header was not inserted")
+	}
+	if msg.Header.Get(newrelic.DistributedTraceNewRelicHeader) == "" {
+		t.Error("newrelic header was not inserted")
+	}
+}
+
+func TestPublish(t *testing.T) {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	received := make(chan *nats.Msg, 1)
+	sub, err := nc.ChanSubscribe("publish.subject", received)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	app := testApp()
+	txn := app.StartTransaction("testing")
+	if err := nrnats.Publish(txn, nc, "publish.subject", []byte("data")); nil != err {
+		t.Fatal(err)
+	}
+	txn.End()
+
+	select {
+	case msg := <-received:
+		if string(msg.Data) != "data" {
+			t.Errorf("unexpected data: %q", msg.Data)
+		}
+		// Servers without header support must receive the message
+		// without headers.
+		if !nc.HeadersSupported() && len(msg.Header) != 0 {
+			t.Errorf("unexpected headers: %v", msg.Header)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "MessageBroker/NATS/Topic/Produce/Named/publish.subject", Scope: "", Forced: false, Data: nil},
+		{Name: "MessageBroker/NATS/Topic/Produce/Named/publish.subject", Scope: "OtherTransaction/Go/testing", Forced: false, Data: nil},
+	})
+}
+
+func TestPublishNilTxn(t *testing.T) {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	if err := nrnats.Publish(nil, nc, "publish.subject", []byte("data")); nil != err {
+		t.Error(err)
+	}
+	if err := nrnats.Publish(nil, nil, "publish.subject", []byte("data")); nats.ErrInvalidConnection != err {
+		t.Error("expected invalid connection error, got", err)
+	}
+}
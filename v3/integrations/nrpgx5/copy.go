@@ -0,0 +1,86 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpgx5
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// WithCopyProgressInterval sets the interval at which the sources returned by
+// Tracer.CopyFromSource record the number of rows copied so far.  It is
+// disabled by default, the sources being returned unwrapped.
+func WithCopyProgressInterval(interval time.Duration) TracerOption {
+	return func(t *Tracer) { t.copyProgressInterval = interval }
+}
+
+// copyProgressMetric returns the name of the custom metric recording the
+// progress of the copies to the table.  The name is prefixed with "Custom/"
+// by RecordCustomMetric.
+func copyProgressMetric(tableName pgx.Identifier) string {
+	return "Postgres/CopyFrom/" + strings.Join(tableName, ".") + "/RowsCopied"
+}
+
+// CopyFromSource wraps src to record the progress of a long CopyFrom to
+// tableName when WithCopyProgressInterval is set: every interval, and once
+// the source is exhausted, the number of rows read from the source so far is
+// recorded in the "Custom/Postgres/CopyFrom/<table>/RowsCopied" custom
+// metric of the application of the transaction in ctx.  The maximum of the
+// metric is thus the progress of the copy.  src is returned as is when the
+// interval is not set or ctx has no transaction.
+//
+//	src := tracer.CopyFromSource(ctx, pgx.Identifier{"mytable"}, pgx.CopyFromRows(rows))
+//	n, err := conn.CopyFrom(ctx, pgx.Identifier{"mytable"}, columns, src)
+func (t *Tracer) CopyFromSource(ctx context.Context, tableName pgx.Identifier, src pgx.CopyFromSource) pgx.CopyFromSource {
+	if t.copyProgressInterval <= 0 {
+		return src
+	}
+	app := newrelic.FromContext(ctx).Application()
+	if app == nil {
+		return src
+	}
+	return &copyProgressSource{
+		CopyFromSource: src,
+		app:            app,
+		metric:         copyProgressMetric(tableName),
+		interval:       t.copyProgressInterval,
+		lastReport:     time.Now(),
+	}
+}
+
+// copyProgressSource counts the rows read from the CopyFromSource it wraps
+// and records the count periodically.
+type copyProgressSource struct {
+	pgx.CopyFromSource
+	app        *newrelic.Application
+	metric     string
+	interval   time.Duration
+	rows       int64
+	lastReport time.Time
+	done       bool
+}
+
+func (s *copyProgressSource) Next() bool {
+	if !s.CopyFromSource.Next() {
+		if !s.done {
+			s.done = true
+			s.report(time.Now())
+		}
+		return false
+	}
+	s.rows++
+	if now := time.Now(); now.Sub(s.lastReport) >= s.interval {
+		s.report(now)
+	}
+	return true
+}
+
+func (s *copyProgressSource) report(now time.Time) {
+	s.lastReport = now
+	s.app.RecordCustomMetric(s.metric, float64(s.rows))
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpgx5
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/facily-tech/go-agent/v3/internal"
+	"github.com/facily-tech/go-agent/v3/internal/integrationsupport"
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowSource is a CopyFromSource taking delay to produce each row.
+type slowSource struct {
+	rows  int
+	delay time.Duration
+	next  int
+}
+
+func (s *slowSource) Next() bool {
+	if s.next >= s.rows {
+		return false
+	}
+	time.Sleep(s.delay)
+	s.next++
+	return true
+}
+
+func (s *slowSource) Values() ([]any, error) { return []any{s.next}, nil }
+func (s *slowSource) Err() error             { return nil }
+
+func TestTracer_copyProgress(t *testing.T) {
+	tracer := NewTracer(WithCopyProgressInterval(5 * time.Millisecond))
+	app := integrationsupport.NewBasicTestApp()
+	txn := app.StartTransaction("load")
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	src := tracer.CopyFromSource(ctx, pgx.Identifier{"public", "mytable"}, &slowSource{rows: 10, delay: 2 * time.Millisecond})
+	var rows int
+	for src.Next() {
+		values, err := src.Values()
+		assert.NoError(t, err)
+		assert.Equal(t, []any{rows + 1}, values)
+		rows++
+	}
+	assert.NoError(t, src.Err())
+	assert.False(t, src.Next())
+	txn.End()
+
+	assert.Equal(t, 10, rows)
+	// The metric is recorded at least once during the copy and once when
+	// it is done, the maximum being the number of rows copied.
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Custom/Postgres/CopyFrom/public.mytable/RowsCopied", Data: nil},
+	})
+	progress := src.(*copyProgressSource)
+	assert.True(t, progress.done)
+	assert.Equal(t, int64(10), progress.rows)
+}
+
+func TestTracer_copyProgressDisabled(t *testing.T) {
+	src := &slowSource{rows: 1}
+	app := integrationsupport.NewBasicTestApp()
+	txn := app.StartTransaction("load")
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	assert.Same(t, src, NewTracer().CopyFromSource(ctx, pgx.Identifier{"mytable"}, src))
+
+	tracer := NewTracer(WithCopyProgressInterval(time.Millisecond))
+	assert.Same(t, src, tracer.CopyFromSource(context.Background(), pgx.Identifier{"mytable"}, src))
+	txn.End()
+}
@@ -18,8 +18,7 @@ func main() {
 		panic(err)
 	}
 
-	cfg.ConnConfig.Tracer = nrpgx5.NewTracer()
-	db, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	db, err := pgxpool.NewWithConfig(context.Background(), nrpgx5.InstrumentConfig(cfg))
 	if err != nil {
 		panic(err)
 	}
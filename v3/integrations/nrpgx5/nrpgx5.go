@@ -28,7 +28,8 @@
 // 	}
 // ...
 // ```
-// or you can set the tracer in the pgxpool.Config like this
+// or you can set the tracer in the pgxpool.Config with InstrumentConfig, which
+// keeps any tracer already set on the config, like this
 // ```go
 // import (
 // 	"github.com/jackc/pgx/v5/pgxpool"
@@ -42,23 +43,80 @@
 // 		panic(err)
 // 	}
 //
-// 	cfg.ConnConfig.Tracer = nrpgx5.NewTracer()
-// 	db, err := pgxpool.NewWithConfig(context.Background(), cfg)
+// 	db, err := pgxpool.NewWithConfig(context.Background(), nrpgx5.InstrumentConfig(cfg))
 // 	if err != nil {
 // 		panic(err)
 // 	}
 // ...
 // ```
+//
+// The plan of the queries slower than the slow query threshold of the agent
+// can be recorded in the "db.explain" attribute of their datastore segment,
+// by running `EXPLAIN` on the pool with ExplainSlowQueries.  This is disabled
+// by default, enable it with newrelic.ConfigDatastoreSlowSample:
+// ```go
+// app, err := newrelic.NewApplication(
+// 	newrelic.ConfigDatastoreSlowSample(nrpgx5.ExplainSlowQueries(db, time.Second)),
+// 	...
+// )
+// ```
+//
+// Use WithApplicationLogging to forward the notices and warnings sent by
+// PostgreSQL, such as those raised with `RAISE NOTICE`, to New Relic Logs in
+// the context of the transaction running the query:
+// ```go
+// cfg.Tracer = nrpgx5.NewTracer(nrpgx5.WithApplicationLogging(true))
+// ```
+//
+// The protocol used to run a query, simple or extended, is recorded in the
+// "db.query_mode" attribute of the datastore segment, following the
+// QueryExecMode of the connection config or of the query:
+// ```go
+// rows, err := conn.Query(ctx, "SELECT name FROM users WHERE id = $1", pgx.QueryExecModeSimpleProtocol, id)
+// ```
+//
+// Queries run in the default pgx.QueryExecModeCacheStatement mode record
+// whether their prepared statement was served from the pgx statement cache in
+// the "db.statement.cached" attribute of the datastore segment, which helps
+// tune the StatementCacheCapacity of the connection config.
+//
+// The datastore segments of Query calls cover the iteration of the rows, and
+// not only the execution of the query: pgx ends the trace of the query once
+// the rows are exhausted or closed.  No option is needed to measure the cost
+// of streaming a large result set, but the rows should be closed as soon as
+// they are processed.
+//
+// When a service has several pools, name them with WithConnectionPoolName to
+// record the name in the "db.pool" attribute of the datastore segments:
+// ```go
+// db, err := pgxpool.NewWithConfig(ctx, nrpgx5.InstrumentConfig(cfg, nrpgx5.WithConnectionPoolName("read")))
+// ```
+//
+// To trace some queries explicitly rather than instrumenting the connection,
+// run them with Query and QueryRow, which time them with a datastore segment
+// of the transaction in the context:
+// ```go
+// rows, err := nrpgx5.Query(ctx, pool, "SELECT id, name FROM users WHERE team = $1", team)
+// ```
+//
+// To keep a tracer you already use, for instance for logging, wrap it so
+// that both are called:
+// ```go
+// cfg.Tracer = nrpgx5.NewTracer(nrpgx5.WithWrappedTracer(cfg.Tracer))
+// ```
 
 package nrpgx5
 
 import (
 	"context"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/facily-tech/go-agent/v3/internal"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/newrelic/go-agent/v3/newrelic/sqlparse"
 )
@@ -72,67 +130,504 @@ type (
 		mu          sync.Mutex
 		BaseSegment newrelic.DatastoreSegment
 		ParseQuery  func(segment *newrelic.DatastoreSegment, query string)
+
+		// wrapped is the tracer called after this one, set with
+		// WithWrappedTracer.
+		wrapped pgx.QueryTracer
+
+		// execMode is the default query exec mode of the connection
+		// config, set when connecting.
+		execMode pgx.QueryExecMode
+
+		logNotices bool
+		// noticeTxns holds the transaction of the query running on each
+		// connection, to which the notices received are recorded.
+		noticeTxns map[*pgconn.PgConn]*newrelic.Transaction
+
+		expectedErrors bool
+
+		// copyProgressInterval is the interval at which the sources
+		// wrapped by CopyFromSource record their progress.
+		copyProgressInterval time.Duration
+
+		// preparedStatements holds the SQL of the statements prepared
+		// with Conn.Prepare on each connection, keyed by statement
+		// name: the names of prepared statements are scoped to their
+		// connection.
+		preparedStatements map[*pgx.Conn]map[string]string
+
+		// poolName is recorded in the PoolAttribute attribute of the
+		// segments, set with WithConnectionPoolName.
+		poolName string
 	}
 
+	// TracerOption configures a Tracer created by NewTracer.
+	TracerOption func(*Tracer)
+
 	nrPgxSegmentType string
+
+	// statementCacheUse records whether a query run in the
+	// QueryExecModeCacheStatement mode had to prepare its statement.
+	statementCacheUse struct {
+		prepared bool
+	}
 )
 
 const (
 	querySegmentKey   nrPgxSegmentType = "nrPgx5Segment"
 	prepareSegmentKey nrPgxSegmentType = "prepareNrPgx5Segment"
 	batchSegmentKey   nrPgxSegmentType = "batchNrPgx5Segment"
+	statementCacheKey nrPgxSegmentType = "statementCacheNrPgx5"
+)
+
+// ExplainAttribute is the datastore segment attribute holding the query plan
+// of slow queries when ExplainSlowQueries is used.
+const ExplainAttribute = "db.explain"
+
+// StatementCachedAttribute is the datastore segment attribute recording
+// whether the prepared statement of a query was served from the pgx statement
+// cache.  It is only recorded for queries with arguments run in the
+// pgx.QueryExecModeCacheStatement mode, the default: queries run with the
+// simple protocol or the other exec modes do not use the statement cache.
+const StatementCachedAttribute = "db.statement.cached"
+
+// QueryModeAttribute is the datastore segment attribute recording the
+// protocol used to run a query: QueryModeSimple for the simple protocol,
+// QueryModeCacheDescribe for the extended protocol with the
+// pgx.QueryExecModeCacheDescribe mode, and QueryModeExtended for the extended
+// protocol with the other exec modes.  It is derived from the exec mode of
+// the connection config and of the query options, and is not recorded for
+// queries without arguments run in an extended mode, which pgx runs with the
+// simple protocol when using Exec only.
+const QueryModeAttribute = "db.query_mode"
+
+// The values of the QueryModeAttribute attribute.
+const (
+	QueryModeSimple        = "simple"
+	QueryModeExtended      = "extended"
+	QueryModeCacheDescribe = "cache_describe"
 )
 
-func NewTracer() *Tracer {
-	return &Tracer{
+// PoolAttribute is the datastore segment attribute holding the name of the
+// connection pool set with WithConnectionPoolName.
+const PoolAttribute = "db.pool"
+
+// NewTracer creates a Tracer configured with the given options.
+func NewTracer(opts ...TracerOption) *Tracer {
+	t := &Tracer{
 		ParseQuery: sqlparse.ParseQuery,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithApplicationLogging enables forwarding the notices received from
+// PostgreSQL while running a query to New Relic Logs, with the trace context
+// of the transaction running the query.  Notices received outside of a
+// traced query are not forwarded.  A notice handler already set in the
+// OnNotice field of the connection config is still called.  Log forwarding
+// must also be enabled in the application config.  It is disabled by
+// default.
+func WithApplicationLogging(enabled bool) TracerOption {
+	return func(t *Tracer) { t.logNotices = enabled }
+}
+
+// WithExpectedErrors enables recording the errors of queries and batches on
+// the transaction as expected errors, using NoticeExpectedError: they are
+// visible in the errors of the transaction but do not count towards the
+// error rate of the application.  This suits connections to read replicas,
+// where transient errors are expected and the query is retried against the
+// primary.  Errors are not recorded by default.
+func WithExpectedErrors(enabled bool) TracerOption {
+	return func(t *Tracer) { t.expectedErrors = enabled }
+}
+
+// WithConnectionPoolName sets the name recorded in the PoolAttribute
+// attribute of the query and batch segments, to tell apart the queries of
+// the different pools of a service, for instance its read, write and
+// analytics pools.  Use a tracer per pool:
+//
+//	readPool, err := pgxpool.NewWithConfig(ctx, nrpgx5.InstrumentConfig(readCfg, nrpgx5.WithConnectionPoolName("read")))
+//	writePool, err := pgxpool.NewWithConfig(ctx, nrpgx5.InstrumentConfig(writeCfg, nrpgx5.WithConnectionPoolName("write")))
+//
+// The attribute is not recorded by default.
+func WithConnectionPoolName(name string) TracerOption {
+	return func(t *Tracer) { t.poolName = name }
+}
+
+// WithWrappedTracer sets a tracer which is called after the New Relic
+// instrumentation by each Trace method, for instance to keep logging done by
+// a tracer previously set on the connection config:
+//
+//	cfg.Tracer = nrpgx5.NewTracer(nrpgx5.WithWrappedTracer(cfg.Tracer))
+//
+// The methods of the pgx.ConnectTracer, pgx.BatchTracer, pgx.PrepareTracer and
+// pgx.CopyFromTracer interfaces are called when the wrapped tracer implements
+// them.
+func WithWrappedTracer(tracer pgx.QueryTracer) TracerOption {
+	return func(t *Tracer) { t.wrapped = tracer }
 }
 
 // TraceConnectStart is called at the beginning of Connect and ConnectConfig calls. The returned context is used for
 // the rest of the call and will be passed to TraceConnectEnd. // implement pgx.ConnectTracer
 func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	t.BaseSegment = newrelic.DatastoreSegment{
 		Product:      newrelic.DatastorePostgres,
 		Host:         data.ConnConfig.Host,
 		PortPathOrID: strconv.FormatUint(uint64(data.ConnConfig.Port), 10),
 		DatabaseName: data.ConnConfig.Database,
 	}
+	t.execMode = data.ConnConfig.DefaultQueryExecMode
 
+	if t.logNotices {
+		// pgx copies the config of each connection, so the handler is
+		// only wrapped once per connection.
+		data.ConnConfig.OnNotice = t.noticeHandler(data.ConnConfig.OnNotice)
+	}
+	t.mu.Unlock()
+
+	if next, ok := t.wrapped.(pgx.ConnectTracer); ok {
+		ctx = next.TraceConnectStart(ctx, data)
+	}
 	return ctx
 }
 
+// noticeHandler returns a notice handler recording the notices to the
+// transaction running a query on the connection, and then calling next if it
+// is not nil.
+func (t *Tracer) noticeHandler(next pgconn.NoticeHandler) pgconn.NoticeHandler {
+	return func(conn *pgconn.PgConn, notice *pgconn.Notice) {
+		t.mu.Lock()
+		txn := t.noticeTxns[conn]
+		t.mu.Unlock()
+		if txn != nil {
+			txn.RecordLog(newrelic.LogData{
+				Severity: notice.Severity,
+				Message:  notice.Message,
+			})
+		}
+		if next != nil {
+			next(conn, notice)
+		}
+	}
+}
+
+// setNoticeTransaction sets the transaction to which the notices received on
+// the connection are recorded.  A nil transaction removes it.
+func (t *Tracer) setNoticeTransaction(conn *pgconn.PgConn, txn *newrelic.Transaction) {
+	if conn == nil {
+		return
+	}
+	if txn == nil {
+		delete(t.noticeTxns, conn)
+		return
+	}
+	if t.noticeTxns == nil {
+		t.noticeTxns = make(map[*pgconn.PgConn]*newrelic.Transaction)
+	}
+	t.noticeTxns[conn] = txn
+}
+
 // TraceConnectEnd method // implement pgx.ConnectTracer
-func (*Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {}
+func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	if next, ok := t.wrapped.(pgx.ConnectTracer); ok {
+		next.TraceConnectEnd(ctx, data)
+	}
+}
 
 // TraceQueryStart is called at the beginning of Query, QueryRow, and Exec calls. The returned context is used for the
 // rest of the call and will be passed to TraceQueryEnd. //implement pgx.QueryTracer
 func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	segment := t.BaseSegment
 	segment.StartTime = newrelic.FromContext(ctx).StartSegmentNow()
-	segment.ParameterizedQuery = data.SQL
-	segment.QueryParameters = t.getQueryParameters(data.Args)
+	t.deallocate(conn, data.SQL)
+	sql, prepared := t.statementSQL(conn, data.SQL)
+	segment.ParameterizedQuery = sql
+	segment.QueryParameters = getQueryParameters(data.Args)
 
 	// fill Operation and Collection
-	t.ParseQuery(&segment, data.SQL)
+	t.ParseQuery(&segment, sql)
 
-	return context.WithValue(ctx, querySegmentKey, &segment)
+	if t.logNotices && conn != nil {
+		t.setNoticeTransaction(conn.PgConn(), newrelic.FromContext(ctx))
+	}
+
+	if !prepared && t.usesStatementCache(data) {
+		ctx = context.WithValue(ctx, statementCacheKey, &statementCacheUse{})
+	}
+	if mode := t.queryMode(data, prepared); mode != "" {
+		segment.AddAttribute(QueryModeAttribute, mode)
+	}
+
+	ctx = context.WithValue(ctx, querySegmentKey, &segment)
+	t.mu.Unlock()
+
+	if t.wrapped != nil {
+		ctx = t.wrapped.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
 }
 
 // TraceQueryEnd method implement pgx.QueryTracer. It will try to get segment from context and end it.
+// pgx calls it once the rows of a Query are exhausted or closed, so that the
+// segment covers their iteration.
 func (t *Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.wrapped != nil {
+		defer t.wrapped.TraceQueryEnd(ctx, conn, data)
+	}
+	if t.logNotices && conn != nil {
+		t.mu.Lock()
+		t.setNoticeTransaction(conn.PgConn(), nil)
+		t.mu.Unlock()
+	}
 	segment, ok := ctx.Value(querySegmentKey).(*newrelic.DatastoreSegment)
 	if !ok {
 		return
 	}
+	if use, ok := ctx.Value(statementCacheKey).(*statementCacheUse); ok && data.Err == nil {
+		segment.AddAttribute(StatementCachedAttribute, !use.prepared)
+	}
+	t.addPoolAttribute(segment)
 	segment.End()
+	t.noticeExpectedError(ctx, data.Err)
+}
+
+// addPoolAttribute records the name of the connection pool in the segment
+// when WithConnectionPoolName is set.
+func (t *Tracer) addPoolAttribute(segment *newrelic.DatastoreSegment) {
+	if t.poolName != "" {
+		segment.AddAttribute(PoolAttribute, t.poolName)
+	}
+}
+
+// noticeExpectedError records err as an expected error on the transaction in
+// ctx when WithExpectedErrors is enabled.
+func (t *Tracer) noticeExpectedError(ctx context.Context, err error) {
+	if !t.expectedErrors || err == nil {
+		return
+	}
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		txn.NoticeExpectedError(err)
+	}
+}
+
+// usesStatementCache reports whether the query is run in the
+// QueryExecModeCacheStatement mode.  Queries without arguments are excluded
+// since Exec runs them with the simple protocol.
+func (t *Tracer) usesStatementCache(data pgx.TraceQueryStartData) bool {
+	mode, hasArgs := t.queryExecMode(data)
+	return mode == pgx.QueryExecModeCacheStatement && hasArgs
+}
+
+// queryMode returns the value of the QueryModeAttribute attribute of the
+// query, or "" when it cannot be told: Exec runs the queries without
+// arguments with the simple protocol whatever the exec mode, while Query does
+// not.
+func (t *Tracer) queryMode(data pgx.TraceQueryStartData, prepared bool) string {
+	if prepared {
+		return QueryModeExtended
+	}
+	mode, hasArgs := t.queryExecMode(data)
+	switch {
+	case mode == pgx.QueryExecModeSimpleProtocol:
+		return QueryModeSimple
+	case !hasArgs:
+		return ""
+	case mode == pgx.QueryExecModeCacheDescribe:
+		return QueryModeCacheDescribe
+	default:
+		return QueryModeExtended
+	}
+}
+
+// queryExecMode returns the exec mode of the query, reading it from the
+// query options passed before its arguments like pgx does, and whether the
+// query has arguments.
+func (t *Tracer) queryExecMode(data pgx.TraceQueryStartData) (pgx.QueryExecMode, bool) {
+	if data.SQL == "" {
+		return 0, false
+	}
+	mode := t.execMode
+	if mode == 0 {
+		// The tracer was not used to connect: assume the pgx default.
+		mode = pgx.QueryExecModeCacheStatement
+	}
+	args := data.Args
+	// A rewriter, such as pgx.NamedArgs, provides the arguments itself.
+	rewritten := false
+optionLoop:
+	for len(args) > 0 {
+		switch arg := args[0].(type) {
+		case pgx.QueryExecMode:
+			mode = arg
+		case pgx.QueryRewriter:
+			rewritten = true
+		case pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+		default:
+			break optionLoop
+		}
+		args = args[1:]
+	}
+	return mode, len(args) > 0 || rewritten
+}
+
+// ExplainSlowQueries returns a function which runs `EXPLAIN` for the
+// PostgreSQL queries slower than the slow query threshold of the agent,
+// Config.DatastoreTracer.SlowQuery.Threshold, and returns their plan.  Set it
+// with newrelic.ConfigDatastoreSlowSample to record the plan in the
+// ExplainAttribute attribute of their datastore segment:
+//
+//	db, err := pgxpool.NewWithConfig(ctx, nrpgx5.InstrumentConfig(cfg))
+//	app, err := newrelic.NewApplication(
+//		newrelic.ConfigDatastoreSlowSample(nrpgx5.ExplainSlowQueries(db, time.Second)),
+//		...
+//	)
+//
+// The plan is queried with q, typically the pool running the queries, rather
+// than with a new connection.  It is queried once the duration of the query
+// has been measured: it adds to the time spent by the caller, at most
+// timeout, but not to the duration of the segment.  The query running on
+// one of its connections, q needs another one: with a pool of a single
+// connection, the plan is never recorded.  Only SELECT, INSERT, UPDATE and
+// DELETE statements are explained.
+func ExplainSlowQueries(q Querier, timeout time.Duration) func(seg *newrelic.DatastoreSegment) string {
+	return explainSlowQueries(q, timeout, explainQuery)
+}
+
+func explainSlowQueries(q Querier, timeout time.Duration,
+	explain func(ctx context.Context, q Querier, sql string, args []interface{}) (string, error),
+) func(seg *newrelic.DatastoreSegment) string {
+	return func(seg *newrelic.DatastoreSegment) string {
+		if seg.Product != newrelic.DatastorePostgres {
+			return ""
+		}
+		switch seg.Operation {
+		case "select", "insert", "update", "delete":
+		default:
+			// EXPLAIN would fail, aborting the enclosing database
+			// transaction when q is a pgx.Tx.
+			return ""
+		}
+		// The context does not hold the transaction: the EXPLAIN query
+		// itself is not traced.
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		plan, err := explain(ctx, q, seg.ParameterizedQuery, queryArguments(seg.QueryParameters))
+		if err != nil {
+			return ""
+		}
+		return plan
+	}
+}
+
+// queryArguments returns the arguments of the query from its parameters, as
+// recorded by getQueryParameters.
+func queryArguments(params map[string]interface{}) []interface{} {
+	args := make([]interface{}, len(params))
+	for i := range args {
+		args[i] = params["$"+strconv.Itoa(i)]
+	}
+	return args
+}
+
+// explainQuery returns the text plan of the query.
+func explainQuery(ctx context.Context, q Querier, sql string, args []interface{}) (string, error) {
+	rows, err := q.Query(ctx, "EXPLAIN "+sql, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// statementSQL returns the SQL of the query run on conn.  A query running a
+// statement prepared with Conn.Prepare only has the name of the statement:
+// the SQL the statement was prepared with on conn is returned, and true.
+// t.mu must be held.
+func (t *Tracer) statementSQL(conn *pgx.Conn, sql string) (string, bool) {
+	if prepared, ok := t.preparedStatements[conn][sql]; ok {
+		return prepared, true
+	}
+	return sql, false
+}
+
+// addPreparedStatement records the SQL of the statement prepared on conn.
+// The statements of the connections which have been closed are forgotten at
+// the same time.  t.mu must be held.
+func (t *Tracer) addPreparedStatement(conn *pgx.Conn, name, sql string) {
+	for c := range t.preparedStatements {
+		if c != nil && c != conn && connClosed(c) {
+			delete(t.preparedStatements, c)
+		}
+	}
+	statements := t.preparedStatements[conn]
+	if statements == nil {
+		if t.preparedStatements == nil {
+			t.preparedStatements = make(map[*pgx.Conn]map[string]string)
+		}
+		statements = make(map[string]string)
+		t.preparedStatements[conn] = statements
+	}
+	statements[name] = sql
+}
+
+// connClosed reports whether the connection is closed, or was never
+// established.
+func connClosed(conn *pgx.Conn) bool {
+	pgConn := conn.PgConn()
+	return pgConn == nil || pgConn.IsClosed()
+}
+
+// forgetConn forgets the statements prepared on the connection, once it is
+// closed.
+func (t *Tracer) forgetConn(conn *pgx.Conn) {
+	t.mu.Lock()
+	delete(t.preparedStatements, conn)
+	t.mu.Unlock()
+}
+
+// deallocate forgets the statements deallocated on conn by the query, if it
+// is a DEALLOCATE statement.  Conn.Deallocate does not run its query through
+// the tracer: the statements it deallocates are only forgotten once the
+// connection is closed, or replaced when their name is prepared again.  t.mu
+// must be held.
+func (t *Tracer) deallocate(conn *pgx.Conn, sql string) {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "deallocate") {
+		return
+	}
+	name := fields[1]
+	if strings.EqualFold(name, "prepare") && len(fields) > 2 {
+		name = fields[2]
+	}
+	switch {
+	case strings.EqualFold(name, "all"):
+		delete(t.preparedStatements, conn)
+	case len(name) > 1 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`):
+		delete(t.preparedStatements[conn], strings.ReplaceAll(name[1:len(name)-1], `""`, `"`))
+	default:
+		// Unquoted identifiers are folded to lower case by
+		// PostgreSQL.
+		delete(t.preparedStatements[conn], strings.ToLower(name))
+	}
 }
 
-func (t *Tracer) getQueryParameters(args []interface{}) map[string]interface{} {
+func getQueryParameters(args []interface{}) map[string]interface{} {
 	result := map[string]interface{}{}
 	for i, arg := range args {
 		result["$"+strconv.Itoa(i)] = arg
@@ -144,42 +639,91 @@ func (t *Tracer) getQueryParameters(args []interface{}) map[string]interface{} {
 // rest of the call and will be passed to TraceBatchQuery and TraceBatchEnd. // implement pgx.BatchTracer
 func (t *Tracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	segment := t.BaseSegment
+	t.mu.Unlock()
 	segment.StartTime = newrelic.FromContext(ctx).StartSegmentNow()
 	segment.Operation = "batch"
 	segment.Collection = ""
 
-	return context.WithValue(ctx, batchSegmentKey, &segment)
+	ctx = context.WithValue(ctx, batchSegmentKey, &segment)
+	if next, ok := t.wrapped.(pgx.BatchTracer); ok {
+		ctx = next.TraceBatchStart(ctx, conn, data)
+	}
+	return ctx
 }
 
 // TraceBatchQuery implement pgx.BatchTracer. In this method we will get query and store it in segment.
 func (t *Tracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if next, ok := t.wrapped.(pgx.BatchTracer); ok {
+		defer next.TraceBatchQuery(ctx, conn, data)
+	}
 	segment, ok := ctx.Value(batchSegmentKey).(*newrelic.DatastoreSegment)
 	if !ok {
 		return
 	}
 
-	segment.ParameterizedQuery += data.SQL + "\n"
+	t.mu.Lock()
+	sql, _ := t.statementSQL(conn, data.SQL)
+	t.mu.Unlock()
+	segment.ParameterizedQuery += sql + "\n"
 }
 
 // TraceBatchEnd implement pgx.BatchTracer. In this method we will get segment from context and fill it with
 func (t *Tracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	if next, ok := t.wrapped.(pgx.BatchTracer); ok {
+		defer next.TraceBatchEnd(ctx, conn, data)
+	}
 	segment, ok := ctx.Value(batchSegmentKey).(*newrelic.DatastoreSegment)
 	if !ok {
 		return
 	}
+	t.addPoolAttribute(segment)
 	segment.End()
+	t.noticeExpectedError(ctx, data.Err)
 }
 
 // TracePrepareStart is called at the beginning of Prepare calls. The returned context is used for the
 // rest of the call and will be passed to TracePrepareEnd. // implement pgx.PrepareTracer
 // The Query and QueryRow will call prepare. Fill this function will make the datastore segment called twice.
-// So this function woudln't do anything and just return the context.
+// So this function woudln't create a segment. It records the SQL of the
+// statements prepared with Conn.Prepare, so that the queries running them by
+// name are recorded with their SQL.
 func (t *Tracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	if data.Name != "" && ctx.Value(querySegmentKey) == nil && ctx.Value(batchSegmentKey) == nil {
+		t.mu.Lock()
+		t.addPreparedStatement(conn, data.Name, data.SQL)
+		t.mu.Unlock()
+	}
+	if next, ok := t.wrapped.(pgx.PrepareTracer); ok {
+		ctx = next.TracePrepareStart(ctx, conn, data)
+	}
 	return ctx
 }
 
-// TracePrepareEnd implement pgx.PrepareTracer. In this function nothing happens.
+// TracePrepareEnd implement pgx.PrepareTracer. When the statement of a query
+// is prepared, the query missed the statement cache.
 func (t *Tracer) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	if use, ok := ctx.Value(statementCacheKey).(*statementCacheUse); ok && !data.AlreadyPrepared {
+		use.prepared = true
+	}
+	if next, ok := t.wrapped.(pgx.PrepareTracer); ok {
+		next.TracePrepareEnd(ctx, conn, data)
+	}
+}
+
+// TraceCopyFromStart implement pgx.CopyFromTracer. CopyFrom calls are not
+// instrumented, this only calls the wrapped tracer.
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	if next, ok := t.wrapped.(pgx.CopyFromTracer); ok {
+		ctx = next.TraceCopyFromStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+// TraceCopyFromEnd implement pgx.CopyFromTracer. CopyFrom calls are not
+// instrumented, this only calls the wrapped tracer.
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	if next, ok := t.wrapped.(pgx.CopyFromTracer); ok {
+		next.TraceCopyFromEnd(ctx, conn, data)
+	}
 }
@@ -0,0 +1,98 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpgx5
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/newrelic/go-agent/v3/newrelic/sqlparse"
+)
+
+// Querier runs queries.  It is implemented by *pgx.Conn, *pgxpool.Pool and
+// pgx.Tx.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Query runs the query with q, timing it with a datastore segment of the
+// transaction in ctx.  It is meant for connections which are not
+// instrumented with the Tracer, to trace some queries explicitly: the
+// queries of an instrumented connection would be recorded twice.  As with
+// the Tracer, the segment covers the iteration of the rows, and ends once the
+// rows are exhausted or closed.
+//
+//	rows, err := nrpgx5.Query(ctx, pool, "SELECT id, name FROM users WHERE team = $1", team)
+func Query(ctx context.Context, q Querier, sql string, args ...interface{}) (pgx.Rows, error) {
+	segment := startSegment(ctx, sql, args)
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		segment.End()
+		return rows, err
+	}
+	return &segmentRows{Rows: rows, segment: segment}, nil
+}
+
+// QueryRow runs the query with q, timing it with a datastore segment of the
+// transaction in ctx, like Query.  The segment ends once the row is scanned.
+//
+//	err := nrpgx5.QueryRow(ctx, pool, "SELECT name FROM users WHERE id = $1", id).Scan(&name)
+func QueryRow(ctx context.Context, q Querier, sql string, args ...interface{}) pgx.Row {
+	segment := startSegment(ctx, sql, args)
+	return &segmentRow{Row: q.QueryRow(ctx, sql, args...), segment: segment}
+}
+
+// startSegment starts the datastore segment of the query.
+func startSegment(ctx context.Context, sql string, args []interface{}) *newrelic.DatastoreSegment {
+	segment := &newrelic.DatastoreSegment{
+		StartTime:          newrelic.FromContext(ctx).StartSegmentNow(),
+		Product:            newrelic.DatastorePostgres,
+		ParameterizedQuery: sql,
+		QueryParameters:    getQueryParameters(args),
+	}
+	// fill Operation and Collection
+	sqlparse.ParseQuery(segment, sql)
+	return segment
+}
+
+// segmentRows ends the segment of the query once its rows are exhausted or
+// closed.
+type segmentRows struct {
+	pgx.Rows
+	segment *newrelic.DatastoreSegment
+	ended   bool
+}
+
+func (r *segmentRows) end() {
+	if !r.ended {
+		r.ended = true
+		r.segment.End()
+	}
+}
+
+func (r *segmentRows) Next() bool {
+	if !r.Rows.Next() {
+		r.end()
+		return false
+	}
+	return true
+}
+
+func (r *segmentRows) Close() {
+	r.Rows.Close()
+	r.end()
+}
+
+// segmentRow ends the segment of the query once its row is scanned.
+type segmentRow struct {
+	pgx.Row
+	segment *newrelic.DatastoreSegment
+}
+
+func (r *segmentRow) Scan(dest ...interface{}) error {
+	defer r.segment.End()
+	return r.Row.Scan(dest...)
+}
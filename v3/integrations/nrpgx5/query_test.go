@@ -0,0 +1,60 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpgx5
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/egon12/pgsnap"
+	"github.com/facily-tech/go-agent/v3/internal"
+	"github.com/facily-tech/go-agent/v3/internal/integrationsupport"
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	snap := pgsnap.NewSnap(t, os.Getenv("PGSNAP_DB_URL"))
+	defer snap.Finish()
+
+	// The connection is not instrumented: the segments are created by
+	// Query and QueryRow.
+	cfg, _ := pgx.ParseConfig(snap.Addr())
+	con, _ := pgx.ConnectConfig(context.Background(), cfg)
+	defer con.Close(context.Background())
+
+	app := integrationsupport.NewBasicTestApp()
+	txn := app.StartTransaction(t.Name())
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	rows, err := Query(ctx, con, "SELECT id, name, timestamp FROM mytable LIMIT $1", 2)
+	assert.NoError(t, err)
+	var names []string
+	for rows.Next() {
+		var id int
+		var name string
+		var ts time.Time
+		assert.NoError(t, rows.Scan(&id, &name, &ts))
+		names = append(names, name)
+	}
+	assert.NoError(t, rows.Err())
+	rows.Close()
+	assert.Equal(t, []string{"Adrian", "Magdalena"}, names)
+
+	var id int
+	var name string
+	var ts time.Time
+	err = QueryRow(ctx, con, "SELECT id, name, timestamp FROM mytable").Scan(&id, &name, &ts)
+	assert.NoError(t, err)
+	assert.Equal(t, "Adrian", name)
+	txn.End()
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Datastore/operation/Postgres/select", Data: []float64{2}},
+		{Name: "Datastore/statement/Postgres/mytable/select", Data: []float64{2}},
+	})
+}
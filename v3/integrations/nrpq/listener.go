@@ -0,0 +1,60 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpq
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// StartNotificationTransaction starts a message transaction for a
+// notification received on a pq.Listener's Notify channel.  The transaction
+// is named after the notification channel.  The caller is responsible for
+// ending the returned transaction.  If either the `newrelic.Application` or
+// the `pq.Notification` is nil, nil is returned.
+func StartNotificationTransaction(app *newrelic.Application, n *pq.Notification) *newrelic.Transaction {
+	if nil == app || nil == n {
+		return nil
+	}
+	namer := internal.MessageMetricKey{
+		Library:         "Postgres",
+		DestinationType: string(newrelic.MessageTopic),
+		DestinationName: n.Channel,
+		Consumer:        true,
+	}
+	txn := app.StartTransaction(namer.Name())
+	integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageRoutingKey, n.Channel, nil)
+	return txn
+}
+
+// WrapListener wraps a function handling notifications received on a
+// pq.Listener's Notify channel.  If the `newrelic.Application` parameter is
+// non-nil, a transaction is started for each notification using
+// StartNotificationTransaction, added to the context passed to f, and ended
+// when f returns.
+//
+//	handle := nrpq.WrapListener(app, func(ctx context.Context, n *pq.Notification) {
+//		// ...
+//	})
+//	for n := range listener.Notify {
+//		handle(n)
+//	}
+//
+// pq.Listener sends a nil notification after re-establishing a lost
+// connection.  No transaction is started for nil notifications, but f is
+// still called.
+func WrapListener(app *newrelic.Application, f func(ctx context.Context, n *pq.Notification)) func(n *pq.Notification) {
+	return func(n *pq.Notification) {
+		ctx := context.Background()
+		if txn := StartNotificationTransaction(app, n); nil != txn {
+			defer txn.End()
+			ctx = newrelic.NewContext(ctx, txn)
+		}
+		f(ctx, n)
+	}
+}
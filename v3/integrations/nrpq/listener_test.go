@@ -0,0 +1,86 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrpq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func testApp() integrationsupport.ExpectApp {
+	return integrationsupport.NewTestApp(integrationsupport.SampleEverythingReplyFn, func(cfg *newrelic.Config) {
+		cfg.Attributes.Include = append(cfg.Attributes.Include, newrelic.AttributeMessageRoutingKey)
+	})
+}
+
+func TestStartNotificationTransactionNil(t *testing.T) {
+	if txn := StartNotificationTransaction(nil, &pq.Notification{Channel: "events"}); nil != txn {
+		t.Error("expected nil transaction for nil application")
+	}
+	app := testApp()
+	if txn := StartNotificationTransaction(app.Application, nil); nil != txn {
+		t.Error("expected nil transaction for nil notification")
+	}
+}
+
+func TestWrapListener(t *testing.T) {
+	app := testApp()
+	var called bool
+	handle := WrapListener(app.Application, func(ctx context.Context, n *pq.Notification) {
+		called = true
+		if nil == newrelic.FromContext(ctx) {
+			t.Error("expected transaction in context")
+		}
+	})
+	handle(&pq.Notification{BePid: 1, Channel: "events", Extra: "payload"})
+
+	if !called {
+		t.Fatal("wrapped function was not called")
+	}
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "OtherTransaction/all", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime", Scope: "", Forced: true, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/all", Scope: "", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/allOther", Scope: "", Forced: false, Data: nil},
+		{Name: "OtherTransaction/Go/Message/Postgres/Topic/Named/events", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime/Go/Message/Postgres/Topic/Named/events", Scope: "", Forced: false, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     "OtherTransaction/Go/Message/Postgres/Topic/Named/events",
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "events",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestWrapListenerNilNotification(t *testing.T) {
+	app := testApp()
+	var called bool
+	handle := WrapListener(app.Application, func(ctx context.Context, n *pq.Notification) {
+		called = true
+		if nil != newrelic.FromContext(ctx) {
+			t.Error("expected no transaction for a nil notification")
+		}
+	})
+	handle(nil)
+
+	if !called {
+		t.Fatal("wrapped function was not called")
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{})
+}
@@ -54,6 +54,24 @@ func NewHook(opts *redis.Options) redis.Hook {
 	return h
 }
 
+// InstrumentUniversalClient adds a hook created by NewHook to the client.  When
+// the client is a *redis.Client its options are used to get instance metrics
+// broken out by host and port.  Clients which span several servers, such as
+// *redis.ClusterClient and *redis.Ring, are instrumented without instance
+// information.  As with NewHook, calls must contain a context which includes
+// the transaction.
+func InstrumentUniversalClient(client redis.UniversalClient) {
+	if nil == client {
+		return
+	}
+	switch c := client.(type) {
+	case *redis.Client:
+		c.AddHook(NewHook(c.Options()))
+	default:
+		c.AddHook(NewHook(nil))
+	}
+}
+
 func (h hook) before(ctx context.Context, operation string) (context.Context, error) {
 	txn := newrelic.FromContext(ctx)
 	if txn == nil {
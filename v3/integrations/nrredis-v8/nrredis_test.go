@@ -166,3 +166,70 @@ func TestNewHookAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestInstrumentUniversalClientSingle(t *testing.T) {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Dialer: emptyDialer,
+		Addrs:  []string{"myhost:myport"},
+	})
+	if _, ok := client.(*redis.Client); !ok {
+		t.Fatalf("unexpected client type %T", client)
+	}
+
+	app := integrationsupport.NewTestApp(nil, nil)
+	txn := app.StartTransaction("txnName")
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	InstrumentUniversalClient(client)
+	client.Ping(ctx)
+	txn.End()
+
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/txnName", Forced: nil},
+		{Name: "OtherTransactionTotalTime/Go/txnName", Forced: nil},
+		{Name: "OtherTransaction/all", Forced: nil},
+		{Name: "OtherTransactionTotalTime", Forced: nil},
+		{Name: "Datastore/all", Forced: nil},
+		{Name: "Datastore/allOther", Forced: nil},
+		{Name: "Datastore/Redis/all", Forced: nil},
+		{Name: "Datastore/Redis/allOther", Forced: nil},
+		{Name: "Datastore/instance/Redis/myhost/myport", Forced: nil},
+		{Name: "Datastore/operation/Redis/ping", Forced: nil},
+		{Name: "Datastore/operation/Redis/ping", Scope: "OtherTransaction/Go/txnName", Forced: nil},
+	})
+}
+
+func TestInstrumentUniversalClientCluster(t *testing.T) {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Dialer: emptyDialer,
+		Addrs:  []string{"myhost:1", "myhost:2"},
+	})
+	if _, ok := client.(*redis.ClusterClient); !ok {
+		t.Fatalf("unexpected client type %T", client)
+	}
+
+	app := integrationsupport.NewTestApp(nil, nil)
+	txn := app.StartTransaction("txnName")
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	InstrumentUniversalClient(client)
+	client.Ping(ctx)
+	txn.End()
+
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/txnName", Forced: nil},
+		{Name: "OtherTransactionTotalTime/Go/txnName", Forced: nil},
+		{Name: "OtherTransaction/all", Forced: nil},
+		{Name: "OtherTransactionTotalTime", Forced: nil},
+		{Name: "Datastore/all", Forced: nil},
+		{Name: "Datastore/allOther", Forced: nil},
+		{Name: "Datastore/Redis/all", Forced: nil},
+		{Name: "Datastore/Redis/allOther", Forced: nil},
+		{Name: "Datastore/operation/Redis/ping", Forced: nil},
+		{Name: "Datastore/operation/Redis/ping", Scope: "OtherTransaction/Go/txnName", Forced: nil},
+	})
+}
+
+func TestInstrumentUniversalClientNil(t *testing.T) {
+	InstrumentUniversalClient(nil)
+}
@@ -0,0 +1,8 @@
+module github.com/newrelic/go-agent/v3/integrations/nrsarama
+
+go 1.17
+
+require (
+	github.com/IBM/sarama v1.41.0
+	github.com/newrelic/go-agent/v3 v3.18.2
+)
@@ -0,0 +1,178 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nrsarama instruments https://github.com/IBM/sarama.
+//
+// This package can be used to instrument Kafka producers and consumers built
+// with sarama.
+//
+// # Kafka producers
+//
+// Use `SendMessage` in place of `sarama.SyncProducer.SendMessage` to create a
+// message producer segment and to add distributed tracing headers to the
+// message:
+//
+//	txn := currentTransaction()  // current newrelic.Transaction
+//	msg := &sarama.ProducerMessage{
+//		Topic: "orders",
+//		Value: sarama.StringEncoder("Hello World"),
+//	}
+//	partition, offset, err := nrsarama.SendMessage(txn, producer, msg)
+//
+// For other producers, such as `sarama.AsyncProducer`, call
+// `StartProducerSegment` before sending the message and end the segment once
+// the message is handed over to the producer.
+//
+// # Kafka consumers
+//
+// Use `StartMessageTransaction` to start a transaction for each message
+// consumed.  The transaction is named after the topic, accepts the
+// distributed tracing headers found on the message, and records the message
+// partition and offset as attributes:
+//
+//	for msg := range partitionConsumer.Messages() {
+//		txn := nrsarama.StartMessageTransaction(app, msg)
+//		handle(newrelic.NewContext(context.Background(), txn), msg)
+//		txn.End()
+//	}
+//
+// # Consumer lag
+//
+// Use `RecordConsumerLag` to periodically record the lag of a consumer as a
+// custom metric, which can be used for alerting:
+//
+//	nrsarama.RecordConsumerLag(app, claim.Topic(), claim.Partition(),
+//		claim.HighWaterMarkOffset(), lowWatermark, committedOffset)
+package nrsarama
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func init() { internal.TrackUsage("integration", "framework", "sarama") }
+
+const (
+	// AttributeKafkaPartition is the attribute holding the partition of a
+	// consumed message.
+	AttributeKafkaPartition = "kafka.partition"
+	// AttributeKafkaOffset is the attribute holding the offset of a consumed
+	// message.
+	AttributeKafkaOffset = "kafka.offset"
+)
+
+// InsertDistributedTraceHeaders adds the distributed tracing headers of the
+// transaction to the headers of the producer message, with lowercase keys such
+// as "traceparent" and "newrelic".  Headers already present on the message
+// with the same keys, in any case, are replaced.
+func InsertDistributedTraceHeaders(txn *newrelic.Transaction, msg *sarama.ProducerMessage) {
+	if nil == txn || nil == msg {
+		return
+	}
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	for key := range hdrs {
+		setHeader(msg, strings.ToLower(key), hdrs.Get(key))
+	}
+}
+
+func setHeader(msg *sarama.ProducerMessage, key, value string) {
+	for i, h := range msg.Headers {
+		if strings.EqualFold(string(h.Key), key) {
+			msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	msg.Headers = append(msg.Headers, sarama.RecordHeader{
+		Key:   []byte(key),
+		Value: []byte(value),
+	})
+}
+
+// StartProducerSegment creates and starts a `newrelic.MessageProducerSegment`
+// for a message about to be sent to Kafka, and adds the distributed tracing
+// headers of the transaction to the message.  Call `End()` on the returned
+// segment once the message has been sent.  If the transaction or the message
+// is nil, nil is returned.
+func StartProducerSegment(txn *newrelic.Transaction, msg *sarama.ProducerMessage) *newrelic.MessageProducerSegment {
+	if nil == txn || nil == msg {
+		return nil
+	}
+	seg := &newrelic.MessageProducerSegment{
+		StartTime:       txn.StartSegmentNow(),
+		Library:         "Kafka",
+		DestinationType: newrelic.MessageTopic,
+		DestinationName: msg.Topic,
+	}
+	InsertDistributedTraceHeaders(txn, msg)
+	return seg
+}
+
+// SendMessage sends the message using the sarama.SyncProducer while timing
+// the call with a producer segment created by StartProducerSegment.
+func SendMessage(txn *newrelic.Transaction, producer sarama.SyncProducer, msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	seg := StartProducerSegment(txn, msg)
+	partition, offset, err = producer.SendMessage(msg)
+	seg.End()
+	return
+}
+
+// StartMessageTransaction starts a transaction for a message consumed from
+// Kafka.  The transaction is named after the topic of the message and accepts
+// the distributed tracing headers found on the message.  The partition and
+// offset of the message are recorded as attributes.  The caller is
+// responsible for ending the returned transaction.  If either the
+// `newrelic.Application` or the message is nil, nil is returned.
+func StartMessageTransaction(app *newrelic.Application, msg *sarama.ConsumerMessage) *newrelic.Transaction {
+	if nil == app || nil == msg {
+		return nil
+	}
+	namer := internal.MessageMetricKey{
+		Library:         "Kafka",
+		DestinationType: string(newrelic.MessageTopic),
+		DestinationName: msg.Topic,
+		Consumer:        true,
+	}
+	txn := app.StartTransaction(namer.Name())
+
+	hdrs := http.Header{}
+	for _, h := range msg.Headers {
+		if nil != h {
+			hdrs.Set(string(h.Key), string(h.Value))
+		}
+	}
+	txn.AcceptDistributedTraceHeaders(newrelic.TransportKafka, hdrs)
+
+	integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageRoutingKey, msg.Topic, nil)
+	txn.AddAttribute(AttributeKafkaPartition, msg.Partition)
+	txn.AddAttribute(AttributeKafkaOffset, msg.Offset)
+	return txn
+}
+
+// RecordConsumerLag records the lag of a consumer on a topic partition as the
+// custom metric "Custom/Message/Kafka/Topic/<topic>/Partition/<partition>/Lag".
+// The lag is the distance between the high watermark of the partition, as
+// returned by `sarama.PartitionConsumer.HighWaterMarkOffset` or
+// `sarama.ConsumerGroupClaim.HighWaterMarkOffset`, and the committed offset.
+// If no offset has been committed yet, indicated by a negative committed
+// offset, the low watermark is used in its place.
+func RecordConsumerLag(app *newrelic.Application, topic string, partition int32, highWatermark, lowWatermark, committedOffset int64) {
+	if nil == app {
+		return
+	}
+	if committedOffset < 0 {
+		committedOffset = lowWatermark
+	}
+	lag := highWatermark - committedOffset
+	if lag < 0 {
+		lag = 0
+	}
+	name := "Message/Kafka/Topic/" + topic + "/Partition/" + strconv.Itoa(int(partition)) + "/Lag"
+	app.RecordCustomMetric(name, float64(lag))
+}
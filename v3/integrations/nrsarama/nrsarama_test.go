@@ -0,0 +1,192 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrsarama
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+var replyFn = func(reply *internal.ConnectReply) {
+	reply.SetSampleEverything()
+	reply.AccountID = "123"
+	reply.TrustedAccountKey = "123"
+	reply.PrimaryAppID = "456"
+}
+
+func testApp() integrationsupport.ExpectApp {
+	return integrationsupport.NewTestApp(replyFn, integrationsupport.DTEnabledCfgFn, func(cfg *newrelic.Config) {
+		cfg.Attributes.Include = append(cfg.Attributes.Include, newrelic.AttributeMessageRoutingKey)
+	})
+}
+
+func headerValue(headers []sarama.RecordHeader, key string) string {
+	for _, h := range headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestSendMessage(t *testing.T) {
+	app := testApp()
+	txn := app.StartTransaction("producer")
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+		return nil
+	})
+
+	msg := &sarama.ProducerMessage{
+		Topic:   "orders",
+		Value:   sarama.StringEncoder("hello"),
+		Headers: []sarama.RecordHeader{{Key: []byte("existing"), Value: []byte("value")}},
+	}
+	if _, _, err := SendMessage(txn, producer, msg); nil != err {
+		t.Fatal(err)
+	}
+	txn.End()
+	if err := producer.Close(); nil != err {
+		t.Fatal(err)
+	}
+
+	if v := headerValue(msg.Headers, "existing"); v != "value" {
+		t.Errorf("existing header was modified: %q", v)
+	}
+	if v := headerValue(msg.Headers, "traceparent"); v == "" {
+		t.Error("traceparent header was not inserted")
+	}
+	if v := headerValue(msg.Headers, "newrelic"); v == "" {
+		t.Error("newrelic header was not inserted")
+	}
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "MessageBroker/Kafka/Topic/Produce/Named/orders", Scope: "", Forced: false, Data: nil},
+		{Name: "MessageBroker/Kafka/Topic/Produce/Named/orders", Scope: "OtherTransaction/Go/producer", Forced: false, Data: nil},
+	})
+}
+
+func TestSendMessageError(t *testing.T) {
+	app := testApp()
+	txn := app.StartTransaction("producer")
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(errors.New("oops"))
+
+	msg := &sarama.ProducerMessage{Topic: "orders", Value: sarama.StringEncoder("hello")}
+	if _, _, err := SendMessage(txn, producer, msg); nil == err {
+		t.Error("expected error to be returned")
+	}
+	txn.End()
+	producer.Close()
+}
+
+func TestStartProducerSegmentNil(t *testing.T) {
+	// Make sure that nil arguments do not cause panics
+	StartProducerSegment(nil, &sarama.ProducerMessage{Topic: "orders"}).End()
+
+	app := testApp()
+	txn := app.StartTransaction("producer")
+	StartProducerSegment(txn, nil).End()
+	txn.End()
+}
+
+func TestStartMessageTransaction(t *testing.T) {
+	producerApp := testApp()
+	producerTxn := producerApp.StartTransaction("producer")
+	produced := &sarama.ProducerMessage{Topic: "orders"}
+	InsertDistributedTraceHeaders(producerTxn, produced)
+	producerTxn.End()
+
+	consumed := &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    42,
+	}
+	for i := range produced.Headers {
+		consumed.Headers = append(consumed.Headers, &produced.Headers[i])
+	}
+
+	app := testApp()
+	txn := StartMessageTransaction(app.Application, consumed)
+	txn.End()
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/Message/Kafka/Topic/Named/orders", Scope: "", Forced: true, Data: nil},
+		{Name: "Supportability/TraceContext/Accept/Success", Scope: "", Forced: true, Data: nil},
+		{Name: "TransportDuration/App/123/456/Kafka/all", Scope: "", Forced: false, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":                     "OtherTransaction/Go/Message/Kafka/Topic/Named/orders",
+				"guid":                     internal.MatchAnything,
+				"priority":                 internal.MatchAnything,
+				"sampled":                  internal.MatchAnything,
+				"traceId":                  internal.MatchAnything,
+				"parentId":                 internal.MatchAnything,
+				"parentSpanId":             internal.MatchAnything,
+				"parent.type":              "App",
+				"parent.account":           "123",
+				"parent.app":               "456",
+				"parent.transportType":     "Kafka",
+				"parent.transportDuration": internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "orders",
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeKafkaPartition: 3,
+				AttributeKafkaOffset:    42,
+			},
+		},
+	})
+}
+
+func TestStartMessageTransactionNil(t *testing.T) {
+	if txn := StartMessageTransaction(nil, &sarama.ConsumerMessage{Topic: "orders"}); nil != txn {
+		t.Error("expected nil transaction for nil application")
+	}
+	app := testApp()
+	if txn := StartMessageTransaction(app.Application, nil); nil != txn {
+		t.Error("expected nil transaction for nil message")
+	}
+}
+
+func TestRecordConsumerLag(t *testing.T) {
+	testcases := []struct {
+		committed int64
+		lag       float64
+	}{
+		{committed: 90, lag: 10},
+		{committed: 100, lag: 0},
+		// No committed offset: the low watermark is used.
+		{committed: -1, lag: 60},
+	}
+
+	for _, tc := range testcases {
+		app := testApp()
+		RecordConsumerLag(app.Application, "orders", 3, 100, 40, tc.committed)
+		app.ExpectMetricsPresent(t, []internal.WantMetric{
+			{
+				Name:   "Custom/Message/Kafka/Topic/orders/Partition/3/Lag",
+				Scope:  "",
+				Forced: false,
+				Data:   []float64{1, tc.lag, tc.lag, tc.lag, tc.lag, tc.lag * tc.lag},
+			},
+		})
+	}
+}
+
+func TestRecordConsumerLagNilApp(t *testing.T) {
+	// Make sure that a nil application does not cause panics
+	RecordConsumerLag(nil, "orders", 3, 100, 40, 90)
+}
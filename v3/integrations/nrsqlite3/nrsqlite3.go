@@ -74,6 +74,7 @@ package nrsqlite3
 import (
 	"database/sql"
 	"database/sql/driver"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -122,10 +123,15 @@ func InstrumentSQLDriver(d *sqlite3.SQLiteDriver) driver.Driver {
 }
 
 func getPortPathOrID(dsn string) (ppoid string) {
-	ppoid = strings.Split(dsn, "?")[0]
-	ppoid = strings.TrimPrefix(ppoid, "file:")
+	parts := strings.SplitN(dsn, "?", 2)
+	ppoid = strings.TrimPrefix(parts[0], "file:")
 
-	if ":memory:" != ppoid && "" != ppoid {
+	// Named in-memory databases, such as "file:memdb?mode=memory", are not
+	// backed by a file and must not be resolved against the working
+	// directory.
+	inMemory := len(parts) == 2 && isMemoryMode(parts[1])
+
+	if ":memory:" != ppoid && "" != ppoid && !inMemory {
 		if abs, err := filepath.Abs(ppoid); nil == err {
 			ppoid = abs
 		}
@@ -134,11 +140,21 @@ func getPortPathOrID(dsn string) (ppoid string) {
 	return
 }
 
+func isMemoryMode(query string) bool {
+	values, err := url.ParseQuery(query)
+	if nil != err {
+		return false
+	}
+	return "memory" == values.Get("mode")
+}
+
 // ParseDSN accepts a DSN string and sets the Host, PortPathOrID, and
-// DatabaseName fields on a newrelic.DatastoreSegment.
+// DatabaseName fields on a newrelic.DatastoreSegment.  The database file
+// path, or the in-memory database name, is used as the DatabaseName so that
+// it is recorded as the db.instance attribute on segments.
 func parseDSN(s *newrelic.DatastoreSegment, dsn string) {
 	// See https://godoc.org/github.com/mattn/go-sqlite3#SQLiteDriver.Open
 	s.Host = "localhost"
 	s.PortPathOrID = getPortPathOrID(dsn)
-	s.DatabaseName = ""
+	s.DatabaseName = s.PortPathOrID
 }
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 func TestGetPortPathOrID(t *testing.T) {
@@ -21,6 +23,9 @@ func TestGetPortPathOrID(t *testing.T) {
 		{"test.db", filepath.Join(currentDir, "test.db")},
 		{"file:/test.db?cache=shared&mode=memory", "/test.db"},
 		{"file::memory:", ":memory:"},
+		{"file::memory:?cache=shared", ":memory:"},
+		{"file:memdb1?mode=memory&cache=shared", "memdb1"},
+		{"file:test.db?cache=shared&mode=rwc", filepath.Join(currentDir, "test.db")},
 		{"", ""},
 	}
 
@@ -30,3 +35,44 @@ func TestGetPortPathOrID(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDSN(t *testing.T) {
+	_, here, _, _ := runtime.Caller(0)
+	currentDir := filepath.Dir(here)
+
+	testcases := []struct {
+		dsn             string
+		expPortPathOrID string
+		expDatabaseName string
+	}{
+		{
+			dsn:             "file:test.db?cache=shared&_busy_timeout=5000",
+			expPortPathOrID: filepath.Join(currentDir, "test.db"),
+			expDatabaseName: filepath.Join(currentDir, "test.db"),
+		},
+		{
+			dsn:             ":memory:",
+			expPortPathOrID: ":memory:",
+			expDatabaseName: ":memory:",
+		},
+		{
+			dsn:             "file:memdb1?mode=memory&cache=shared",
+			expPortPathOrID: "memdb1",
+			expDatabaseName: "memdb1",
+		},
+	}
+
+	for _, test := range testcases {
+		s := &newrelic.DatastoreSegment{}
+		parseDSN(s, test.dsn)
+		if "localhost" != s.Host {
+			t.Errorf(`incorrect host: dsn="%s", actual="%s"`, test.dsn, s.Host)
+		}
+		if test.expPortPathOrID != s.PortPathOrID {
+			t.Errorf(`incorrect port path or id: dsn="%s", actual="%s"`, test.dsn, s.PortPathOrID)
+		}
+		if test.expDatabaseName != s.DatabaseName {
+			t.Errorf(`incorrect database name: dsn="%s", actual="%s"`, test.dsn, s.DatabaseName)
+		}
+	}
+}
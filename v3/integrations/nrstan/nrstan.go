@@ -4,6 +4,10 @@
 package nrstan
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+
 	stan "github.com/nats-io/stan.go"
 	"github.com/newrelic/go-agent/v3/internal"
 	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
@@ -34,3 +38,93 @@ func StreamingSubWrapper(app *newrelic.Application, f func(msg *stan.Msg)) func(
 		f(msg)
 	}
 }
+
+// AttributeStanSequence is the attribute holding the sequence number of a
+// NATS Streaming message.
+const AttributeStanSequence = "stan.sequence"
+
+// Envelope wraps the data of a NATS Streaming message together with
+// distributed tracing headers.  NATS Streaming messages do not have headers,
+// so publishers that want subscribers to continue their traces must publish
+// envelopes created by NewEnvelope.
+type Envelope struct {
+	Headers map[string]string `json:"newrelic_headers"`
+	Data    []byte            `json:"data"`
+}
+
+// NewEnvelope returns the JSON encoding of an Envelope holding the data and
+// the distributed tracing headers of the transaction.  Publish the result in
+// place of the data:
+//
+//	envelope, err := nrstan.NewEnvelope(txn, []byte("Hello World"))
+//	if nil == err {
+//		err = sc.Publish(subject, envelope)
+//	}
+func NewEnvelope(txn *newrelic.Transaction, data []byte) ([]byte, error) {
+	env := Envelope{
+		Headers: map[string]string{},
+		Data:    data,
+	}
+	if nil != txn {
+		hdrs := http.Header{}
+		txn.InsertDistributedTraceHeaders(hdrs)
+		for key := range hdrs {
+			env.Headers[key] = hdrs.Get(key)
+		}
+	}
+	return json.Marshal(env)
+}
+
+// StreamingSubscribe subscribes to the subject using the `stan.Conn`.  If the
+// `newrelic.Application` parameter is non-nil, a `newrelic.Transaction` is
+// started for each message received, added to the context passed to the
+// handler, and ended when the handler returns.  The subject and the sequence
+// number of the message are recorded as attributes.  If the message data is
+// an Envelope created by NewEnvelope, its distributed tracing headers are
+// accepted and the message data is replaced with the wrapped data before the
+// handler is called.
+func StreamingSubscribe(app *newrelic.Application, sc stan.Conn, subject string, handler func(ctx context.Context, msg *stan.Msg), opts ...stan.SubscriptionOption) (stan.Subscription, error) {
+	return sc.Subscribe(subject, wrapStreamingHandler(app, handler), opts...)
+}
+
+func wrapStreamingHandler(app *newrelic.Application, handler func(ctx context.Context, msg *stan.Msg)) stan.MsgHandler {
+	return func(msg *stan.Msg) {
+		hdrs := openEnvelope(msg)
+		ctx := context.Background()
+		if nil != app {
+			namer := internal.MessageMetricKey{
+				Library:         "STAN",
+				DestinationType: string(newrelic.MessageTopic),
+				DestinationName: msg.MsgProto.Subject,
+				Consumer:        true,
+			}
+			txn := app.StartTransaction(namer.Name())
+			defer txn.End()
+
+			if nil != hdrs {
+				txn.AcceptDistributedTraceHeaders(newrelic.TransportQueue, hdrs)
+			}
+			integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageRoutingKey, msg.MsgProto.Subject, nil)
+			integrationsupport.AddAgentAttribute(txn, newrelic.AttributeMessageReplyTo, msg.MsgProto.Reply, nil)
+			txn.AddAttribute(AttributeStanSequence, msg.MsgProto.Sequence)
+			ctx = newrelic.NewContext(ctx, txn)
+		}
+		handler(ctx, msg)
+	}
+}
+
+// openEnvelope returns the distributed tracing headers of the message if its
+// data is an Envelope, in which case the data is replaced with the wrapped
+// data.  It returns nil otherwise.
+func openEnvelope(msg *stan.Msg) http.Header {
+	var env Envelope
+	if err := json.Unmarshal(msg.MsgProto.Data, &env); nil != err || len(env.Headers) == 0 {
+		return nil
+	}
+	hdrs := http.Header{}
+	for key, value := range env.Headers {
+		hdrs.Set(key, value)
+	}
+	msg.MsgProto.Data = env.Data
+	return hdrs
+}
@@ -22,6 +22,16 @@
 //	app := createTestApp(t)  // newrelic.Application
 //	sc.Subscribe(subject, StreamingSubWrapper(app, myMessageHandler)
 //
+// `nrstan.StreamingSubscribe` subscribes with a handler that receives a context
+// containing the transaction.  It records the sequence number of each message,
+// and continues distributed traces from messages published as envelopes
+// created by `nrstan.NewEnvelope`.  Example:
+//
+//	nrstan.StreamingSubscribe(app, sc, subject, func(ctx context.Context, msg *stan.Msg) {
+//		txn := newrelic.FromContext(ctx)
+//		// ...
+//	})
+//
 //
 // NATS Streaming publishers
 //
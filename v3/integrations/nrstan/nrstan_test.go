@@ -0,0 +1,155 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nrstan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/stan.go"
+	"github.com/nats-io/stan.go/pb"
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/integrationsupport"
+	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// fakeConn captures the handler passed to Subscribe so that tests can invoke
+// it without a NATS Streaming server.
+type fakeConn struct {
+	stan.Conn
+	subject string
+	handler stan.MsgHandler
+}
+
+func (c *fakeConn) Subscribe(subject string, cb stan.MsgHandler, opts ...stan.SubscriptionOption) (stan.Subscription, error) {
+	c.subject = subject
+	c.handler = cb
+	return nil, nil
+}
+
+var replyFn = func(reply *internal.ConnectReply) {
+	reply.SetSampleEverything()
+	reply.AccountID = "123"
+	reply.TrustedAccountKey = "123"
+	reply.PrimaryAppID = "456"
+}
+
+func testApp() integrationsupport.ExpectApp {
+	return integrationsupport.NewTestApp(replyFn, func(cfg *newrelic.Config) {
+		cfg.Attributes.Include = append(cfg.Attributes.Include,
+			newrelic.AttributeMessageRoutingKey,
+			newrelic.AttributeMessageReplyTo,
+		)
+	})
+}
+
+func TestStreamingSubscribe(t *testing.T) {
+	app := testApp()
+	conn := &fakeConn{}
+	var data string
+	StreamingSubscribe(app.Application, conn, "sample.subject", func(ctx context.Context, msg *stan.Msg) {
+		data = string(msg.Data)
+		if nil == newrelic.FromContext(ctx) {
+			t.Error("expected transaction in context")
+		}
+	})
+	if conn.subject != "sample.subject" {
+		t.Errorf("unexpected subject: %q", conn.subject)
+	}
+
+	conn.handler(&stan.Msg{MsgProto: pb.MsgProto{
+		Sequence: 7,
+		Subject:  "sample.subject",
+		Data:     []byte("data"),
+	}})
+
+	if data != "data" {
+		t.Errorf("unexpected data: %q", data)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":     "OtherTransaction/Go/Message/STAN/Topic/Named/sample.subject",
+				"guid":     internal.MatchAnything,
+				"priority": internal.MatchAnything,
+				"sampled":  internal.MatchAnything,
+				"traceId":  internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "sample.subject",
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeStanSequence: 7,
+			},
+		},
+	})
+}
+
+func TestStreamingSubscribeEnvelope(t *testing.T) {
+	producerApp := testApp()
+	producerTxn := producerApp.StartTransaction("producer")
+	envelope, err := NewEnvelope(producerTxn, []byte("data"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	producerTxn.End()
+
+	app := testApp()
+	conn := &fakeConn{}
+	var data string
+	StreamingSubscribe(app.Application, conn, "sample.subject", func(ctx context.Context, msg *stan.Msg) {
+		data = string(msg.Data)
+	})
+	conn.handler(&stan.Msg{MsgProto: pb.MsgProto{
+		Sequence: 8,
+		Subject:  "sample.subject",
+		Data:     envelope,
+	}})
+
+	if data != "data" {
+		t.Errorf("envelope was not unwrapped: %q", data)
+	}
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Supportability/TraceContext/Accept/Success", Scope: "", Forced: true, Data: nil},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":                     "OtherTransaction/Go/Message/STAN/Topic/Named/sample.subject",
+				"guid":                     internal.MatchAnything,
+				"priority":                 internal.MatchAnything,
+				"sampled":                  internal.MatchAnything,
+				"traceId":                  internal.MatchAnything,
+				"parentId":                 internal.MatchAnything,
+				"parentSpanId":             internal.MatchAnything,
+				"parent.type":              "App",
+				"parent.account":           "123",
+				"parent.app":               "456",
+				"parent.transportType":     "Queue",
+				"parent.transportDuration": internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "sample.subject",
+			},
+			UserAttributes: map[string]interface{}{
+				AttributeStanSequence: 8,
+			},
+		},
+	})
+}
+
+func TestStreamingSubscribeNilApp(t *testing.T) {
+	conn := &fakeConn{}
+	var called bool
+	StreamingSubscribe(nil, conn, "sample.subject", func(ctx context.Context, msg *stan.Msg) {
+		called = true
+		if nil != newrelic.FromContext(ctx) {
+			t.Error("expected no transaction in context")
+		}
+	})
+	conn.handler(&stan.Msg{MsgProto: pb.MsgProto{Subject: "sample.subject"}})
+	if !called {
+		t.Fatal("handler was not called")
+	}
+}
@@ -16,6 +16,9 @@ const (
 	// MaxCustomEvents is the maximum number of Transaction Events that can be captured
 	// per 60-second harvest cycle
 	MaxCustomEvents = 30 * 1000
+	// MaxCustomEventsServerLimit is the largest number of Custom Events
+	// the collector accepts per 60-second harvest cycle
+	MaxCustomEventsServerLimit = 100 * 1000
 	// MaxLogEvents is the maximum number of Log Events that can be captured per
 	// 60-second harvest cycle
 	MaxLogEvents = 10 * 1000
@@ -32,6 +32,13 @@ type appRun struct {
 	// between transactions.
 	rulesCache *rulesCache
 
+	// browserHeader caches the transaction independent portion of the
+	// Browser timing header.  It exists here since it is specific to the
+	// connect reply and config, and is created on first use when
+	// Config.BrowserMonitoring.CacheStaticHeader is enabled.
+	browserHeaderOnce sync.Once
+	browserHeader     *browserStaticHeader
+
 	// harvestConfig contains configuration related to event limits and
 	// flexible harvest periods.  This field is created once at appRun
 	// creation.
@@ -295,3 +302,10 @@ func (run *appRun) createTransactionName(input string, isWeb bool) string {
 	}
 	return name
 }
+
+func (run *appRun) browserStaticHeader() *browserStaticHeader {
+	run.browserHeaderOnce.Do(func() {
+		run.browserHeader = newBrowserStaticHeader(run.Reply, run.Config.License)
+	})
+	return run.browserHeader
+}
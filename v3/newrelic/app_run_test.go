@@ -499,3 +499,62 @@ func TestCreateTransactionName(t *testing.T) {
 		t.Error("wanted:", want, "got:", out)
 	}
 }
+
+func TestRuntimeConfigNotConnected(t *testing.T) {
+	app := testApp(nil, nil, t)
+	if rc := app.RuntimeConfig(); !reflect.DeepEqual(rc, RuntimeConfig{}) {
+		t.Errorf("expected zero value before connect, got %+v", rc)
+	}
+	var nilApp *Application
+	if rc := nilApp.RuntimeConfig(); !reflect.DeepEqual(rc, RuntimeConfig{}) {
+		t.Errorf("expected zero value for nil app, got %+v", rc)
+	}
+}
+
+func uintPtr(u uint) *uint { return &u }
+
+func TestRuntimeConfigFromConnectReply(t *testing.T) {
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.RunID = "run-id"
+		reply.EntityGUID = "entity-guid"
+		reply.ApdexThresholdSeconds = 0.25
+		reply.SamplingTarget = 20
+		reply.SamplingTargetPeriodInSeconds = 30
+		reply.EventData.ReportPeriodMs = 5000
+		reply.EventData.Limits.TxnEvents = uintPtr(100)
+		reply.EventData.Limits.CustomEvents = uintPtr(200)
+		reply.EventData.Limits.ErrorEvents = uintPtr(10)
+		reply.EventData.Limits.LogEvents = uintPtr(300)
+		reply.SpanEventHarvestConfig.HarvestLimit = uintPtr(400)
+		reply.CollectErrorEvents = false
+		reply.ServerSideConfig.TransactionTracerThreshold = 1.5
+		reply.ServerSideConfig.TransactionTracerStackTraceThreshold = float64Ptr(0.1)
+	}
+	app := testApp(replyfn, nil, t)
+	rc := app.RuntimeConfig()
+
+	expect := RuntimeConfig{
+		RunID:                    "run-id",
+		EntityGUID:               "entity-guid",
+		ApdexThreshold:           250 * time.Millisecond,
+		SamplingTarget:           20,
+		SamplingTargetPeriod:     30 * time.Second,
+		EventHarvestPeriod:       5 * time.Second,
+		MaxTxnEvents:             100,
+		MaxCustomEvents:          200,
+		MaxErrorEvents:           10,
+		MaxSpanEvents:            400,
+		MaxLogEvents:             300,
+		ErrorCollectorEnabled:    true,
+		ErrorEventsEnabled:       false,
+		TransactionEventsEnabled: true,
+		SpanEventsEnabled:        true,
+	}
+	expect.TransactionTracer.Enabled = true
+	expect.TransactionTracer.Threshold = 1500 * time.Millisecond
+	expect.TransactionTracer.StackTraceThreshold = 100 * time.Millisecond
+
+	if !reflect.DeepEqual(rc, expect) {
+		t.Errorf("unexpected runtime config:\ngot:  %+v\nwant: %+v", rc, expect)
+	}
+}
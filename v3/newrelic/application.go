@@ -4,6 +4,7 @@
 package newrelic
 
 import (
+	"net/http"
 	"os"
 	"time"
 )
@@ -34,7 +35,8 @@ func (app *Application) StartTransaction(name string, opts ...TraceOption) *Tran
 // restricted keywords, see:
 // https://docs.newrelic.com/docs/insights/new-relic-insights/adding-querying-data/inserting-custom-events-new-relic-apm-agents
 //
-// An error is logged if eventType or params is invalid.
+// An error is logged if eventType or params is invalid.  Use
+// ValidateCustomEvent to obtain the offending attribute directly.
 func (app *Application) RecordCustomEvent(eventType string, params map[string]interface{}) {
 	if nil == app {
 		return
@@ -44,11 +46,37 @@ func (app *Application) RecordCustomEvent(eventType string, params map[string]in
 	}
 	err := app.app.RecordCustomEvent(eventType, params)
 	if err != nil {
-		app.app.Error("unable to record custom event", map[string]interface{}{
-			"event-type": eventType,
-			"reason":     err.Error(),
-		})
+		app.logCustomEventError(eventType, err)
+	}
+}
+
+// RecordCustomEventWithTimestamp works just like RecordCustomEvent, except
+// that the event is recorded with the timestamp given rather than the current
+// time.  Use it to record events for past occurrences.  New Relic only
+// accepts timestamps within 24 hours of the current time: an error is logged
+// and the event is dropped if the timestamp is outside of this window.
+func (app *Application) RecordCustomEventWithTimestamp(eventType string, params map[string]interface{}, timestamp time.Time) {
+	if nil == app {
+		return
+	}
+	if nil == app.app {
+		return
+	}
+	err := app.app.RecordCustomEventWithTimestamp(eventType, params, timestamp)
+	if err != nil {
+		app.logCustomEventError(eventType, err)
+	}
+}
+
+func (app *Application) logCustomEventError(eventType string, err error) {
+	fields := map[string]interface{}{
+		"event-type": eventType,
+		"reason":     err.Error(),
+	}
+	if e, ok := err.(*InvalidEventError); ok && e.Field != "" {
+		fields["field"] = e.Field
 	}
+	app.app.Error("unable to record custom event", fields)
 }
 
 // RecordCustomMetric records a custom metric.  The metric name you
@@ -74,6 +102,32 @@ func (app *Application) RecordCustomMetric(name string, value float64) {
 	}
 }
 
+// RecordDimensionalMetric records a dimensional metric: a metric whose
+// values are aggregated per set of attributes rather than per name, so that
+// they can be faceted by the attributes in queries.  Unlike custom metrics,
+// the name is not prefixed.
+//
+// Each value in the attributes map must be a number, string, or boolean.
+// The map may not contain more than 32 attributes.  To bound the number of
+// series, at most 100 different attribute sets of a metric name are recorded
+// per harvest: the values recorded with other attribute sets are dropped.
+// Dimensional metrics are not currently supported in serverless mode.
+func (app *Application) RecordDimensionalMetric(name string, value float64, attributes map[string]interface{}) {
+	if nil == app {
+		return
+	}
+	if nil == app.app {
+		return
+	}
+	err := app.app.RecordDimensionalMetric(name, value, attributes)
+	if err != nil {
+		app.app.Error("unable to record dimensional metric", map[string]interface{}{
+			"metric-name": name,
+			"reason":      err.Error(),
+		})
+	}
+}
+
 // RecordLog records the data from a single log line.
 // This consumes a LogData object that should be configured
 // with data taken from a logging framework.
@@ -97,6 +151,33 @@ func (app *Application) RecordLog(logEvent LogData) {
 	}
 }
 
+// RecordSlowQuery records a slow database query as a custom event of type
+// SlowQueryEventType, so that datastore integrations report slow queries
+// with the same event type and attributes: product, statement, duration (in
+// seconds), host and rows.  The literals of the statement are obfuscated, and
+// the statement is omitted when SQL must not be recorded, as for the slow
+// query traces.  The event is subject to the same restrictions as
+// the events recorded with RecordCustomEvent.  An error is logged if the
+// query data or the event is invalid.
+func (app *Application) RecordSlowQuery(query SlowQueryData) {
+	if nil == app {
+		return
+	}
+	if nil == app.app {
+		return
+	}
+	run, _ := app.app.getState()
+	params, err := query.eventParams(run)
+	if err == nil {
+		err = app.app.RecordCustomEvent(SlowQueryEventType, params)
+	}
+	if err != nil {
+		app.app.Error("unable to record slow query", map[string]interface{}{
+			"reason": err.Error(),
+		})
+	}
+}
+
 // WaitForConnection blocks until the application is connected, is
 // incapable of being connected, or the timeout has been reached.  This
 // method is useful for short-lived processes since the application will
@@ -122,6 +203,54 @@ func (app *Application) WaitForConnection(timeout time.Duration) error {
 	return app.app.WaitForConnection(timeout)
 }
 
+// RuntimeConfig returns the settings in effect for the application's current
+// connection to New Relic, including those applied by the server.  The zero
+// value is returned if the application is not yet connected.
+func (app *Application) RuntimeConfig() RuntimeConfig {
+	if nil == app || nil == app.app {
+		return RuntimeConfig{}
+	}
+	run, _ := app.app.getState()
+	if "" == run.Reply.RunID {
+		return RuntimeConfig{}
+	}
+	return newRuntimeConfig(run)
+}
+
+// Enabled returns whether the agent is enabled, as set by Config.Enabled.
+// Transactions started by a disabled Application are never reported, so
+// integrations may use Enabled to skip adding instrumentation altogether.
+// Enabled returns false if the Application is nil.
+func (app *Application) Enabled() bool {
+	if nil == app || nil == app.app {
+		return false
+	}
+	return app.app.config.Enabled
+}
+
+// PrometheusHandler returns an http.Handler exposing the supportability
+// metrics of the agent in the Prometheus text format, to monitor the health
+// of the agent alongside the metrics of the application:
+//
+//	http.Handle("/metrics/newrelic", app.PrometheusHandler())
+//
+// The metrics are reported as the newrelic_agent_supportability summary, the
+// name of each metric being in its "metric" label, for instance
+// "Supportability/EventHarvest/ReportPeriod".  The counts and sums are
+// accumulated from the metrics harvested since the application started: they
+// are updated at each harvest, and nothing is reported before the first
+// harvest, nor by serverless applications.  Metrics which could not be sent
+// and are retried with the next harvest are only counted once sent.  A nil
+// Application exposes no metrics.
+func (app *Application) PrometheusHandler() http.Handler {
+	if nil == app || nil == app.app {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", prometheusContentType)
+		})
+	}
+	return app.app.prometheusHandler()
+}
+
 // Shutdown flushes data to New Relic's servers and stops all
 // agent-related goroutines managing this application.  After Shutdown
 // is called, the Application is disabled and will never collect data
@@ -132,10 +261,35 @@ func (app *Application) WaitForConnection(timeout time.Duration) error {
 // If Infinite Tracing is enabled, Shutdown will block until all queued span
 // events have been sent to the Trace Observer or the timeout has been reached.
 func (app *Application) Shutdown(timeout time.Duration) {
+	app.ShutdownWithResult(timeout)
+}
+
+// ShutdownResult reports the outcome of the final harvest made by
+// Application.ShutdownWithResult.
+type ShutdownResult struct {
+	// Completed is false when the timeout elapsed before the final
+	// harvest completed.  The counts are then unknown and left at zero.
+	Completed bool
+	// Flushed is the number of data items, such as metrics, events,
+	// traces or errors, sent to New Relic by the final harvest.
+	Flushed int
+	// Dropped is the number of data items lost: those of the final
+	// harvest that could not be sent, and those recorded while the
+	// application was not connected.
+	Dropped int
+}
+
+// ShutdownWithResult works just like Shutdown, except that it reports
+// whether the final harvest completed before the timeout and how many data
+// items were sent or dropped, for instance to tell whether a restart lost
+// data.  Data recorded while the application was not connected, which is
+// never harvested, is counted as dropped.  Nil, disabled and serverless
+// applications report a completed shutdown with no data.
+func (app *Application) ShutdownWithResult(timeout time.Duration) ShutdownResult {
 	if nil == app {
-		return
+		return ShutdownResult{Completed: true}
 	}
-	app.app.Shutdown(timeout)
+	return app.app.Shutdown(timeout)
 }
 
 func newApplication(app *app) *Application {
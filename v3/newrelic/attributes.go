@@ -125,6 +125,10 @@ const (
 	AttributeMessageCorrelationID = "message.correlationId"
 )
 
+// MessageHeaderAttributePrefix is the prefix of the span attributes holding
+// the headers of a produced message, see MessageProducerSegment.Headers.
+const MessageHeaderAttributePrefix = "message.headers."
+
 // Attributes destined for Span Events. These attributes appear only on Span
 // Events and are not available to transaction events, error events, or traced
 // errors.
@@ -138,6 +142,7 @@ const (
 	SpanAttributeDBStatement             = "db.statement"
 	SpanAttributeDBInstance              = "db.instance"
 	SpanAttributeDBCollection            = "db.collection"
+	SpanAttributeDBExplain               = "db.explain"
 	SpanAttributePeerAddress             = "peer.address"
 	SpanAttributePeerHostname            = "peer.hostname"
 	SpanAttributeHTTPURL                 = "http.url"
@@ -151,6 +156,9 @@ const (
 	SpanAttributeParentAccount           = "parent.account"
 	SpanAttributeParentTransportDuration = "parent.transportDuration"
 	SpanAttributeParentTransportType     = "parent.transportType"
+	// SpanAttributeQueueDuration is the time, in seconds, the transaction
+	// waited in a queue before it started, recorded on its root span.
+	SpanAttributeQueueDuration = "queueDuration"
 
 	// Deprecated: This attribute is a duplicate of AttributeResponseCode and
 	// will be removed in a later release.
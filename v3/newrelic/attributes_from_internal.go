@@ -61,6 +61,7 @@ var (
 		SpanAttributeDBStatement:             usualDests,
 		SpanAttributeDBInstance:              usualDests,
 		SpanAttributeDBCollection:            usualDests,
+		SpanAttributeDBExplain:               usualDests,
 		SpanAttributePeerAddress:             usualDests,
 		SpanAttributePeerHostname:            usualDests,
 		SpanAttributeHTTPURL:                 usualDests,
@@ -75,6 +76,7 @@ var (
 		SpanAttributeParentAccount:           usualDests,
 		SpanAttributeParentTransportDuration: usualDests,
 		SpanAttributeParentTransportType:     usualDests,
+		SpanAttributeQueueDuration:           usualDests,
 	}
 )
 
@@ -0,0 +1,126 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Baggage limits follow the W3C baggage specification:
+// https://www.w3.org/TR/baggage/#limits
+const (
+	maxBaggageEntries = 64
+	maxBaggageBytes   = 8192
+)
+
+var (
+	errBaggageKey     = errors.New("baggage key must be a non-empty token")
+	errBaggageEntries = fmt.Errorf("maximum of %d baggage entries exceeded", maxBaggageEntries)
+	errBaggageSize    = fmt.Errorf("baggage exceeds size limit of %d bytes", maxBaggageBytes)
+)
+
+// baggage holds the key value pairs propagated in the W3C baggage header.
+type baggage map[string]string
+
+// isBaggageKey reports whether key is a valid RFC 7230 token.
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func baggageEntrySize(key, value string) int {
+	return len(key) + len("=") + len(url.PathEscape(value))
+}
+
+// size returns the length of the encoded header.
+func (b baggage) size() int {
+	size := 0
+	for key, value := range b {
+		if size > 0 {
+			size += len(",")
+		}
+		size += baggageEntrySize(key, value)
+	}
+	return size
+}
+
+// set adds or replaces an entry, enforcing the entry and size limits.
+func (b baggage) set(key, value string) error {
+	if !isBaggageKey(key) {
+		return errBaggageKey
+	}
+	size := b.size()
+	if old, exists := b[key]; exists {
+		size -= baggageEntrySize(key, old)
+	} else if len(b) >= maxBaggageEntries {
+		return errBaggageEntries
+	}
+	if size > 0 {
+		size += len(",")
+	}
+	if size+baggageEntrySize(key, value) > maxBaggageBytes {
+		return errBaggageSize
+	}
+	b[key] = value
+	return nil
+}
+
+// header returns the W3C baggage header value.  Entries are sorted by key to
+// make the output deterministic.
+func (b baggage) header() string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(url.PathEscape(b[key]))
+	}
+	return sb.String()
+}
+
+// merge adds the entries of the inbound baggage headers that are not already
+// present.  Malformed entries and entries beyond the limits are dropped.
+// Properties following the value are ignored.
+func (b baggage) merge(hdrs []string) {
+	for _, hdr := range hdrs {
+		for _, member := range strings.Split(hdr, ",") {
+			if idx := strings.IndexByte(member, ';'); idx >= 0 {
+				member = member[:idx]
+			}
+			idx := strings.IndexByte(member, '=')
+			if idx < 0 {
+				continue
+			}
+			key := strings.TrimSpace(member[:idx])
+			value, err := url.PathUnescape(strings.TrimSpace(member[idx+1:]))
+			if nil != err {
+				continue
+			}
+			if _, exists := b[key]; exists {
+				continue
+			}
+			b.set(key, value)
+		}
+	}
+}
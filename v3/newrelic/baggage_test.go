@@ -0,0 +1,55 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBaggageEntryLimit(t *testing.T) {
+	b := make(baggage)
+	for i := 0; i < maxBaggageEntries; i++ {
+		if err := b.set("key"+strconv.Itoa(i), "value"); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := b.set("another", "value"); err != errBaggageEntries {
+		t.Error(err)
+	}
+	if err := b.set("key0", "replaced"); nil != err {
+		t.Error(err)
+	}
+}
+
+func TestBaggageSizeLimit(t *testing.T) {
+	b := make(baggage)
+	if err := b.set("big", strings.Repeat("a", maxBaggageBytes)); err != errBaggageSize {
+		t.Error(err)
+	}
+	if err := b.set("big", strings.Repeat("a", maxBaggageBytes-len("big="))); nil != err {
+		t.Error(err)
+	}
+	if size := b.size(); size != maxBaggageBytes {
+		t.Error(size)
+	}
+	if err := b.set("x", ""); err != errBaggageSize {
+		t.Error(err)
+	}
+}
+
+func TestBaggageMerge(t *testing.T) {
+	b := baggage{"tenant": "local"}
+	b.merge([]string{"tenant=remote, region = us%2Deast;prop=1,malformed,bad key=1", "user=a%20b"})
+	expect := baggage{"tenant": "local", "region": "us-east", "user": "a b"}
+	if len(b) != len(expect) {
+		t.Fatal(b)
+	}
+	for key, val := range expect {
+		if b[key] != val {
+			t.Error(key, b[key])
+		}
+	}
+}
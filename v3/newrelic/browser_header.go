@@ -6,6 +6,9 @@ package newrelic
 import (
 	"bytes"
 	"encoding/json"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/jsonx"
 )
 
 var (
@@ -35,6 +38,73 @@ type browserInfo struct {
 type BrowserTimingHeader struct {
 	agentLoader string
 	info        browserInfo
+	// static is the cached output surrounding the transaction specific
+	// info fields.  It is nil unless
+	// Config.BrowserMonitoring.CacheStaticHeader is enabled.
+	static *browserStaticHeader
+}
+
+// browserStaticHeader contains the portions of the browser timing JavaScript
+// which are the same for every transaction of a connection: everything before
+// and after the transaction specific fields of the info hash.  The head and
+// tail are marshalled so that the combined output matches the json.Marshal
+// output of browserInfo.
+type browserStaticHeader struct {
+	head        []byte
+	tail        []byte
+	encodingKey []byte
+}
+
+func newBrowserStaticHeader(reply *internal.ConnectReply, license string) *browserStaticHeader {
+	buf := &bytes.Buffer{}
+	buf.WriteString(reply.AgentLoader)
+	buf.Write(browserInfoPrefix)
+	buf.WriteString(`{"beacon":`)
+	buf.Write(browserJSONString(reply.Beacon))
+	buf.WriteString(`,"licenseKey":`)
+	buf.Write(browserJSONString(reply.BrowserKey))
+	buf.WriteString(`,"applicationID":`)
+	buf.Write(browserJSONString(reply.AppID))
+	buf.WriteString(`,"transactionName":`)
+	head := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	buf.WriteString(`,"errorBeacon":`)
+	buf.Write(browserJSONString(reply.ErrorBeacon))
+	buf.WriteString(`,"agent":`)
+	buf.Write(browserJSONString(reply.JSAgentFile))
+	buf.WriteByte('}')
+
+	return &browserStaticHeader{
+		head:        head,
+		tail:        append([]byte(nil), buf.Bytes()...),
+		encodingKey: browserEncodingKey(license),
+	}
+}
+
+// browserJSONString marshals a string the way json.Marshal does, including
+// its HTML escaping, so that cached and uncached headers are identical.
+func browserJSONString(s string) []byte {
+	js, _ := json.Marshal(s)
+	return js
+}
+
+// appendInfo appends the info hash fields of the transaction to the cached
+// head and tail.  The transaction name and attributes are obfuscated, and
+// therefore need no escaping.
+func (s *browserStaticHeader) appendInfo(info *browserInfo) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, len(s.head)+len(s.tail)+
+		len(info.TransactionName)+len(info.ObfuscatedAttributes)+64))
+	buf.Write(s.head)
+	jsonx.AppendString(buf, info.TransactionName)
+	buf.WriteString(`,"queueTime":`)
+	jsonx.AppendInt(buf, info.QueueTimeMillis)
+	buf.WriteString(`,"applicationTime":`)
+	jsonx.AppendInt(buf, info.ApplicationTimeMillis)
+	buf.WriteString(`,"atts":`)
+	jsonx.AppendString(buf, info.ObfuscatedAttributes)
+	buf.Write(s.tail)
+	return buf.Bytes()
 }
 
 func appendSlices(slices ...[]byte) []byte {
@@ -70,6 +140,9 @@ func (h *BrowserTimingHeader) WithoutTags() []byte {
 	if nil == h {
 		return nil
 	}
+	if nil != h.static {
+		return h.static.appendInfo(&h.info)
+	}
 
 	// We could memoise this, but it seems unnecessary, since most users are
 	// going to call this zero or one times.
@@ -89,3 +89,27 @@ func TestBrowserAttributes(t *testing.T) {
 		t.Errorf("unexpected browser attributes: expected %s; got %s", expected, actual)
 	}
 }
+
+func benchmarkBrowserTimingHeader(b *testing.B, cacheStaticHeader bool) {
+	app := testApp(browserReplyFields, func(cfg *Config) {
+		cfg.BrowserMonitoring.CacheStaticHeader = cacheStaticHeader
+	}, b)
+	txn := app.StartTransaction("hello")
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if nil == txn.BrowserTimingHeader().WithTags() {
+			b.Fatal("missing browser timing header")
+		}
+	}
+}
+
+func BenchmarkBrowserTimingHeader(b *testing.B) {
+	benchmarkBrowserTimingHeader(b, false)
+}
+
+func BenchmarkBrowserTimingHeaderCacheStaticHeader(b *testing.B) {
+	benchmarkBrowserTimingHeader(b, true)
+}
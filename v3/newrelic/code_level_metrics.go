@@ -90,6 +90,7 @@ type traceOptSet struct {
 	DemandCLM        bool
 	IgnoredPrefixes  []string
 	PathPrefixes     []string
+	WorkerID         string
 }
 
 //
@@ -210,6 +211,23 @@ func WithCodeLevelMetrics() TraceOption {
 	}
 }
 
+// WorkerIDAttribute is the name of the user attribute added to transactions
+// started with the WithWorkerID option.
+const WorkerIDAttribute = "worker.id"
+
+//
+// WithWorkerID records the identifier of the worker processing this trace
+// as the WorkerIDAttribute user attribute. This is useful for background
+// jobs run from a pool of workers, where it helps to know which worker
+// handled a given transaction. Segments may be tagged with the same
+// identifier using their AddAttribute method.
+//
+func WithWorkerID(id string) TraceOption {
+	return func(o *traceOptSet) {
+		o.WorkerID = id
+	}
+}
+
 //
 // WithThisCodeLocation is equivalent to calling WithCodeLocation, referring
 // to the point in the code where the WithThisCodeLocation call is being made.
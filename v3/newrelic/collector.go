@@ -38,6 +38,8 @@ const (
 	cmdTxnTraces    = "transaction_sample_data"
 	cmdSlowSQLs     = "sql_trace_data"
 	cmdSpanEvents   = "span_event_data"
+
+	cmdDimensionalMetrics = "dimensional_metric_data"
 )
 
 // rpmCmd contains fields specific to an individual call made to RPM.
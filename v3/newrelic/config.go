@@ -72,9 +72,20 @@ type Config struct {
 		// setting.
 		Enabled bool
 		// MaxSamplesStored sets the desired maximum custom event samples stored
+		// per 60-second harvest cycle.  It may be raised above the
+		// default, up to 100000, at the cost of the memory holding the
+		// events: see ConfigCustomInsightsEventsMaxSamplesStored.
 		MaxSamplesStored int
 	}
 
+	// ApdexThresholds sets the Apdex threshold of the transactions named,
+	// overriding the application Apdex threshold.  Transactions are
+	// matched either by the name they were given or by their full metric
+	// name, eg. "WebTransaction/Go/GET /report".  Thresholds set for key
+	// transactions in the New Relic UI take precedence.  Use
+	// ConfigApdexForTransaction to populate this field.
+	ApdexThresholds map[string]time.Duration
+
 	// TransactionEvents controls the behavior of transaction analytics
 	// events.
 	TransactionEvents struct {
@@ -164,6 +175,13 @@ type Config struct {
 		//
 		//	cfg.BrowserMonitoring.Attributes.Enabled = true
 		Attributes AttributeDestinationConfig
+		// CacheStaticHeader controls whether the portion of the Browser
+		// timing JavaScript which does not depend on the transaction is
+		// generated once per connection to New Relic and reused, rather
+		// than generated for every Transaction.BrowserTimingHeader call.
+		// Enable it to reduce the cost of injecting the header on
+		// high-traffic pages.
+		CacheStaticHeader bool
 	}
 
 	// HostDisplayName gives this server a recognizable name in the New
@@ -304,6 +322,15 @@ type Config struct {
 		SlowQuery struct {
 			Enabled   bool
 			Threshold time.Duration
+			// Sample, when set, is called with each datastore
+			// segment whose duration exceeds Threshold when the
+			// segment ends.  A non-empty return value, such as the
+			// output of an EXPLAIN query, is recorded as the
+			// "db.explain" attribute of the segment.  Sample is not
+			// called in high security mode or when the security
+			// policies forbid recording SQL.  Use
+			// ConfigDatastoreSlowSample to set it.
+			Sample func(seg *DatastoreSegment) string `json:"-"`
 		}
 	}
 
@@ -353,6 +380,12 @@ type Config struct {
 	// Host can be used to override the New Relic endpoint.
 	Host string
 
+	// Clock provides the current time used to measure the durations of
+	// transactions and segments.  If nil, which is the default, the system
+	// clock is used.  It is intended for tests that need deterministic
+	// durations: see ConfigClock.
+	Clock Clock `json:"-"`
+
 	// Error may be populated by the ConfigOptions provided to NewApplication
 	// to indicate that setup has failed.  NewApplication will return this
 	// error if it is set.
@@ -417,6 +450,7 @@ type Config struct {
 	// application, to help manage project dependencies.
 	ModuleDependencyMetrics struct {
 		// Enabled controls whether the module dependencies are collected and reported.
+		// It is false by default: use ConfigModuleInfo to enable it.
 		Enabled bool
 		// RedactIgnoredPrefixes, if true, redacts a non-nil list of IgnoredPrefixes from
 		// the configuration data transmitted by the agent.
@@ -425,6 +459,10 @@ type Config struct {
 		// begins with one of these prefixes is excluded from the dependency reporting.
 		// This list of ignored prefixes itself is not reported outside the agent.
 		IgnoredPrefixes []string
+		// MaxModules is the maximum number of modules reported.  Modules
+		// beyond this limit, once the ignored modules are excluded, are
+		// not reported.  Zero means no limit.
+		MaxModules int
 	}
 }
 
@@ -575,6 +613,40 @@ type AttributeDestinationConfig struct {
 	Exclude []string
 }
 
+// AttributeDestination is a set of destinations of the attributes, used with
+// ConfigAttributeDestinations.  Destinations are combined with the |
+// operator.
+type AttributeDestination int
+
+// The destinations of the attributes.
+const (
+	// AttributeDestinationTransactionEvents is the transaction events.
+	AttributeDestinationTransactionEvents AttributeDestination = 1 << iota
+	// AttributeDestinationErrors is the error events and traces.
+	AttributeDestinationErrors
+	// AttributeDestinationTransactionTraces is the transaction traces.
+	AttributeDestinationTransactionTraces
+	// AttributeDestinationTraceSegments is the segments of the
+	// transaction traces.
+	AttributeDestinationTraceSegments
+	// AttributeDestinationSpanEvents is the span events.
+	AttributeDestinationSpanEvents
+	// AttributeDestinationBrowser is the browser monitoring.
+	AttributeDestinationBrowser
+)
+
+// attributeDestinations returns the configuration of each destination.
+func (c *Config) attributeDestinations() map[AttributeDestination]*AttributeDestinationConfig {
+	return map[AttributeDestination]*AttributeDestinationConfig{
+		AttributeDestinationTransactionEvents: &c.TransactionEvents.Attributes,
+		AttributeDestinationErrors:            &c.ErrorCollector.Attributes,
+		AttributeDestinationTransactionTraces: &c.TransactionTracer.Attributes,
+		AttributeDestinationTraceSegments:     &c.TransactionTracer.Segments.Attributes,
+		AttributeDestinationSpanEvents:        &c.SpanEvents.Attributes,
+		AttributeDestinationBrowser:           &c.BrowserMonitoring.Attributes,
+	}
+}
+
 // defaultConfig creates a Config populated with default settings.
 func defaultConfig() Config {
 	c := Config{}
@@ -652,7 +724,7 @@ func defaultConfig() Config {
 	c.CodeLevelMetrics.Scope = AllCLM
 
 	// Module Dependency Metrics
-	c.ModuleDependencyMetrics.Enabled = true
+	c.ModuleDependencyMetrics.Enabled = false
 	c.ModuleDependencyMetrics.RedactIgnoredPrefixes = true
 	return c
 }
@@ -730,13 +802,16 @@ func (c Config) maxTxnEvents() int {
 	return configured
 }
 
-// maxCustomEvents returns the configured maximum number of Custom Events if it has been configured
-// and is less than the default maximum; otherwise it returns the default max.
+// maxCustomEvents returns the configured maximum number of Custom Events if it has been configured;
+// otherwise it returns the default max.  It is capped to the maximum allowed by the collector.
 func (c Config) maxCustomEvents() int {
 	configured := c.CustomInsightsEvents.MaxSamplesStored
-	if configured < 0 || configured > internal.MaxCustomEvents {
+	if configured < 0 {
 		return internal.MaxCustomEvents
 	}
+	if configured > internal.MaxCustomEventsServerLimit {
+		return internal.MaxCustomEventsServerLimit
+	}
 	return configured
 }
 
@@ -771,6 +846,12 @@ func copyConfigReferenceFields(cfg Config) Config {
 			cp.Labels[key] = val
 		}
 	}
+	if nil != cfg.ApdexThresholds {
+		cp.ApdexThresholds = make(map[string]time.Duration, len(cfg.ApdexThresholds))
+		for name, threshold := range cfg.ApdexThresholds {
+			cp.ApdexThresholds[name] = threshold
+		}
+	}
 	if cfg.ErrorCollector.IgnoreStatusCodes != nil {
 		ignored := make([]int, len(cfg.ErrorCollector.IgnoreStatusCodes))
 		copy(ignored, cfg.ErrorCollector.IgnoreStatusCodes)
@@ -926,7 +1007,7 @@ func configConnectJSONInternal(c Config, pid int, util *utilization.Data, e envi
 		Util:             util,
 		SecurityPolicies: securityPolicies,
 		Metadata:         metadata,
-		EventData:        internal.DefaultEventHarvestConfigWithDT(c.TransactionEvents.MaxSamplesStored, c.ApplicationLogging.Forwarding.MaxSamplesStored, c.CustomInsightsEvents.MaxSamplesStored, c.DistributedTracer.ReservoirLimit, c.DistributedTracer.Enabled),
+		EventData:        internal.DefaultEventHarvestConfigWithDT(c.TransactionEvents.MaxSamplesStored, c.ApplicationLogging.Forwarding.MaxSamplesStored, c.maxCustomEvents(), c.DistributedTracer.ReservoirLimit, c.DistributedTracer.Enabled),
 	}})
 }
 
@@ -948,6 +1029,19 @@ func gatherMetadata(env []string) map[string]string {
 	return metadata
 }
 
+// Clock provides the current time.  See ConfigClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// now returns the current time according to the configured Clock.
+func (c Config) now() time.Time {
+	if nil == c.Clock {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
 // config exists to avoid adding private fields to Config.
 type config struct {
 	Config
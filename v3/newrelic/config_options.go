@@ -9,7 +9,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
+
+	"github.com/newrelic/go-agent/v3/internal"
 )
 
 // ConfigOption configures the Config when provided to NewApplication.
@@ -30,6 +33,70 @@ func ConfigLicense(license string) ConfigOption {
 	return func(cfg *Config) { cfg.License = license }
 }
 
+// ConfigClock sets the Clock used to measure the durations of transactions
+// and segments.  It exists so that tests can control durations
+// deterministically; production applications should use the default
+// system clock.
+func ConfigClock(clock Clock) ConfigOption {
+	return func(cfg *Config) { cfg.Clock = clock }
+}
+
+// ConfigCollectorHost sets the host of the collector to which the data is
+// sent, overriding the host derived from the license key.  Use it to send
+// data to a specific endpoint, for instance for data residency.
+func ConfigCollectorHost(host string) ConfigOption {
+	return func(cfg *Config) { cfg.Host = host }
+}
+
+// ConfigHostDisplayName sets Config.HostDisplayName, the name under which the
+// host is shown in the New Relic UI in place of its hostname, such as the
+// name of a pod in place of its hash.
+func ConfigHostDisplayName(name string) ConfigOption {
+	return func(cfg *Config) { cfg.HostDisplayName = name }
+}
+
+// ConfigHostDisplayNameFromEnv sets Config.HostDisplayName from the
+// environment variable named, for instance one set from the Kubernetes
+// downward API:
+//
+//	newrelic.ConfigHostDisplayNameFromEnv("NODE_NAME")
+//
+// Config.HostDisplayName is left unchanged if the variable is unset or empty.
+func ConfigHostDisplayNameFromEnv(name string) ConfigOption {
+	return configHostDisplayNameFromEnv(name, os.Getenv)
+}
+
+func configHostDisplayNameFromEnv(name string, getenv func(string) string) ConfigOption {
+	return ConfigHostDisplayNameResolver(func() string { return getenv(name) })
+}
+
+// ConfigHostDisplayNameResolver sets Config.HostDisplayName to the name
+// returned by resolve, for instance read from the metadata of the cloud
+// instance.  resolve is called once, when the option is applied.
+// Config.HostDisplayName is left unchanged if it returns an empty name.
+func ConfigHostDisplayNameResolver(resolve func() string) ConfigOption {
+	return func(cfg *Config) {
+		if name := resolve(); name != "" {
+			cfg.HostDisplayName = name
+		}
+	}
+}
+
+// ConfigRegion sets the collector host to the one of the region, for
+// instance RegionEU to keep the data of the application in the European
+// Union.  The OTLP endpoint of the region, for OpenTelemetry exporters, is
+// returned by Region.OTLPEndpoint.  NewApplication returns an error if the
+// region is not valid.
+func ConfigRegion(region Region) ConfigOption {
+	return func(cfg *Config) {
+		if err := region.Valid(); err != nil {
+			cfg.Error = err
+			return
+		}
+		cfg.Host = region.CollectorHost()
+	}
+}
+
 // ConfigDistributedTracerEnabled populates the Config's
 // DistributedTracer.Enabled setting.
 func ConfigDistributedTracerEnabled(enabled bool) ConfigOption {
@@ -40,9 +107,17 @@ func ConfigDistributedTracerEnabled(enabled bool) ConfigOption {
 // of how many custom events are stored in an agent for a given harvest cycle.
 // Alters the CustomInsightsEvents.MaxSamplesStored setting.
 // Note: As of Jul 2022, the absolute maximum events that can be sent each minute is 100000.
+//
+// The limit may be raised above the default of 30000 so that bursts of custom
+// events are not sampled out between harvests, up to the maximum allowed by
+// the collector, which is used instead of larger limits.  The limit requested
+// is sent when connecting and the collector has the final say.  The reservoir
+// holds the events of each harvest in memory, so that a larger limit
+// increases the memory used by the agent in proportion, each event holding
+// its attributes.
 func ConfigCustomInsightsEventsMaxSamplesStored(limit int) ConfigOption {
-	if limit > 100000 {
-		return func(cfg *Config) { cfg.CustomInsightsEvents.MaxSamplesStored = 100000 }
+	if limit > internal.MaxCustomEventsServerLimit {
+		limit = internal.MaxCustomEventsServerLimit
 	}
 	return func(cfg *Config) { cfg.CustomInsightsEvents.MaxSamplesStored = limit }
 }
@@ -262,6 +337,82 @@ func ConfigAppLogForwardingMaxSamplesStored(maxSamplesStored int) ConfigOption {
 	}
 }
 
+// ConfigDatastoreSlowSample sets the function called with each datastore
+// segment slower than the slow query threshold,
+// Config.DatastoreTracer.SlowQuery.Threshold.  Its return value is recorded as
+// the "db.explain" attribute of the segment.  This allows integrations to run
+// an EXPLAIN query only for slow queries.
+//
+// The function is called from the goroutine ending the segment, and the
+// segment end is delayed until it returns: it should be bounded by a timeout,
+// and must not use the transaction of the segment.
+func ConfigDatastoreSlowSample(sample func(seg *DatastoreSegment) string) ConfigOption {
+	return func(cfg *Config) { cfg.DatastoreTracer.SlowQuery.Sample = sample }
+}
+
+// ConfigApdexForTransaction sets the Apdex threshold of the transactions
+// named, overriding the application Apdex threshold.  Names are matched
+// against both the name given to the transaction and its full metric name.
+// Thresholds set for key transactions in the New Relic UI take precedence.
+//
+//	newrelic.ConfigApdexForTransaction(map[string]time.Duration{
+//		"GET /reports": 2 * time.Second,
+//	})
+func ConfigApdexForTransaction(thresholds map[string]time.Duration) ConfigOption {
+	return func(cfg *Config) {
+		if nil == cfg.ApdexThresholds {
+			cfg.ApdexThresholds = make(map[string]time.Duration, len(thresholds))
+		}
+		for name, threshold := range thresholds {
+			cfg.ApdexThresholds[name] = threshold
+		}
+	}
+}
+
+// ConfigBrowserMonitoringCacheStaticHeader controls whether the portion of the
+// Transaction.BrowserTimingHeader output which does not depend on the
+// transaction is cached.  See Config.BrowserMonitoring.CacheStaticHeader.
+func ConfigBrowserMonitoringCacheStaticHeader(enabled bool) ConfigOption {
+	return func(cfg *Config) { cfg.BrowserMonitoring.CacheStaticHeader = enabled }
+}
+
+// ConfigAttributesExclude prevents the capture of the attributes with the
+// given keys, for every destination.  It applies to the agent attributes,
+// such as AttributeRequestURI, as well as to the attributes added by the
+// application and the integrations.  The keys are added to
+// Config.Attributes.Exclude and may thus end with the '*' wildcard.  An
+// attribute excluded this way is not brought back by the Include list of a
+// destination with the same key.
+//
+//	newrelic.ConfigAttributesExclude(newrelic.AttributeRequestURI)
+func ConfigAttributesExclude(keys ...string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Attributes.Exclude = append(cfg.Attributes.Exclude, keys...)
+	}
+}
+
+// ConfigAttributeDestinations sends the attribute with the given key only to
+// the given destinations: it is added to the Include list of these
+// destinations and to the Exclude list of the others.  The key may end with
+// the '*' wildcard.  For example, to keep the attribute "order.items" on the
+// errors but not on the span events nor the transaction events:
+//
+//	newrelic.ConfigAttributeDestinations("order.items", newrelic.AttributeDestinationErrors)
+//
+// The attribute must still be captured for the destinations to get it: an
+// attribute excluded by Config.Attributes.Exclude is not brought back.
+func ConfigAttributeDestinations(key string, dests AttributeDestination) ConfigOption {
+	return func(cfg *Config) {
+		for dest, dc := range cfg.attributeDestinations() {
+			if 0 != dests&dest {
+				dc.Include = append(dc.Include, key)
+			} else {
+				dc.Exclude = append(dc.Exclude, key)
+			}
+		}
+	}
+}
+
 // ConfigLogger populates the Config's Logger.
 func ConfigLogger(l Logger) ConfigOption {
 	return func(cfg *Config) { cfg.Logger = l }
@@ -280,6 +431,18 @@ func ConfigModuleDependencyMetricsEnabled(enabled bool) ConfigOption {
 	}
 }
 
+// ConfigModuleInfo enables the reporting of the modules compiled into the
+// instrumented application, as read from runtime/debug.ReadBuildInfo, when
+// connecting to New Relic.  The modules are not collected unless this option,
+// or ConfigModuleDependencyMetricsEnabled, is used.  At most maxModules
+// modules are reported, zero meaning no limit.
+func ConfigModuleInfo(maxModules int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ModuleDependencyMetrics.Enabled = true
+		cfg.ModuleDependencyMetrics.MaxModules = maxModules
+	}
+}
+
 // ConfigModuleDependencyMetricsIgnoredPrefixes sets the list of module path prefix strings
 // indicating which modules should be excluded from the dependency report.
 func ConfigModuleDependencyMetricsIgnoredPrefixes(prefix ...string) ConfigOption {
@@ -316,6 +479,7 @@ func ConfigDebugLogger(w io.Writer) ConfigOption {
 //		NEW_RELIC_ATTRIBUTES_INCLUDE                      			sets Attributes.Include using a comma-separated list
 //		NEW_RELIC_MODULE_DEPENDENCY_METRICS_ENABLED          		sets ModuleDependencyMetrics.Enabled
 //		NEW_RELIC_MODULE_DEPENDENCY_METRICS_IGNORED_PREFIXES 		sets ModuleDependencyMetrics.IgnoredPrefixes
+//		NEW_RELIC_MODULE_DEPENDENCY_METRICS_MAX_MODULES      		sets ModuleDependencyMetrics.MaxModules using strconv.Atoi
 //		NEW_RELIC_MODULE_DEPENDENCY_METRICS_REDACT_IGNORED_PREFIXES sets ModuleDependencyMetrics.RedactIgnoredPrefixes to a boolean value
 //		NEW_RELIC_CODE_LEVEL_METRICS_ENABLED              			sets CodeLevelMetrics.Enabled
 //		NEW_RELIC_CODE_LEVEL_METRICS_SCOPE                			sets CodeLevelMetrics.Scope using a comma-separated list, e.g. "transaction"
@@ -351,6 +515,35 @@ func ConfigFromEnvironment() ConfigOption {
 	return configFromEnvironment(os.Getenv)
 }
 
+// ConfigEnabledFromEnv enables or disables the agent using only the
+// environment, leaving all other settings untouched.  Config.Enabled is set
+// using strconv.ParseBool from NEW_RELIC_ENABLED or, if it is unset, from
+// NEW_RELIC_AGENT_ENABLED.  Config.Enabled is left unchanged if neither is
+// set, and Config.Error is assigned if the value cannot be parsed.
+//
+// Use Application.Enabled to check whether the agent is enabled, for example
+// to skip adding instrumentation altogether.
+func ConfigEnabledFromEnv() ConfigOption {
+	return configEnabledFromEnv(os.Getenv)
+}
+
+func configEnabledFromEnv(getenv func(string) string) ConfigOption {
+	return func(cfg *Config) {
+		for _, name := range []string{"NEW_RELIC_ENABLED", "NEW_RELIC_AGENT_ENABLED"} {
+			env := getenv(name)
+			if env == "" {
+				continue
+			}
+			if b, err := strconv.ParseBool(env); nil != err {
+				cfg.Error = fmt.Errorf("invalid %s value: %s", name, env)
+			} else {
+				cfg.Enabled = b
+			}
+			return
+		}
+	}
+}
+
 func configFromEnvironment(getenv func(string) string) ConfigOption {
 	return func(cfg *Config) {
 		// Because fields could have been assigned in a previous
@@ -397,6 +590,7 @@ func configFromEnvironment(getenv func(string) string) ConfigOption {
 		assignString(&cfg.Utilization.BillingHostname, "NEW_RELIC_UTILIZATION_BILLING_HOSTNAME")
 		assignString(&cfg.InfiniteTracing.TraceObserver.Host, "NEW_RELIC_INFINITE_TRACING_TRACE_OBSERVER_HOST")
 		assignInt(&cfg.InfiniteTracing.TraceObserver.Port, "NEW_RELIC_INFINITE_TRACING_TRACE_OBSERVER_PORT")
+		assignInt(&cfg.ModuleDependencyMetrics.MaxModules, "NEW_RELIC_MODULE_DEPENDENCY_METRICS_MAX_MODULES")
 		assignInt(&cfg.Utilization.LogicalProcessors, "NEW_RELIC_UTILIZATION_LOGICAL_PROCESSORS")
 		assignInt(&cfg.Utilization.TotalRAMMIB, "NEW_RELIC_UTILIZATION_TOTAL_RAM_MIB")
 		assignInt(&cfg.InfiniteTracing.SpanEvents.QueueSize, "NEW_RELIC_INFINITE_TRACING_SPAN_EVENTS_QUEUE_SIZE")
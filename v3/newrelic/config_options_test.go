@@ -4,8 +4,13 @@
 package newrelic
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/internal"
+	"github.com/newrelic/go-agent/v3/internal/utilization"
 )
 
 func TestConfigFromEnvironment(t *testing.T) {
@@ -252,3 +257,190 @@ func TestConfigFromEnvironmentLabelsSuccess(t *testing.T) {
 		t.Error(cfg.Labels)
 	}
 }
+
+func TestConfigEnabledFromEnv(t *testing.T) {
+	testcases := []struct {
+		env      map[string]string
+		enabled  bool
+		hasError bool
+	}{
+		{env: map[string]string{}, enabled: true},
+		{env: map[string]string{"NEW_RELIC_ENABLED": "false"}, enabled: false},
+		{env: map[string]string{"NEW_RELIC_AGENT_ENABLED": "false"}, enabled: false},
+		{env: map[string]string{"NEW_RELIC_ENABLED": "true", "NEW_RELIC_AGENT_ENABLED": "false"}, enabled: true},
+		{env: map[string]string{"NEW_RELIC_ENABLED": "BOGUS"}, enabled: true, hasError: true},
+	}
+	for _, tc := range testcases {
+		cfg := defaultConfig()
+		cfg.AppName = "my app"
+		configEnabledFromEnv(func(s string) string { return tc.env[s] })(&cfg)
+		if cfg.Enabled != tc.enabled {
+			t.Error("incorrect enabled value:", tc.env, cfg.Enabled)
+		}
+		if (cfg.Error != nil) != tc.hasError {
+			t.Error("incorrect error:", tc.env, cfg.Error)
+		}
+		if cfg.AppName != "my app" {
+			t.Error("unrelated setting changed:", cfg.AppName)
+		}
+	}
+}
+
+func TestConfigEnabledFromEnvDisabledApp(t *testing.T) {
+	t.Setenv("NEW_RELIC_ENABLED", "false")
+	app, err := NewApplication(
+		ConfigAppName("my app"),
+		ConfigLicense(testLicenseKey),
+		ConfigEnabledFromEnv(),
+	)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if app.Enabled() {
+		t.Error("application should be disabled")
+	}
+	txn := app.StartTransaction("hello")
+	txn.End()
+	if rc := app.RuntimeConfig(); rc.RunID != "" {
+		t.Error("disabled application should not connect:", rc.RunID)
+	}
+	app.Shutdown(10 * time.Millisecond)
+
+	var nilApp *Application
+	if nilApp.Enabled() {
+		t.Error("nil application should not be enabled")
+	}
+}
+
+func TestConfigRegion(t *testing.T) {
+	testcases := []struct {
+		region    Region
+		collector string
+		otlp      string
+	}{
+		{region: RegionUS, collector: "collector.newrelic.com", otlp: "https://otlp.nr-data.net"},
+		{region: RegionEU, collector: "collector.eu01.nr-data.net", otlp: "https://otlp.eu01.nr-data.net"},
+		{region: RegionFedRAMP, collector: "gov-collector.newrelic.com", otlp: "https://gov-otlp.nr-data.net"},
+	}
+	for _, tc := range testcases {
+		cfg := config{Config: defaultConfig()}
+		// The region overrides the region of the license key.
+		cfg.License = "eu01xx6789012345678901234567890123456789"
+		ConfigRegion(tc.region)(&cfg.Config)
+		if nil != cfg.Error {
+			t.Fatal(tc.region, cfg.Error)
+		}
+		if host := cfg.preconnectHost(); host != tc.collector {
+			t.Error(tc.region, host)
+		}
+		if otlp := tc.region.OTLPEndpoint(); otlp != tc.otlp {
+			t.Error(tc.region, otlp)
+		}
+	}
+}
+
+func TestConfigRegionInvalid(t *testing.T) {
+	_, err := NewApplication(
+		ConfigAppName("my app"),
+		ConfigLicense(testLicenseKey),
+		ConfigRegion("Mars"),
+	)
+	if nil == err || err.Error() != `invalid region "Mars"` {
+		t.Error(err)
+	}
+}
+
+func TestConfigCollectorHost(t *testing.T) {
+	cfg := config{Config: defaultConfig()}
+	cfg.License = testLicenseKey
+	ConfigCollectorHost("collector.example.com")(&cfg.Config)
+	if host := cfg.preconnectHost(); host != "collector.example.com" {
+		t.Error(host)
+	}
+}
+
+func connectDisplayHost(t *testing.T, opts ...ConfigOption) string {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	js, err := configConnectJSONInternal(cfg, 123, &utilization.SampleData, sampleEnvironment, "0.2.2", nil, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload []struct {
+		DisplayHost *string `json:"display_host"`
+	}
+	if err := json.Unmarshal(js, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload[0].DisplayHost == nil {
+		return ""
+	}
+	return *payload[0].DisplayHost
+}
+
+func TestConfigHostDisplayName(t *testing.T) {
+	getenv := func(name string) string {
+		if name == "NODE_NAME" {
+			return "node-1"
+		}
+		return ""
+	}
+
+	if host := connectDisplayHost(t); host != "" {
+		t.Error(host)
+	}
+	if host := connectDisplayHost(t, ConfigHostDisplayName("checkout-1")); host != "checkout-1" {
+		t.Error(host)
+	}
+	if host := connectDisplayHost(t,
+		ConfigHostDisplayName("checkout-1"),
+		configHostDisplayNameFromEnv("NODE_NAME", getenv),
+	); host != "node-1" {
+		t.Error(host)
+	}
+	if host := connectDisplayHost(t,
+		ConfigHostDisplayName("checkout-1"),
+		configHostDisplayNameFromEnv("POD_NAME", getenv),
+	); host != "checkout-1" {
+		t.Error(host)
+	}
+	if host := connectDisplayHost(t,
+		ConfigHostDisplayNameResolver(func() string { return "instance-1" }),
+	); host != "instance-1" {
+		t.Error(host)
+	}
+	if host := connectDisplayHost(t,
+		ConfigHostDisplayName("checkout-1"),
+		ConfigHostDisplayNameResolver(func() string { return "" }),
+	); host != "checkout-1" {
+		t.Error(host)
+	}
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestConfigClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	app := testApp(nil, func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = false
+		ConfigClock(clock)(cfg)
+	}, t)
+	txn := app.StartTransaction("hello")
+	seg := txn.StartSegment("mySegment")
+	clock.Advance(2 * time.Second)
+	if d := seg.EndWithDuration(); d != 2*time.Second {
+		t.Error(d)
+	}
+	clock.Advance(time.Second)
+	txn.End()
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/hello", Scope: "", Forced: true, Data: []float64{1, 3, 0, 3, 3, 9}},
+		{Name: "Custom/mySegment", Scope: "", Forced: false, Data: []float64{1, 2, 2, 2, 2, 4}},
+		{Name: "Custom/mySegment", Scope: "OtherTransaction/Go/hello", Forced: false, Data: []float64{1, 2, 2, 2, 2, 4}},
+	})
+}
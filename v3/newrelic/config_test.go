@@ -130,6 +130,7 @@ func TestCopyConfigReferenceFieldsPresent(t *testing.T) {
 		"agent_version":"0.2.2",
 		"host":"my-hostname",
 		"settings":{
+			"ApdexThresholds":null,
 			"AppName":"my appname",
 			"ApplicationLogging": {
 				"Enabled": true,
@@ -147,6 +148,7 @@ func TestCopyConfigReferenceFieldsPresent(t *testing.T) {
 			"Attributes":{"Enabled":true,"Exclude":["2"],"Include":["1"]},
 			"BrowserMonitoring":{
 				"Attributes":{"Enabled":false,"Exclude":["10"],"Include":["9"]},
+				"CacheStaticHeader":false,
 				"Enabled":true
 			},
 			"CodeLevelMetrics":{"Enabled":false,"IgnoredPrefix":"","IgnoredPrefixes":null,"PathPrefix":"","PathPrefixes":null,"RedactIgnoredPrefixes":true,"RedactPathPrefixes":true,"Scope":"all"},
@@ -191,7 +193,7 @@ func TestCopyConfigReferenceFieldsPresent(t *testing.T) {
 			},
 			"Labels":{"zip":"zap"},
 			"Logger":"*logger.logFile",
-			"ModuleDependencyMetrics":{"Enabled":true,"IgnoredPrefixes":null,"RedactIgnoredPrefixes":true},
+			"ModuleDependencyMetrics":{"Enabled":false,"IgnoredPrefixes":null,"MaxModules":0,"RedactIgnoredPrefixes":true},
 			"RuntimeSampler":{"Enabled":true},
 			"SecurityPoliciesToken":"",
 			"ServerlessMode":{
@@ -325,6 +327,7 @@ func TestCopyConfigReferenceFieldsAbsent(t *testing.T) {
 		"agent_version":"0.2.2",
 		"host":"my-hostname",
 		"settings":{
+			"ApdexThresholds":null,
 			"AppName":"my appname",
 			"ApplicationLogging": {
 				"Enabled": true,
@@ -346,6 +349,7 @@ func TestCopyConfigReferenceFieldsAbsent(t *testing.T) {
 					"Exclude":null,
 					"Include":null
 				},
+				"CacheStaticHeader":false,
 				"Enabled":true
 			},
 			"CodeLevelMetrics":{"Enabled":false,"IgnoredPrefix":"","IgnoredPrefixes":null,"PathPrefix":"","PathPrefixes":null,"RedactIgnoredPrefixes":true,"RedactPathPrefixes":true,"Scope":"all"},
@@ -390,7 +394,7 @@ func TestCopyConfigReferenceFieldsAbsent(t *testing.T) {
 			},
 			"Labels":null,
 			"Logger":null,
-			"ModuleDependencyMetrics":{"Enabled":true,"IgnoredPrefixes":null,"RedactIgnoredPrefixes":true},
+			"ModuleDependencyMetrics":{"Enabled":false,"IgnoredPrefixes":null,"MaxModules":0,"RedactIgnoredPrefixes":true},
 			"RuntimeSampler":{"Enabled":true},
 			"SecurityPoliciesToken":"",
 			"ServerlessMode":{
@@ -23,8 +23,46 @@ var (
 	errEventTypeRegex = fmt.Errorf("event type must match %s", eventTypeRegexRaw)
 	errNumAttributes  = fmt.Errorf("maximum of %d attributes exceeded",
 		customEventAttributeLimit)
+	errEventTimestamp = fmt.Errorf("event timestamp must be within %d hours of the current time",
+		int(customEventTimestampWindow.Hours()))
 )
 
+// customEventTimestampWindow is the maximum difference between the timestamp
+// of a custom event and the current time that New Relic accepts.
+const customEventTimestampWindow = 24 * time.Hour
+
+// InvalidEventError is returned by ValidateCustomEvent when a custom event
+// cannot be recorded.  Field is the name of the offending attribute, and is
+// empty when the problem is with the event type or the number of attributes.
+type InvalidEventError struct {
+	Field  string
+	Reason string
+	err    error
+}
+
+func newInvalidEventError(field string, err error) *InvalidEventError {
+	return &InvalidEventError{
+		Field:  field,
+		Reason: err.Error(),
+		err:    err,
+	}
+}
+
+func (e *InvalidEventError) Error() string { return e.Reason }
+
+// Unwrap returns the underlying validation error.
+func (e *InvalidEventError) Unwrap() error { return e.err }
+
+// ValidateCustomEvent checks that the event type and params would be accepted
+// by Application.RecordCustomEvent.  If they would not, the returned error is
+// an *InvalidEventError identifying the problem.
+func ValidateCustomEvent(eventType string, params map[string]interface{}) error {
+	if _, err := createCustomEvent(eventType, params, time.Now()); nil != err {
+		return err
+	}
+	return nil
+}
+
 // customEvent is a custom event.
 type customEvent struct {
 	eventType       string
@@ -74,21 +112,28 @@ func eventTypeValidate(eventType string) error {
 	return nil
 }
 
+func eventTimestampValidate(timestamp, now time.Time) error {
+	if d := now.Sub(timestamp); d > customEventTimestampWindow || d < -customEventTimestampWindow {
+		return errEventTimestamp
+	}
+	return nil
+}
+
 // CreateCustomEvent creates a custom event.
 func createCustomEvent(eventType string, params map[string]interface{}, now time.Time) (*customEvent, error) {
 	if err := eventTypeValidate(eventType); nil != err {
-		return nil, err
+		return nil, newInvalidEventError("", err)
 	}
 
 	if len(params) > customEventAttributeLimit {
-		return nil, errNumAttributes
+		return nil, newInvalidEventError("", errNumAttributes)
 	}
 
 	truncatedParams := make(map[string]interface{})
 	for key, val := range params {
 		val, err := validateUserAttribute(key, val)
 		if nil != err {
-			return nil, err
+			return nil, newInvalidEventError(key, err)
 		}
 		truncatedParams[key] = val
 	}
@@ -100,6 +145,37 @@ func createCustomEvent(eventType string, params map[string]interface{}, now time
 	}, nil
 }
 
+// Attributes holding the trace context of the custom events recorded by the
+// agent on behalf of the user.
+const (
+	eventTraceIDAttr = "trace.id"
+	eventSpanIDAttr  = "span.id"
+)
+
+// eventParams are the attributes of the custom events recorded by the agent
+// on behalf of the user, such as the SlowQuery events.
+type eventParams map[string]interface{}
+
+// addString adds the attribute unless its value is empty.
+func (params eventParams) addString(key, value string) {
+	if value != "" {
+		params[key] = value
+	}
+}
+
+// addTraceMetadata adds the trace and span IDs of the metadata, unless they
+// are empty.
+func (params eventParams) addTraceMetadata(metadata TraceMetadata) {
+	params.addString(eventTraceIDAttr, metadata.TraceID)
+	params.addString(eventSpanIDAttr, metadata.SpanID)
+}
+
+// newEventLimitError returns the error of the events, described by what,
+// exceeding the limit of events recorded per transaction.
+func newEventLimitError(what string, limit int) error {
+	return fmt.Errorf("maximum of %d %s per transaction exceeded", limit, what)
+}
+
 // MergeIntoHarvest implements Harvestable.
 func (e *customEvent) MergeIntoHarvest(h *harvest) {
 	h.CustomEvents.Add(e)
@@ -5,6 +5,7 @@ package newrelic
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
@@ -45,7 +46,7 @@ func TestCreateCustomEventSuccess(t *testing.T) {
 
 func TestInvalidEventTypeCharacter(t *testing.T) {
 	event, err := createCustomEvent("myEvent!", map[string]interface{}{"alpha": 1}, now)
-	if err != errEventTypeRegex {
+	if !errors.Is(err, errEventTypeRegex) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -55,7 +56,7 @@ func TestInvalidEventTypeCharacter(t *testing.T) {
 
 func TestLongEventType(t *testing.T) {
 	event, err := createCustomEvent(strLen512, map[string]interface{}{"alpha": 1}, now)
-	if err != errEventTypeLength {
+	if !errors.Is(err, errEventTypeLength) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -79,7 +80,7 @@ func TestNilParams(t *testing.T) {
 
 func TestMissingEventType(t *testing.T) {
 	event, err := createCustomEvent("", map[string]interface{}{"alpha": 1}, now)
-	if err != errEventTypeRegex {
+	if !errors.Is(err, errEventTypeRegex) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -117,7 +118,7 @@ func TestTruncatedStringValue(t *testing.T) {
 
 func TestInvalidValueType(t *testing.T) {
 	event, err := createCustomEvent("myEvent", map[string]interface{}{"alpha": []string{}}, now)
-	if _, ok := err.(errInvalidAttributeType); !ok {
+	if !errors.As(err, new(errInvalidAttributeType)) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -130,7 +131,7 @@ func TestInvalidCustomAttributeKey(t *testing.T) {
 	if nil == err {
 		t.Fatal(err)
 	}
-	if _, ok := err.(invalidAttributeKeyErr); !ok {
+	if !errors.As(err, new(invalidAttributeKeyErr)) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -144,7 +145,7 @@ func TestTooManyAttributes(t *testing.T) {
 		params[strconv.Itoa(i)] = i
 	}
 	event, err := createCustomEvent("myEvent", params, now)
-	if errNumAttributes != err {
+	if !errors.Is(err, errNumAttributes) {
 		t.Fatal(err)
 	}
 	if nil != event {
@@ -224,3 +225,61 @@ func TestMultipleAttributeJSON(t *testing.T) {
 		t.Error(string(js))
 	}
 }
+
+func TestValidateCustomEventInvalidType(t *testing.T) {
+	err := ValidateCustomEvent("myEvent", map[string]interface{}{"alpha": 1, "beta": []string{}})
+	var e *InvalidEventError
+	if !errors.As(err, &e) {
+		t.Fatal(err)
+	}
+	if e.Field != "beta" {
+		t.Error(e.Field)
+	}
+	if e.Reason != err.Error() || e.Reason == "" {
+		t.Error(e.Reason)
+	}
+	if !errors.As(err, new(errInvalidAttributeType)) {
+		t.Error(err)
+	}
+}
+
+func TestValidateCustomEventInvalidEventType(t *testing.T) {
+	err := ValidateCustomEvent("myEvent!", map[string]interface{}{"alpha": 1})
+	var e *InvalidEventError
+	if !errors.As(err, &e) {
+		t.Fatal(err)
+	}
+	if e.Field != "" {
+		t.Error(e.Field)
+	}
+	if e.Reason != errEventTypeRegex.Error() {
+		t.Error(e.Reason)
+	}
+	if !errors.Is(err, errEventTypeRegex) {
+		t.Error(err)
+	}
+}
+
+func TestValidateCustomEventValid(t *testing.T) {
+	if err := ValidateCustomEvent("myEvent", map[string]interface{}{"alpha": 1}); nil != err {
+		t.Error(err)
+	}
+}
+
+func TestEventTimestampValidate(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		timestamp time.Time
+		err       error
+	}{
+		{timestamp: now},
+		{timestamp: now.Add(-customEventTimestampWindow)},
+		{timestamp: now.Add(customEventTimestampWindow)},
+		{timestamp: now.Add(-customEventTimestampWindow - time.Second), err: errEventTimestamp},
+		{timestamp: now.Add(customEventTimestampWindow + time.Second), err: errEventTimestamp},
+	} {
+		if err := eventTimestampValidate(tc.timestamp, now); err != tc.err {
+			t.Errorf("timestamp %v: got %v, want %v", tc.timestamp, err, tc.err)
+		}
+	}
+}
@@ -13,3 +13,12 @@ type customMetric struct {
 func (m customMetric) MergeIntoHarvest(h *harvest) {
 	h.Metrics.addValue(customMetricName(m.RawInputName), "", m.Value, unforced)
 }
+
+// supportabilityCount is a single count of a supportability metric recorded
+// outside of a transaction.
+type supportabilityCount string
+
+// MergeIntoHarvest implements Harvestable.
+func (name supportabilityCount) MergeIntoHarvest(h *harvest) {
+	h.Metrics.addSingleCount(string(name), forced)
+}
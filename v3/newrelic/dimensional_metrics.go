@@ -0,0 +1,189 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/internal/jsonx"
+)
+
+var (
+	errDimensionalMetricAttributes = fmt.Errorf("maximum of %d dimensional metric attributes exceeded",
+		dimensionalMetricAttributeLimit)
+	errDimensionalMetricServerless = errors.New("dimensional metrics are not currently supported in serverless mode")
+)
+
+// dimensionalMetricID identifies the time series of a dimensional metric:
+// its name and the JSON object of its attributes, with sorted keys so that
+// the same attributes always give the same series.
+type dimensionalMetricID struct {
+	Name       string
+	Attributes string
+}
+
+// dimensionalMetricTable aggregates the dimensional metrics recorded during
+// a harvest period.  Unlike the metricTable, it bounds the number of series
+// of each metric name, so that an attribute with unbounded values does not
+// crowd out the other metrics.
+type dimensionalMetricTable struct {
+	metricPeriodStart time.Time
+	failedHarvests    int
+	maxTableSize      int
+	maxCardinality    int
+	cardinality       map[string]int
+	metrics           map[dimensionalMetricID]*metricData
+}
+
+func newDimensionalMetricTable(maxTableSize, maxCardinality int, now time.Time) *dimensionalMetricTable {
+	return &dimensionalMetricTable{
+		metricPeriodStart: now,
+		maxTableSize:      maxTableSize,
+		maxCardinality:    maxCardinality,
+		cardinality:       make(map[string]int),
+		metrics:           make(map[dimensionalMetricID]*metricData),
+	}
+}
+
+// mergeMetric adds the data to the series, returning false if the series is
+// dropped because of the table size or the cardinality limit.
+func (mt *dimensionalMetricTable) mergeMetric(id dimensionalMetricID, data metricData) bool {
+	if to := mt.metrics[id]; nil != to {
+		to.aggregate(data)
+		return true
+	}
+	if len(mt.metrics) >= mt.maxTableSize || mt.cardinality[id.Name] >= mt.maxCardinality {
+		return false
+	}
+	alloc := new(metricData)
+	*alloc = data
+	mt.metrics[id] = alloc
+	mt.cardinality[id.Name]++
+	return true
+}
+
+func (mt *dimensionalMetricTable) addValue(name, attributes string, value float64) bool {
+	return mt.mergeMetric(dimensionalMetricID{Name: name, Attributes: attributes}, metricData{
+		countSatisfied:  1,
+		totalTolerated:  value,
+		exclusiveFailed: value,
+		min:             value,
+		max:             value,
+		sumSquares:      value * value,
+	})
+}
+
+func (mt *dimensionalMetricTable) mergeFailed(from *dimensionalMetricTable) {
+	fails := from.failedHarvests + 1
+	if fails >= failedMetricAttemptsLimit {
+		return
+	}
+	if from.metricPeriodStart.Before(mt.metricPeriodStart) {
+		mt.metricPeriodStart = from.metricPeriodStart
+	}
+	mt.failedHarvests = fails
+	for id, data := range from.metrics {
+		mt.mergeMetric(id, *data)
+	}
+}
+
+// Data implements payloadCreator.  The payload has the format of the
+// metric_data payload, with the attributes of each series added to its
+// metric spec.
+func (mt *dimensionalMetricTable) Data(agentRunID string, harvestStart time.Time) ([]byte, error) {
+	if 0 == len(mt.metrics) {
+		return nil, nil
+	}
+	estimatedBytesPerMetric := 192
+	buf := bytes.NewBuffer(make([]byte, 0, len(mt.metrics)*estimatedBytesPerMetric))
+	buf.WriteByte('[')
+	jsonx.AppendString(buf, agentRunID)
+	buf.WriteByte(',')
+	jsonx.AppendInt(buf, mt.metricPeriodStart.Unix())
+	buf.WriteByte(',')
+	jsonx.AppendInt(buf, harvestStart.Unix())
+	buf.WriteByte(',')
+
+	buf.WriteByte('[')
+	first := true
+	for id, data := range mt.metrics {
+		if first {
+			first = false
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`[{"name":`)
+		jsonx.AppendString(buf, id.Name)
+		buf.WriteString(`,"attributes":`)
+		buf.WriteString(id.Attributes)
+		buf.WriteString(`},`)
+		jsonx.AppendFloatArray(buf,
+			data.countSatisfied,
+			data.totalTolerated,
+			data.exclusiveFailed,
+			data.min,
+			data.max,
+			data.sumSquares)
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(']')
+
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// MergeIntoHarvest implements harvestable.
+func (mt *dimensionalMetricTable) MergeIntoHarvest(h *harvest) {
+	h.DimensionalMetrics.mergeFailed(mt)
+}
+
+// EndpointMethod implements payloadCreator.
+func (mt *dimensionalMetricTable) EndpointMethod() string {
+	return cmdDimensionalMetrics
+}
+
+// dimensionalMetric is a single value recorded with
+// Application.RecordDimensionalMetric.
+type dimensionalMetric struct {
+	Name       string
+	Attributes string
+	Value      float64
+}
+
+// MergeIntoHarvest implements harvestable.
+func (m dimensionalMetric) MergeIntoHarvest(h *harvest) {
+	if !h.DimensionalMetrics.addValue(m.Name, m.Attributes, m.Value) {
+		h.Metrics.addSingleCount(supportDimensionalMetricsDropped, forced)
+	}
+}
+
+// dimensionalMetricAttributesJSON validates the attributes of a dimensional
+// metric and returns their JSON object, with sorted keys.
+func dimensionalMetricAttributesJSON(attributes map[string]interface{}) (string, error) {
+	if len(attributes) > dimensionalMetricAttributeLimit {
+		return "", errDimensionalMetricAttributes
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	w := jsonFieldsWriter{buf: buf}
+	buf.WriteByte('{')
+	for _, key := range keys {
+		val, err := validateUserAttribute(key, attributes[key])
+		if nil != err {
+			return "", err
+		}
+		writeAttributeValueJSON(&w, key, val)
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/internal"
+)
+
+func dimensionalAttributes(t *testing.T, attributes map[string]interface{}) string {
+	js, err := dimensionalMetricAttributesJSON(attributes)
+	if nil != err {
+		t.Fatal(err)
+	}
+	return js
+}
+
+func TestEmptyDimensionalMetrics(t *testing.T) {
+	mt := newDimensionalMetricTable(20, 10, start)
+	js, err := mt.Data(`12345`, end)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if nil != js {
+		t.Error(string(js))
+	}
+}
+
+func TestDimensionalMetricsPayload(t *testing.T) {
+	mt := newDimensionalMetricTable(20, 10, start)
+	attrs := dimensionalAttributes(t, map[string]interface{}{
+		"region": "us-east-1",
+		"cached": true,
+		"shard":  3,
+	})
+	mt.addValue("queue.depth", attrs, 2)
+	mt.addValue("queue.depth", attrs, 4)
+
+	js, err := mt.Data(`12345`, end)
+	if nil != err {
+		t.Fatal(err)
+	}
+	expect := `["12345",1417136460,1417136520,[` +
+		`[{"name":"queue.depth","attributes":{"cached":true,"region":"us-east-1","shard":3}},[2,6,6,2,4,20]]` +
+		`]]`
+	if string(js) != expect {
+		t.Error(string(js))
+	}
+	if err := isValidJSON(js); nil != err {
+		t.Error(err)
+	}
+	if m := mt.EndpointMethod(); m != "dimensional_metric_data" {
+		t.Error(m)
+	}
+}
+
+func TestDimensionalMetricAttributesSorted(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		js := dimensionalAttributes(t, map[string]interface{}{"b": 1, "a": "x", "c": 1.5})
+		if js != `{"a":"x","b":1,"c":1.5}` {
+			t.Fatal(js)
+		}
+	}
+}
+
+func TestDimensionalMetricAttributesInvalid(t *testing.T) {
+	if _, err := dimensionalMetricAttributesJSON(map[string]interface{}{"zip": struct{}{}}); nil == err {
+		t.Error("expected error for invalid attribute value")
+	}
+	tooMany := make(map[string]interface{})
+	for i := 0; i <= dimensionalMetricAttributeLimit; i++ {
+		tooMany["attr"+strconv.Itoa(i)] = i
+	}
+	if _, err := dimensionalMetricAttributesJSON(tooMany); err != errDimensionalMetricAttributes {
+		t.Error(err)
+	}
+}
+
+func TestDimensionalMetricsCardinalityLimit(t *testing.T) {
+	h := newHarvest(start, testHarvestCfgr)
+	h.DimensionalMetrics = newDimensionalMetricTable(20, 2, start)
+	for _, user := range []string{"alice", "bob", "carol", "alice"} {
+		dimensionalMetric{
+			Name:       "logins",
+			Attributes: dimensionalAttributes(t, map[string]interface{}{"user": user}),
+			Value:      1,
+		}.MergeIntoHarvest(h)
+	}
+	dimensionalMetric{
+		Name:       "logouts",
+		Attributes: dimensionalAttributes(t, map[string]interface{}{"user": "carol"}),
+		Value:      1,
+	}.MergeIntoHarvest(h)
+
+	if n := len(h.DimensionalMetrics.metrics); n != 3 {
+		t.Error(n)
+	}
+	alice := dimensionalMetricID{Name: "logins", Attributes: `{"user":"alice"}`}
+	if data := h.DimensionalMetrics.metrics[alice]; nil == data || data.countSatisfied != 2 {
+		t.Error(data)
+	}
+	carol := dimensionalMetricID{Name: "logins", Attributes: `{"user":"carol"}`}
+	if _, ok := h.DimensionalMetrics.metrics[carol]; ok {
+		t.Error("series over the cardinality limit should be dropped")
+	}
+	expectMetrics(t, h.Metrics, []internal.WantMetric{
+		{Name: supportDimensionalMetricsDropped, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
+	})
+}
+
+func TestDimensionalMetricsTableSizeLimit(t *testing.T) {
+	mt := newDimensionalMetricTable(2, 10, start)
+	if !mt.addValue("one", `{}`, 1) || !mt.addValue("two", `{}`, 1) {
+		t.Fatal("series under the limits should be added")
+	}
+	if mt.addValue("three", `{}`, 1) {
+		t.Error("series over the table size should be dropped")
+	}
+	if !mt.addValue("one", `{}`, 1) {
+		t.Error("existing series should still aggregate")
+	}
+}
+
+func TestDimensionalMetricsMergeFailed(t *testing.T) {
+	failed := newDimensionalMetricTable(20, 10, start)
+	failed.addValue("one", `{"a":1}`, 2)
+
+	current := newDimensionalMetricTable(20, 10, end)
+	current.addValue("one", `{"a":1}`, 4)
+	current.mergeFailed(failed)
+
+	data := current.metrics[dimensionalMetricID{Name: "one", Attributes: `{"a":1}`}]
+	if nil == data || data.countSatisfied != 2 || data.totalTolerated != 6 {
+		t.Error(data)
+	}
+	if current.failedHarvests != 1 || !current.metricPeriodStart.Equal(start) {
+		t.Error(current.failedHarvests, current.metricPeriodStart)
+	}
+
+	failed.failedHarvests = failedMetricAttemptsLimit
+	next := newDimensionalMetricTable(20, 10, time.Now())
+	next.mergeFailed(failed)
+	if len(next.metrics) != 0 {
+		t.Error("metrics should be discarded after too many failed harvests")
+	}
+}
@@ -51,7 +51,11 @@ func injectDependencyModuleList(c *config, modules []*debug.Module) []string {
 	var modList []string
 
 	if c != nil && c.ModuleDependencyMetrics.Enabled {
+		max := c.ModuleDependencyMetrics.MaxModules
 		for _, module := range modules {
+			if max > 0 && len(modList) >= max {
+				break
+			}
 			if module != nil && includeModule(module.Path, c.ModuleDependencyMetrics.IgnoredPrefixes) {
 				modList = append(modList, fmt.Sprintf("%s(%s)", module.Path, module.Version))
 			}
@@ -61,19 +65,13 @@ func injectDependencyModuleList(c *config, modules []*debug.Module) []string {
 }
 
 func getDependencyModuleList(c *config) []string {
-	var modList []string
-
 	if c != nil && c.ModuleDependencyMetrics.Enabled {
 		info, ok := debug.ReadBuildInfo()
 		if info != nil && ok {
-			for _, module := range info.Deps {
-				if module != nil && includeModule(module.Path, c.ModuleDependencyMetrics.IgnoredPrefixes) {
-					modList = append(modList, fmt.Sprintf("%s(%s)", module.Path, module.Version))
-				}
-			}
+			return injectDependencyModuleList(c, info.Deps)
 		}
 	}
-	return modList
+	return nil
 }
 
 func includeModule(name string, ignoredModulePrefixes []string) bool {
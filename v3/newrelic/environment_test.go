@@ -55,9 +55,9 @@ func TestEnvironmentFields(t *testing.T) {
 func TestModuleDependency(t *testing.T) {
 	cfg := config{Config: defaultConfig()}
 
-	// check that the default is to be enabled
-	if !cfg.ModuleDependencyMetrics.Enabled {
-		t.Error("MDM should be enabled, was", cfg.ModuleDependencyMetrics.Enabled)
+	// check that the default is to be disabled
+	if cfg.ModuleDependencyMetrics.Enabled {
+		t.Error("MDM should be disabled, was", cfg.ModuleDependencyMetrics.Enabled)
 	}
 
 	// if disabled, we shouldn't get any data
@@ -101,6 +101,45 @@ func TestModuleDependency(t *testing.T) {
 	checkModuleListsMatch(t, expectedModules, env.Modules, "reduced module list")
 }
 
+func TestModuleInfo(t *testing.T) {
+	cfg := config{Config: defaultConfig()}
+	ConfigModuleInfo(0)(&cfg.Config)
+	if !cfg.ModuleDependencyMetrics.Enabled {
+		t.Error("MDM should be enabled by ConfigModuleInfo")
+	}
+
+	mockedModuleList := []*debug.Module{
+		{Path: "example/path/to/module", Version: "v1.2.3"},
+		{Path: "github.com/another/module", Version: "v0.1.2"},
+		{Path: "some/development/module", Version: "(develop)"},
+	}
+	modules := injectDependencyModuleList(&cfg, mockedModuleList)
+	if len(modules) != 3 || modules[0] != "example/path/to/module(v1.2.3)" {
+		t.Error("unexpected module list:", modules)
+	}
+
+	// the limit applies once the ignored modules are excluded
+	ConfigModuleInfo(1)(&cfg.Config)
+	cfg.ModuleDependencyMetrics.IgnoredPrefixes = []string{"exam"}
+	modules = injectDependencyModuleList(&cfg, mockedModuleList)
+	if len(modules) != 1 || modules[0] != "github.com/another/module(v0.1.2)" {
+		t.Error("unexpected capped module list:", modules)
+	}
+}
+
+func TestModuleInfoCollected(t *testing.T) {
+	cfg := config{Config: defaultConfig()}
+	ConfigModuleInfo(1)(&cfg.Config)
+	info, ok := debug.ReadBuildInfo()
+	if !ok || len(info.Deps) == 0 {
+		t.Skip("no module dependencies in the build info")
+	}
+	env := newEnvironment(&cfg)
+	if len(env.Modules) != 1 {
+		t.Error("expected a single module reported:", env.Modules)
+	}
+}
+
 func checkModuleListsMatch(t *testing.T, expected map[string]*debug.Module, actual []string, message string) {
 	if expected == nil {
 		t.Error(message, "expected list is nil")
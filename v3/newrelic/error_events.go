@@ -31,6 +31,7 @@ func (e *errorEvent) WriteJSON(buf *bytes.Buffer) {
 	if e.SpanID != "" {
 		w.stringField("spanId", e.SpanID)
 	}
+	addOptionalStringField(&w, errorGroupKeyAttr, e.GroupKey)
 
 	sharedTransactionIntrinsics(&e.txnEvent, &w)
 	sharedBetterCATIntrinsics(&e.txnEvent, &w)
@@ -62,6 +62,7 @@ type errorData struct {
 	Klass           string
 	SpanID          string
 	Expect          bool
+	GroupKey        string
 }
 
 // txnError combines error data with information about a transaction.  txnError is used for
@@ -114,7 +115,7 @@ func (h *tracedError) WriteJSON(buf *bytes.Buffer) {
 	buf.WriteByte(',')
 	buf.WriteString(`"intrinsics"`)
 	buf.WriteByte(':')
-	intrinsicsJSON(&h.txnEvent, buf, h.errorData.Expect)
+	intrinsicsJSON(&h.txnEvent, buf, h.errorData.Expect, h.errorData.GroupKey)
 	if nil != h.Stack {
 		buf.WriteByte(',')
 		buf.WriteString(`"stack_trace"`)
@@ -0,0 +1,40 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"errors"
+)
+
+// FeatureFlagEventType is the type of the custom events recorded by
+// Transaction.RecordFeatureFlag.
+const FeatureFlagEventType = "FeatureFlagEvaluation"
+
+// Attributes of the FeatureFlagEvaluation custom events, along with the
+// trace context.
+const (
+	featureFlagKeyAttr   = "flag.key"
+	featureFlagValueAttr = "flag.value"
+)
+
+// featureFlagsPerTransactionLimit is the maximum number of feature flag
+// evaluations recorded by a transaction.
+const featureFlagsPerTransactionLimit = 64
+
+var (
+	errFeatureFlagKey   = errors.New("feature flag key is required")
+	errFeatureFlagLimit = newEventLimitError("feature flags", featureFlagsPerTransactionLimit)
+)
+
+// featureFlagEventParams returns the attributes of the custom event
+// recording the evaluation of a feature flag.  The trace and span IDs are
+// omitted when empty.
+func featureFlagEventParams(key string, value interface{}, metadata TraceMetadata) eventParams {
+	params := eventParams{
+		featureFlagKeyAttr:   key,
+		featureFlagValueAttr: value,
+	}
+	params.addTraceMetadata(metadata)
+	return params
+}
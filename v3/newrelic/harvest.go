@@ -61,15 +61,16 @@ func (timer *harvestTimer) ready(now time.Time) (ready harvestTypes) {
 type harvest struct {
 	timer *harvestTimer
 
-	Metrics      *metricTable
-	ErrorTraces  harvestErrors
-	TxnTraces    *harvestTraces
-	SlowSQLs     *slowQueries
-	SpanEvents   *spanEvents
-	CustomEvents *customEvents
-	LogEvents    *logEvents
-	TxnEvents    *txnEvents
-	ErrorEvents  *errorEvents
+	Metrics            *metricTable
+	DimensionalMetrics *dimensionalMetricTable
+	ErrorTraces        harvestErrors
+	TxnTraces          *harvestTraces
+	SlowSQLs           *slowQueries
+	SpanEvents         *spanEvents
+	CustomEvents       *customEvents
+	LogEvents          *logEvents
+	TxnEvents          *txnEvents
+	ErrorEvents        *errorEvents
 }
 
 const (
@@ -121,10 +122,12 @@ func (h *harvest) Ready(now time.Time) *harvest {
 	// ensure that the metrics contain the event supportability metrics.
 	if 0 != types&harvestMetricsTraces {
 		ready.Metrics = h.Metrics
+		ready.DimensionalMetrics = h.DimensionalMetrics
 		ready.ErrorTraces = h.ErrorTraces
 		ready.SlowSQLs = h.SlowSQLs
 		ready.TxnTraces = h.TxnTraces
 		h.Metrics = newMetricTable(maxMetrics, now)
+		h.DimensionalMetrics = newDimensionalMetricTable(maxDimensionalMetrics, maxDimensionalMetricCardinality, now)
 		h.ErrorTraces = newHarvestErrors(maxHarvestErrors)
 		h.SlowSQLs = newSlowQueries(maxHarvestSlowSQLs)
 		h.TxnTraces = newHarvestTraces()
@@ -152,6 +155,9 @@ func (h *harvest) Payloads(splitLargeTxnEvents bool) (ps []payloadCreator) {
 	if nil != h.Metrics {
 		ps = append(ps, h.Metrics)
 	}
+	if nil != h.DimensionalMetrics {
+		ps = append(ps, h.DimensionalMetrics)
+	}
 	if nil != h.ErrorTraces {
 		ps = append(ps, h.ErrorTraces)
 	}
@@ -184,16 +190,17 @@ type harvestConfig struct {
 // newHarvest returns a new Harvest.
 func newHarvest(now time.Time, configurer harvestConfig) *harvest {
 	return &harvest{
-		timer:        newHarvestTimer(now, configurer.ReportPeriods),
-		Metrics:      newMetricTable(maxMetrics, now),
-		ErrorTraces:  newHarvestErrors(maxHarvestErrors),
-		TxnTraces:    newHarvestTraces(),
-		SlowSQLs:     newSlowQueries(maxHarvestSlowSQLs),
-		SpanEvents:   newSpanEvents(configurer.MaxSpanEvents),
-		CustomEvents: newCustomEvents(configurer.MaxCustomEvents),
-		LogEvents:    newLogEvents(configurer.CommonAttributes, configurer.LoggingConfig),
-		TxnEvents:    newTxnEvents(configurer.MaxTxnEvents),
-		ErrorEvents:  newErrorEvents(configurer.MaxErrorEvents),
+		timer:              newHarvestTimer(now, configurer.ReportPeriods),
+		Metrics:            newMetricTable(maxMetrics, now),
+		DimensionalMetrics: newDimensionalMetricTable(maxDimensionalMetrics, maxDimensionalMetricCardinality, now),
+		ErrorTraces:        newHarvestErrors(maxHarvestErrors),
+		TxnTraces:          newHarvestTraces(),
+		SlowSQLs:           newSlowQueries(maxHarvestSlowSQLs),
+		SpanEvents:         newSpanEvents(configurer.MaxSpanEvents),
+		CustomEvents:       newCustomEvents(configurer.MaxCustomEvents),
+		LogEvents:          newLogEvents(configurer.CommonAttributes, configurer.LoggingConfig),
+		TxnEvents:          newTxnEvents(configurer.MaxTxnEvents),
+		ErrorEvents:        newErrorEvents(configurer.MaxErrorEvents),
 	}
 }
 
@@ -263,6 +270,26 @@ type payloadCreator interface {
 	EndpointMethod() string
 }
 
+// payloadItems returns the number of data items of the payload, such as its
+// events, metrics, traces or errors.
+func payloadItems(p payloadCreator) int {
+	switch p := p.(type) {
+	case interface{ NumSaved() float64 }:
+		return int(p.NumSaved())
+	case *metricTable:
+		return len(p.metrics)
+	case *dimensionalMetricTable:
+		return len(p.metrics)
+	case harvestErrors:
+		return len(p)
+	case *harvestTraces:
+		return p.Len()
+	case *slowQueries:
+		return p.Len()
+	}
+	return 0
+}
+
 // createTxnMetrics creates metrics for a transaction.
 func createTxnMetrics(args *txnData, metrics *metricTable) {
 	withoutFirstSegment := removeFirstSegment(args.FinalName)
@@ -339,7 +366,11 @@ func createTxnMetrics(args *txnData, metrics *metricTable) {
 
 	// Queueing Metrics
 	if args.Queuing > 0 {
-		metrics.addDuration(queueMetric, "", args.Queuing, args.Queuing, forced)
+		if args.IsWeb {
+			metrics.addDuration(queueMetric, "", args.Queuing, args.Queuing, forced)
+		} else {
+			metrics.addDuration(workerQueueMetric, "", args.Queuing, args.Queuing, forced)
+		}
 	}
 }
 
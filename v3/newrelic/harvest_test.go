@@ -224,7 +224,7 @@ func TestCreateFinalMetricsTraceObserver(t *testing.T) {
 func TestEmptyPayloads(t *testing.T) {
 	h := newHarvest(time.Now(), testHarvestCfgr)
 	payloads := h.Payloads(true)
-	if len(payloads) != 9 {
+	if len(payloads) != 10 {
 		t.Error(len(payloads))
 	}
 	for _, p := range payloads {
@@ -355,6 +355,8 @@ func TestHarvestLogEventsReady(t *testing.T) {
 	expectMetrics(t, h.Metrics, []internal.WantMetric{
 		{Name: logsSeen, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
 		{Name: logsSeen + "/" + logEvent.severity, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
+		{Name: logsWithTrace, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
+		{Name: logsWithoutTrace, Scope: "", Forced: true, Data: []float64{0, 0, 0, 0, 0, 0}},
 		{Name: logsDropped, Scope: "", Forced: true, Data: []float64{0, 0, 0, 0, 0, 0}},
 	})
 }
@@ -531,7 +533,7 @@ func TestHarvestMetricsTracesReady(t *testing.T) {
 
 	ready := h.Ready(now.Add(61 * time.Second))
 	payloads := ready.Payloads(true)
-	if len(payloads) != 4 {
+	if len(payloads) != 5 {
 		t.Fatal(payloads)
 	}
 
@@ -891,10 +893,10 @@ func TestHarvestSplitTxnEvents(t *testing.T) {
 	payloadsWithSplit := h.Payloads(true)
 	payloadsWithoutSplit := h.Payloads(false)
 
-	if len(payloadsWithSplit) != 10 {
+	if len(payloadsWithSplit) != 11 {
 		t.Error(len(payloadsWithSplit))
 	}
-	if len(payloadsWithoutSplit) != 9 {
+	if len(payloadsWithoutSplit) != 10 {
 		t.Error(len(payloadsWithoutSplit))
 	}
 }
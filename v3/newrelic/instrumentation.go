@@ -4,7 +4,11 @@
 package newrelic
 
 import (
+	"crypto/tls"
 	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
 )
 
 // instrumentation.go contains helpers built on the lower level api.
@@ -117,22 +121,157 @@ func WrapHandleFunc(app *Application, pattern string, handler func(http.Response
 // provided (or http.DefaultTransport if none is provided).  The
 // http.RoundTripper will look for a Transaction in the request's context
 // (using FromContext).
-func NewRoundTripper(original http.RoundTripper) http.RoundTripper {
+func NewRoundTripper(original http.RoundTripper, options ...RoundTripperOption) http.RoundTripper {
 	if nil == original {
 		original = http.DefaultTransport
 	}
-	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
-		// The specification of http.RoundTripper requires that the request is never modified.
-		request = cloneRequest(request)
-		segment := StartExternalSegment(nil, request)
+	rt := &roundTripper{original: original}
+	for _, o := range options {
+		o(rt)
+	}
+	return rt
+}
 
-		response, err := original.RoundTrip(request)
+// RoundTripperOption configures the http.RoundTripper created by
+// NewRoundTripper.
+type RoundTripperOption func(*roundTripper)
 
-		segment.Response = response
-		segment.End()
+// Attributes recording the phases of external requests when
+// WithRoundTripperPhaseTimings is used.  Their values are durations in
+// milliseconds.
+const (
+	// SpanAttributeHTTPDNSMillis is the time spent resolving the host.
+	SpanAttributeHTTPDNSMillis = "http.dns_ms"
+	// SpanAttributeHTTPConnectMillis is the time spent opening the
+	// connection.
+	SpanAttributeHTTPConnectMillis = "http.connect_ms"
+	// SpanAttributeHTTPTLSMillis is the time spent in the TLS handshake.
+	SpanAttributeHTTPTLSMillis = "http.tls_ms"
+	// SpanAttributeHTTPTTFBMillis is the time from the start of the
+	// request to the first byte of the response.
+	SpanAttributeHTTPTTFBMillis = "http.ttfb_ms"
+)
 
-		return response, err
-	})
+// WithRoundTripperPhaseTimings records the duration of the phases of each
+// request, traced with httptrace.ClientTrace, as attributes of its external
+// segment: the host resolution in SpanAttributeHTTPDNSMillis, the connection
+// in SpanAttributeHTTPConnectMillis, the TLS handshake in
+// SpanAttributeHTTPTLSMillis and the time to the first byte of the response
+// in SpanAttributeHTTPTTFBMillis.  The phases skipped by requests reusing a
+// connection are not recorded.  It is disabled by default since tracing the
+// requests adds some overhead.  A ClientTrace already in the context of the
+// request is still called.
+//
+//	client.Transport = newrelic.NewRoundTripper(nil, newrelic.WithRoundTripperPhaseTimings())
+func WithRoundTripperPhaseTimings() RoundTripperOption {
+	return func(rt *roundTripper) { rt.phaseTimings = true }
+}
+
+type roundTripper struct {
+	original     http.RoundTripper
+	phaseTimings bool
+}
+
+func (rt *roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	// The specification of http.RoundTripper requires that the request is never modified.
+	request = cloneRequest(request)
+	segment := StartExternalSegment(nil, request)
+
+	var phases *requestPhases
+	if rt.phaseTimings && nil != segment.StartTime.thread {
+		phases = newRequestPhases()
+		request = request.WithContext(httptrace.WithClientTrace(request.Context(), phases.clientTrace()))
+	}
+
+	response, err := rt.original.RoundTrip(request)
+
+	phases.addAttributes(segment)
+	segment.Response = response
+	segment.End()
+
+	return response, err
+}
+
+// requestPhases records the durations of the phases of a request.  The
+// httptrace.ClientTrace hooks may be called concurrently, for instance when
+// dialing several addresses.
+type requestPhases struct {
+	sync.Mutex
+	start     time.Time
+	starts    map[string]time.Time
+	durations map[string]time.Duration
+}
+
+func newRequestPhases() *requestPhases {
+	return &requestPhases{
+		start:     time.Now(),
+		starts:    make(map[string]time.Time),
+		durations: make(map[string]time.Duration),
+	}
+}
+
+func (p *requestPhases) begin(attr string) {
+	p.Lock()
+	defer p.Unlock()
+	p.starts[attr] = time.Now()
+}
+
+func (p *requestPhases) end(attr string) {
+	p.Lock()
+	defer p.Unlock()
+	if start, ok := p.starts[attr]; ok {
+		p.durations[attr] = time.Since(start)
+	}
+}
+
+func (p *requestPhases) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { p.begin(SpanAttributeHTTPDNSMillis) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { p.end(SpanAttributeHTTPDNSMillis) },
+		ConnectStart:      func(string, string) { p.begin(SpanAttributeHTTPConnectMillis) },
+		ConnectDone:       func(string, string, error) { p.end(SpanAttributeHTTPConnectMillis) },
+		TLSHandshakeStart: func() { p.begin(SpanAttributeHTTPTLSMillis) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { p.end(SpanAttributeHTTPTLSMillis) },
+		GotFirstResponseByte: func() {
+			p.Lock()
+			defer p.Unlock()
+			p.durations[SpanAttributeHTTPTTFBMillis] = time.Since(p.start)
+		},
+	}
+}
+
+// addAttributes adds the durations recorded to the segment.
+func (p *requestPhases) addAttributes(segment *ExternalSegment) {
+	if nil == p {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	for attr, d := range p.durations {
+		segment.AddAttribute(attr, float64(d)/float64(time.Millisecond))
+	}
+}
+
+// InstrumentClient replaces the client's Transport with one created by
+// NewRoundTripper, wrapping the existing Transport (or http.DefaultTransport
+// if none is set).  The client is modified in place, so take care when
+// passing shared clients such as http.DefaultClient.  Clients whose Transport
+// was already created by NewRoundTripper are left unchanged, so that requests
+// are not recorded twice.
+//
+//	client := &http.Client{Timeout: 5 * time.Second}
+//	newrelic.InstrumentClient(client)
+//
+// As with NewRoundTripper, the Transaction is found in the request's context,
+// and the options configure the http.RoundTripper created.
+func InstrumentClient(client *http.Client, options ...RoundTripperOption) {
+	if nil == client {
+		return
+	}
+	if _, ok := client.Transport.(*roundTripper); ok {
+		return
+	}
+	client.Transport = NewRoundTripper(client.Transport, options...)
 }
 
 // cloneRequest mimics implementation of
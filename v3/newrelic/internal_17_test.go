@@ -6,6 +6,8 @@ package newrelic
 import (
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/newrelic/go-agent/v3/internal"
@@ -259,3 +261,114 @@ func TestRoundTripperRace(t *testing.T) {
 	go client.Do(req)
 	go client.Do(req)
 }
+
+func TestRoundTripperPhaseTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	// The host is resolved when requesting localhost rather than the
+	// address of the server.  The certificate of the server is valid for
+	// example.com.
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+	client := &http.Client{Transport: NewRoundTripper(transport, WithRoundTripperPhaseTimings())}
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(RequestWithTransactionContext(req, txn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	txn.End()
+
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId":  internal.MatchAnything,
+				"name":      "External/" + req.URL.Host + "/http/GET",
+				"category":  "http",
+				"component": "http",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{
+				SpanAttributeHTTPDNSMillis:     internal.MatchAnything,
+				SpanAttributeHTTPConnectMillis: internal.MatchAnything,
+				SpanAttributeHTTPTLSMillis:     internal.MatchAnything,
+				SpanAttributeHTTPTTFBMillis:    internal.MatchAnything,
+			},
+			AgentAttributes: map[string]interface{}{
+				"http.url":        url,
+				"http.method":     "GET",
+				"http.statusCode": 200,
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"transaction.name": "OtherTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestRoundTripperPhaseTimingsDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewRoundTripper(nil)}
+
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(RequestWithTransactionContext(req, txn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	txn.End()
+
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId":  internal.MatchAnything,
+				"name":      "External/" + req.URL.Host + "/http/GET",
+				"category":  "http",
+				"component": "http",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{
+				"http.url":        server.URL,
+				"http.method":     "GET",
+				"http.statusCode": 200,
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"transaction.name": "OtherTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
@@ -46,6 +46,14 @@ type app struct {
 	// merge the data into the next harvest.
 	shutdownStarted  chan struct{}
 	shutdownComplete chan struct{}
+	// shutdownResult is set by the processor goroutine before closing
+	// shutdownComplete, and must not be read before.
+	shutdownResult ShutdownResult
+
+	// discarded holds the data consumed while the application is not
+	// connected, which is reported as dropped by the shutdown.
+	discardedLock sync.Mutex
+	discarded     *harvest
 
 	// Sends to these channels should not occur without a <-shutdownStarted
 	// select option to prevent deadlock.
@@ -64,13 +72,19 @@ type app struct {
 	err error
 
 	serverless *serverlessHarvest
+
+	// supportability holds the supportability metrics harvested, for
+	// Application.PrometheusHandler.
+	supportability supportabilityMetrics
 }
 
-func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) {
+// doHarvest sends the payloads of the harvest and returns the number of data
+// items sent and the number of data items which could not be sent.
+func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) (flushed, dropped int) {
 	h.CreateFinalMetrics(run, app.getObserver())
 
 	payloads := h.Payloads(app.config.DistributedTracer.Enabled)
-	for _, p := range payloads {
+	for i, p := range payloads {
 		cmd := p.EndpointMethod()
 		var data []byte
 
@@ -93,6 +107,7 @@ func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) {
 				"cmd":   cmd,
 				"error": err.Error(),
 			})
+			dropped += payloadItems(p)
 			continue
 		}
 		if data == nil {
@@ -115,6 +130,10 @@ func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) {
 			case app.collectorErrorChan <- resp:
 			case <-app.shutdownStarted:
 			}
+			// The remaining payloads are not sent.
+			for _, p := range payloads[i:] {
+				dropped += payloadItems(p)
+			}
 			return
 		}
 
@@ -124,12 +143,19 @@ func (app *app) doHarvest(h *harvest, harvestStart time.Time, run *appRun) {
 				"error":       resp.Err.Error(),
 				"retain_data": resp.ShouldSaveHarvestData(),
 			})
+			dropped += payloadItems(p)
+		} else {
+			flushed += payloadItems(p)
+			if mt, ok := p.(*metricTable); ok {
+				app.supportability.record(mt)
+			}
 		}
 
 		if resp.ShouldSaveHarvestData() {
 			app.Consume(run.Reply.RunID, p)
 		}
 	}
+	return
 }
 
 func (app *app) connectRoutine() {
@@ -273,9 +299,13 @@ func (app *app) process() {
 						done = true
 					}
 				}
-				app.doHarvest(h, time.Now(), run)
+				flushed, dropped := app.doHarvest(h, time.Now(), run)
+				app.shutdownResult.Flushed = flushed
+				app.shutdownResult.Dropped = dropped
 			}
+			app.shutdownResult.Dropped += app.discardedItems()
 
+			app.shutdownResult.Completed = true
 			close(app.shutdownComplete)
 			app.setObserver(nil)
 			return
@@ -325,15 +355,15 @@ func (app *app) process() {
 	}
 }
 
-func (app *app) Shutdown(timeout time.Duration) {
+func (app *app) Shutdown(timeout time.Duration) ShutdownResult {
 	if nil == app {
-		return
+		return ShutdownResult{Completed: true}
 	}
 	if !app.config.Enabled {
-		return
+		return ShutdownResult{Completed: true}
 	}
 	if app.config.ServerlessMode.Enabled {
-		return
+		return ShutdownResult{Completed: true}
 	}
 
 	select {
@@ -342,16 +372,22 @@ func (app *app) Shutdown(timeout time.Duration) {
 	}
 
 	// Block until shutdown is done or timeout occurs.
+	var result ShutdownResult
 	t := time.NewTimer(timeout)
 	select {
 	case <-app.shutdownComplete:
+		result = app.shutdownResult
 	case <-t.C:
 	}
 	t.Stop()
 
 	app.Info("application shutdown", map[string]interface{}{
-		"app": app.config.AppName,
+		"app":       app.config.AppName,
+		"completed": result.Completed,
+		"flushed":   result.Flushed,
+		"dropped":   result.Dropped,
 	})
+	return result
 }
 
 func runSampler(app *app, period time.Duration) {
@@ -539,34 +575,57 @@ var (
 
 // RecordCustomEvent implements newrelic.Application's RecordCustomEvent.
 func (app *app) RecordCustomEvent(eventType string, params map[string]interface{}) error {
+	return app.recordCustomEvent(eventType, params, time.Now())
+}
+
+func (app *app) RecordCustomEventWithTimestamp(eventType string, params map[string]interface{}, timestamp time.Time) error {
+	if err := eventTimestampValidate(timestamp, time.Now()); nil != err {
+		return newInvalidEventError("", err)
+	}
+	return app.recordCustomEvent(eventType, params, timestamp)
+}
+
+func (app *app) recordCustomEvent(eventType string, params map[string]interface{}, timestamp time.Time) error {
 	if nil == app {
 		return nil
 	}
+	event, run, err := app.newCustomEvent(eventType, params, timestamp)
+	if nil != err {
+		return err
+	}
+
+	app.Consume(run.Reply.RunID, event)
+
+	return nil
+}
+
+// newCustomEvent creates a custom event, once the configuration, the server
+// and the security policies have been checked to allow it, and returns it
+// along with the run it is recorded in.
+func (app *app) newCustomEvent(eventType string, params map[string]interface{}, timestamp time.Time) (*customEvent, *appRun, error) {
 	if app.config.Config.HighSecurity {
-		return errHighSecurityEnabled
+		return nil, nil, errHighSecurityEnabled
 	}
 
 	if !app.config.CustomInsightsEvents.Enabled {
-		return errCustomEventsDisabled
+		return nil, nil, errCustomEventsDisabled
 	}
 
-	event, e := createCustomEvent(eventType, params, time.Now())
+	event, e := createCustomEvent(eventType, params, timestamp)
 	if nil != e {
-		return e
+		return nil, nil, e
 	}
 
 	run, _ := app.getState()
 	if !run.Reply.CollectCustomEvents {
-		return errCustomEventsRemoteDisabled
+		return nil, nil, errCustomEventsRemoteDisabled
 	}
 
 	if !run.Reply.SecurityPolicies.CustomEvents.Enabled() {
-		return errSecurityPolicy
+		return nil, nil, errSecurityPolicy
 	}
 
-	app.Consume(run.Reply.RunID, event)
-
-	return nil
+	return event, run, nil
 }
 
 var (
@@ -601,6 +660,37 @@ func (app *app) RecordCustomMetric(name string, value float64) error {
 	return nil
 }
 
+// RecordDimensionalMetric implements newrelic.Application's
+// RecordDimensionalMetric.
+func (app *app) RecordDimensionalMetric(name string, value float64, attributes map[string]interface{}) error {
+	if nil == app {
+		return nil
+	}
+	if app.config.ServerlessMode.Enabled {
+		return errDimensionalMetricServerless
+	}
+	if math.IsNaN(value) {
+		return errMetricNaN
+	}
+	if math.IsInf(value, 0) {
+		return errMetricInf
+	}
+	if "" == name {
+		return errMetricNameEmpty
+	}
+	attrs, err := dimensionalMetricAttributesJSON(attributes)
+	if nil != err {
+		return err
+	}
+	run, _ := app.getState()
+	app.Consume(run.Reply.RunID, dimensionalMetric{
+		Name:       name,
+		Attributes: attrs,
+		Value:      value,
+	})
+	return nil
+}
+
 var (
 	errAppLoggingDisabled = errors.New("log data can not be recorded when application logging is disabled")
 )
@@ -639,6 +729,7 @@ func (app *app) Consume(id internal.AgentRunID, data harvestable) {
 	}
 
 	if "" == id {
+		app.discard(data)
 		return
 	}
 
@@ -648,6 +739,40 @@ func (app *app) Consume(id internal.AgentRunID, data harvestable) {
 	}
 }
 
+// discard keeps the data consumed while the application is not connected, up
+// to the harvest limits, to count it as dropped once the application is shut
+// down.
+func (app *app) discard(data harvestable) {
+	if !app.config.Enabled || app.config.ServerlessMode.Enabled {
+		return
+	}
+	select {
+	case <-app.shutdownStarted:
+		return
+	default:
+	}
+
+	app.discardedLock.Lock()
+	defer app.discardedLock.Unlock()
+
+	if nil == app.discarded {
+		app.discarded = newHarvest(time.Now(), app.placeholderRun.harvestConfig)
+	}
+	data.MergeIntoHarvest(app.discarded)
+}
+
+// discardedItems returns the number of data items discarded because the
+// application was not connected.
+func (app *app) discardedItems() (items int) {
+	app.discardedLock.Lock()
+	defer app.discardedLock.Unlock()
+
+	for _, p := range app.discarded.Payloads(false) {
+		items += payloadItems(p)
+	}
+	return
+}
+
 func (app *app) ExpectCustomEvents(t internal.Validator, want []internal.WantEvent) {
 	expectCustomEvents(extendValidator(t, "custom events"), app.testHarvest.CustomEvents, want)
 }
@@ -6,6 +6,10 @@ package newrelic
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -148,3 +152,142 @@ func TestRecordLog(t *testing.T) {
 		},
 	})
 }
+
+func TestRecordLogTraceCorrelationMetrics(t *testing.T) {
+	testApp := newTestApp(
+		sampleEverythingReplyFn,
+		configTestAppLogFn,
+	)
+
+	testApp.Application.RecordLog(LogData{
+		Severity: "Info",
+		Message:  "outside of a transaction",
+	})
+	txn := testApp.Application.StartTransaction("hello")
+	txn.RecordLog(LogData{Severity: "Info", Message: "first"})
+	txn.RecordLog(LogData{Severity: "Info", Message: "second"})
+	txn.End()
+
+	h := testApp.Application.Private.(*app).testHarvest
+	h.LogEvents.RecordLoggingMetrics(h.Metrics)
+	expectMetricsPresent(t, h.Metrics, []internal.WantMetric{
+		{Name: logsSeen, Scope: "", Forced: true, Data: []float64{3, 0, 0, 0, 0, 0}},
+		{Name: logsWithTrace, Scope: "", Forced: true, Data: []float64{2, 0, 0, 0, 0, 0}},
+		{Name: logsWithoutTrace, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
+	})
+}
+
+// shutdownTestApp creates a connected application whose collector fails the
+// harvest requests of the failMethod command with the failStatus status code.
+// It returns the application and a function returning the harvest commands
+// received.
+func shutdownTestApp(t *testing.T, failMethod string, failStatus int) (*Application, func() []string) {
+	var mu sync.Mutex
+	var harvested []string
+	app, err := NewApplication(
+		ConfigAppName("my app"),
+		ConfigLicense(testLicenseKey),
+		func(cfg *Config) {
+			cfg.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				body := `{"return_value":null}`
+				status := 200
+				switch method := r.URL.Query().Get("method"); method {
+				case cmdPreconnect:
+					body = `{"return_value":{"redirect_host":"collector.newrelic.com"}}`
+				case cmdConnect:
+					body = `{"return_value":{"agent_run_id":"my_agent_run_id"}}`
+				default:
+					mu.Lock()
+					harvested = append(harvested, method)
+					mu.Unlock()
+					if method == failMethod {
+						status = failStatus
+					}
+				}
+				return &http.Response{
+					StatusCode: status,
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+				}, nil
+			})
+		},
+	)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := app.WaitForConnection(5 * time.Second); nil != err {
+		t.Fatal(err)
+	}
+	return app, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), harvested...)
+	}
+}
+
+func TestShutdownWithResult(t *testing.T) {
+	app, harvested := shutdownTestApp(t, "", 0)
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	result := app.ShutdownWithResult(5 * time.Second)
+
+	cmds := harvested()
+	// The metrics are flushed along with the custom event.
+	if !result.Completed || result.Dropped != 0 || result.Flushed < 2 {
+		t.Error(result, cmds)
+	}
+	sentEvents := false
+	for _, cmd := range cmds {
+		sentEvents = sentEvents || cmd == cmdCustomEvents
+	}
+	if !sentEvents {
+		t.Error("custom events were not flushed", cmds)
+	}
+}
+
+func TestShutdownWithResultDropped(t *testing.T) {
+	app, _ := shutdownTestApp(t, cmdCustomEvents, 503)
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	result := app.ShutdownWithResult(5 * time.Second)
+
+	// Each custom event is a data item of the payload that failed.
+	if !result.Completed || result.Dropped != 2 || result.Flushed == 0 {
+		t.Error(result)
+	}
+}
+
+func TestShutdownWithResultNeverConnected(t *testing.T) {
+	app, err := NewApplication(
+		ConfigAppName("my app"),
+		ConfigLicense(testLicenseKey),
+		func(cfg *Config) {
+			cfg.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return nil, errors.New("unreachable collector")
+			})
+		},
+	)
+	if nil != err {
+		t.Fatal(err)
+	}
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": "zap"})
+	result := app.ShutdownWithResult(5 * time.Second)
+
+	if result != (ShutdownResult{Completed: true, Dropped: 3}) {
+		t.Error(result)
+	}
+}
+
+func TestShutdownWithResultNotEnabled(t *testing.T) {
+	var nilApp *Application
+	if result := nilApp.ShutdownWithResult(time.Second); result != (ShutdownResult{Completed: true}) {
+		t.Error(result)
+	}
+	app, err := NewApplication(ConfigAppName("my app"), ConfigEnabled(false))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if result := app.ShutdownWithResult(time.Second); result != (ShutdownResult{Completed: true}) {
+		t.Error(result)
+	}
+}
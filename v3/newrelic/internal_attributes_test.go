@@ -618,6 +618,137 @@ func TestRequestURIExcluded(t *testing.T) {
 	}})
 }
 
+func TestConfigAttributesExclude(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		enableBetterCAT(cfg)
+		cfg.TransactionTracer.Threshold.IsApdexFailing = false
+		cfg.TransactionTracer.Threshold.Duration = 0
+		// A destination include does not bring the attribute back.
+		cfg.TransactionEvents.Attributes.Include = []string{AttributeRequestURI}
+		ConfigAttributesExclude(AttributeRequestURI)(cfg)
+	}
+	app := testApp(distributedTracingReplyFields, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	req, err := http.NewRequest("GET", "https://example.com/reset/secret-token", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	// The attribute is set both by the agent, from the request, and by
+	// an integration adding it explicitly.
+	txn.SetWebRequestHTTP(req)
+	txn.AddAttribute(AttributeRequestURI, "/reset/secret-token")
+	txn.NoticeError(errors.New("zap"))
+	txn.End()
+
+	agentAttributes := map[string]interface{}{
+		AttributeRequestMethod: "GET",
+		AttributeRequestHost:   "example.com",
+	}
+	userAttributes := map[string]interface{}{}
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/hello",
+			"nr.apdexPerfZone": internal.MatchAnything,
+			"guid":             internal.MatchAnything,
+			"priority":         internal.MatchAnything,
+			"sampled":          internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+		},
+		AgentAttributes: agentAttributes,
+		UserAttributes:  userAttributes,
+	}})
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName:         "WebTransaction/Go/hello",
+		Msg:             "zap",
+		Klass:           "*errors.errorString",
+		AgentAttributes: agentAttributes,
+		UserAttributes:  userAttributes,
+	}})
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName:      "WebTransaction/Go/hello",
+		NumSegments:     0,
+		AgentAttributes: agentAttributes,
+		UserAttributes:  userAttributes,
+	}})
+	app.ExpectSpanEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "WebTransaction/Go/hello",
+			"transaction.name": "WebTransaction/Go/hello",
+			"category":         "generic",
+			"nr.entryPoint":    true,
+			"sampled":          true,
+			"priority":         internal.MatchAnything,
+			"guid":             internal.MatchAnything,
+			"transactionId":    internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+		},
+		AgentAttributes: map[string]interface{}{
+			AttributeRequestMethod: "GET",
+			AttributeRequestHost:   "example.com",
+			"error.class":          "*errors.errorString",
+			"error.message":        "zap",
+		},
+		UserAttributes: userAttributes,
+	}})
+}
+
+func TestConfigAttributeDestinations(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		enableBetterCAT(cfg)
+		cfg.TransactionTracer.Threshold.IsApdexFailing = false
+		cfg.TransactionTracer.Threshold.Duration = 0
+		ConfigAttributeDestinations("order.items", AttributeDestinationErrors)(cfg)
+	}
+	app := testApp(distributedTracingReplyFields, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	txn.AddAttribute("order.items", 3)
+	txn.AddAttribute("order.id", "o-123")
+	txn.NoticeError(errors.New("zap"))
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/hello",
+			"error":    true,
+			"guid":     internal.MatchAnything,
+			"priority": internal.MatchAnything,
+			"sampled":  internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{"order.id": "o-123"},
+	}})
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName:        "OtherTransaction/Go/hello",
+		Msg:            "zap",
+		Klass:          "*errors.errorString",
+		UserAttributes: map[string]interface{}{"order.id": "o-123", "order.items": 3},
+	}})
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName:     "OtherTransaction/Go/hello",
+		NumSegments:    0,
+		UserAttributes: map[string]interface{}{"order.id": "o-123"},
+	}})
+	app.ExpectSpanEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "OtherTransaction/Go/hello",
+			"transaction.name": "OtherTransaction/Go/hello",
+			"category":         "generic",
+			"nr.entryPoint":    true,
+			"sampled":          true,
+			"priority":         internal.MatchAnything,
+			"guid":             internal.MatchAnything,
+			"transactionId":    internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+		},
+		AgentAttributes: map[string]interface{}{
+			"error.class":   "*errors.errorString",
+			"error.message": "zap",
+		},
+		UserAttributes: map[string]interface{}{"order.id": "o-123"},
+	}})
+}
+
 func TestMessageAttributes(t *testing.T) {
 	// test that adding message attributes as agent attributes filters them,
 	// but as user attributes does not filter them.
@@ -838,6 +969,183 @@ func TestAddSpanAttr_MessageProducerSegment(t *testing.T) {
 	})
 }
 
+func TestTransactionAddSpanAttribute(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("txn")
+	txn.AddSpanAttribute("root-attr", "root")
+	seg := txn.StartSegment("segment")
+	txn.AddSpanAttribute("segment-attr", 1)
+	seg.End()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/txn",
+			"guid":     internal.MatchAnything,
+			"priority": internal.MatchAnything,
+			"sampled":  internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+		},
+		AgentAttributes: nil,
+		UserAttributes:  map[string]interface{}{},
+	}})
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":          "Custom/segment",
+				"sampled":       true,
+				"category":      "generic",
+				"priority":      internal.MatchAnything,
+				"guid":          internal.MatchAnything,
+				"transactionId": internal.MatchAnything,
+				"traceId":       internal.MatchAnything,
+				"parentId":      internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"segment-attr": 1,
+			},
+			AgentAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"transaction.name": "OtherTransaction/Go/txn",
+				"name":             "OtherTransaction/Go/txn",
+				"sampled":          true,
+				"category":         "generic",
+				"priority":         internal.MatchAnything,
+				"guid":             internal.MatchAnything,
+				"transactionId":    internal.MatchAnything,
+				"nr.entryPoint":    true,
+				"traceId":          internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"root-attr": "root",
+			},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestTransactionAddSpanAttributeInvalid(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("txn")
+	txn.AddSpanAttribute("invalid", struct{}{})
+	app.expectSingleLoggedError(t, "unable to add span attribute", map[string]interface{}{
+		"reason": "attribute 'invalid' value of type struct {} is invalid",
+	})
+	txn.End()
+	txn.AddSpanAttribute("ended", 1)
+}
+
+func TestTransactionAddSpanAttributeHighSecurity(t *testing.T) {
+	cfgFn := func(cfg *Config) {
+		enableBetterCAT(cfg)
+		cfg.HighSecurity = true
+	}
+	app := testApp(distributedTracingReplyFields, cfgFn, t)
+	txn := app.StartTransaction("txn")
+	txn.AddSpanAttribute("root-attr", "root")
+	app.expectSingleLoggedError(t, "unable to add span attribute", map[string]interface{}{
+		"reason": errHighSecurityEnabled.Error(),
+	})
+	txn.End()
+}
+
+func TestWithWorkerID(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("txn", WithWorkerID("worker-7"))
+	seg := txn.StartSegment("segment")
+	seg.End()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/txn",
+			"guid":     internal.MatchAnything,
+			"priority": internal.MatchAnything,
+			"sampled":  internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+		},
+		AgentAttributes: nil,
+		UserAttributes: map[string]interface{}{
+			WorkerIDAttribute: "worker-7",
+		},
+	}})
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":          "Custom/segment",
+				"sampled":       true,
+				"category":      "generic",
+				"priority":      internal.MatchAnything,
+				"guid":          internal.MatchAnything,
+				"transactionId": internal.MatchAnything,
+				"traceId":       internal.MatchAnything,
+				"parentId":      internal.MatchAnything,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"transaction.name": "OtherTransaction/Go/txn",
+				"name":             "OtherTransaction/Go/txn",
+				"sampled":          true,
+				"category":         "generic",
+				"priority":         internal.MatchAnything,
+				"guid":             internal.MatchAnything,
+				"transactionId":    internal.MatchAnything,
+				"nr.entryPoint":    true,
+				"traceId":          internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				WorkerIDAttribute: "worker-7",
+			},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestWithWorkerIDSetOption(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = false
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("txn")
+	txn.SetOption(WithWorkerID("worker-3"))
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name": "OtherTransaction/Go/txn",
+		},
+		AgentAttributes: map[string]interface{}{},
+		UserAttributes: map[string]interface{}{
+			WorkerIDAttribute: "worker-3",
+		},
+	}})
+}
+
+func TestWithWorkerIDHighSecurity(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = false
+		cfg.HighSecurity = true
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("txn", WithWorkerID("worker-7"))
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name": "OtherTransaction/Go/txn",
+		},
+		AgentAttributes: map[string]interface{}{},
+		UserAttributes:  map[string]interface{}{},
+	}})
+}
+
 func TestAddSpanAttr_ExternalSegment(t *testing.T) {
 	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
 	txn := app.StartTransaction("txn")
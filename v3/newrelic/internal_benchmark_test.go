@@ -5,6 +5,7 @@ package newrelic
 
 import (
 	"net/http"
+	"sync"
 	"testing"
 )
 
@@ -165,6 +166,67 @@ func BenchmarkExternalSegment(b *testing.B) {
 	}
 }
 
+// benchmarkExternalFanout is the number of concurrent external calls made by
+// each transaction of the fan-out benchmarks.
+const benchmarkExternalFanout = 16
+
+func benchmarkExternalFanoutRequests() []*http.Request {
+	requests := make([]*http.Request, benchmarkExternalFanout)
+	for i := range requests {
+		requests[i], _ = http.NewRequest("GET", "http://example.com/", nil)
+	}
+	return requests
+}
+
+// BenchmarkExternalSegmentFanout starts the segments of concurrent calls with
+// a goroutine of the transaction and StartExternalSegment each, as a control
+// against BenchmarkStartExternalSegmentsFanout.
+func BenchmarkExternalSegmentFanout(b *testing.B) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, b)
+	requests := benchmarkExternalFanoutRequests()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			txn := app.StartTransaction("my txn")
+			var wg sync.WaitGroup
+			for _, req := range requests {
+				wg.Add(1)
+				go func(txn *Transaction, req *http.Request) {
+					defer wg.Done()
+					StartExternalSegment(txn, req).End()
+				}(txn.NewGoroutine(), req)
+			}
+			wg.Wait()
+			txn.End()
+		}
+	})
+}
+
+func BenchmarkStartExternalSegmentsFanout(b *testing.B) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, b)
+	requests := benchmarkExternalFanoutRequests()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			txn := app.StartTransaction("my txn")
+			var wg sync.WaitGroup
+			for _, s := range StartExternalSegments(txn, requests) {
+				wg.Add(1)
+				go func(s *ExternalSegment) {
+					defer wg.Done()
+					s.End()
+				}(s)
+			}
+			wg.Wait()
+			txn.End()
+		}
+	})
+}
+
 func BenchmarkTxnWithSegment(b *testing.B) {
 	app := testApp(nil, nil, b)
 
@@ -5,6 +5,7 @@ package newrelic
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/newrelic/go-agent/v3/internal"
@@ -174,3 +175,60 @@ func BenchmarkBrowserTimingHeaderSuccess(b *testing.B) {
 		hdr.WithTags()
 	}
 }
+
+func TestBrowserTimingHeaderCacheStaticHeader(t *testing.T) {
+	cacheStaticHeader := func(cfg *Config) {
+		cfg.BrowserMonitoring.CacheStaticHeader = true
+	}
+	replyfn := func(reply *internal.ConnectReply) {
+		browserReplyFields(reply)
+		reply.Beacon = "<beacon>"
+	}
+	app := testApp(replyfn, cacheStaticHeader, t)
+	txn := app.StartTransaction("hello")
+	txn.AddAttribute("zip", "zap")
+	hdr := txn.BrowserTimingHeader()
+	app.expectNoLoggedErrors(t)
+
+	if nil == hdr.static {
+		t.Fatal("static header not cached")
+	}
+	cached := hdr.WithTags()
+	hdr.static = nil
+	if uncached := hdr.WithTags(); string(cached) != string(uncached) {
+		t.Errorf("cached header does not match: expected %s; got %s", uncached, cached)
+	}
+
+	other := app.StartTransaction("other").BrowserTimingHeader()
+	run, _ := app.app.getState()
+	if other.static != run.browserStaticHeader() {
+		t.Error("static header not shared between transactions")
+	}
+}
+
+func TestBrowserStaticHeaderNewRun(t *testing.T) {
+	reply := internal.ConnectReplyDefaults()
+	browserReplyFields(reply)
+	cfg := config{Config: defaultConfig()}
+	cfg.License = testLicenseKey
+	cfg.BrowserMonitoring.CacheStaticHeader = true
+	run := newAppRun(cfg, reply)
+	if hdr := run.browserStaticHeader(); string(hdr.head) != `loaderwindow.NREUM||(NREUM={});NREUM.info={"beacon":"beacon","licenseKey":"key","applicationID":"app","transactionName":` {
+		t.Errorf("unexpected head: %s", hdr.head)
+	}
+
+	// A reconnect creates a new run, so changes to the connect reply and
+	// config are not hidden by the cache.
+	reply = internal.ConnectReplyDefaults()
+	browserReplyFields(reply)
+	reply.AgentLoader = "new-loader"
+	cfg.License = "0123456789012345678901234567890123456789"
+	run = newAppRun(cfg, reply)
+	hdr := run.browserStaticHeader()
+	if !strings.HasPrefix(string(hdr.head), "new-loader") {
+		t.Errorf("unexpected head: %s", hdr.head)
+	}
+	if string(hdr.encodingKey) != "0123456789012" {
+		t.Errorf("unexpected encoding key: %s", hdr.encodingKey)
+	}
+}
@@ -126,3 +126,52 @@ func TestNewRoundTripperNilTransaction(t *testing.T) {
 		},
 	})
 }
+
+func TestInstrumentClient(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myTxn")
+
+	client := &http.Client{}
+	client.Transport = roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+		}, nil
+	})
+	InstrumentClient(client)
+	// A second call must not wrap the transport again.
+	InstrumentClient(client)
+	if _, ok := client.Transport.(*roundTripper).original.(*roundTripper); ok {
+		t.Error("transport wrapped twice")
+	}
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	req = RequestWithTransactionContext(req, txn)
+	client.Do(req)
+	txn.End()
+
+	scope := "OtherTransaction/Go/myTxn"
+	app.ExpectMetrics(t, []internal.WantMetric{
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/all", Scope: "", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/allOther", Scope: "", Forced: false, Data: nil},
+		{Name: "External/all", Scope: "", Forced: true, Data: nil},
+		{Name: "External/allOther", Scope: "", Forced: true, Data: nil},
+		{Name: "External/example.com/all", Scope: "", Forced: false, Data: nil},
+		{Name: "External/example.com/http/GET", Scope: scope, Forced: false, Data: nil},
+		{Name: "OtherTransaction/Go/myTxn", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransaction/all", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime", Scope: "", Forced: true, Data: nil},
+		{Name: "OtherTransactionTotalTime/Go/myTxn", Scope: "", Forced: false, Data: nil},
+	})
+}
+
+func TestInstrumentClientNilTransport(t *testing.T) {
+	client := &http.Client{}
+	InstrumentClient(client)
+	rt, ok := client.Transport.(*roundTripper)
+	if !ok {
+		t.Fatalf("unexpected transport %T", client.Transport)
+	}
+	if rt.original != http.DefaultTransport {
+		t.Error("default transport not preserved")
+	}
+	InstrumentClient(nil)
+}
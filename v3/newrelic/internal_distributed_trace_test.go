@@ -1566,6 +1566,94 @@ func TestW3CTraceHeadersRoundTrip(t *testing.T) {
 
 }
 
+func TestDistributedTraceHeadersMapRoundTrip(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	outbound := app.StartTransaction("outbound")
+	carrier := map[string]string{"key": "value"}
+	outbound.InsertDistributedTraceHeadersMap(carrier)
+
+	for _, key := range []string{"key", "traceparent", "tracestate", "newrelic"} {
+		if carrier[key] == "" {
+			t.Errorf("missing carrier key %q: %v", key, carrier)
+		}
+	}
+	if len(carrier) != 4 {
+		t.Errorf("unexpected carrier keys: %v", carrier)
+	}
+
+	inbound := app.StartTransaction("inbound")
+	inbound.AcceptDistributedTraceHeadersMap(TransportQueue, carrier)
+	if out, in := outbound.GetTraceMetadata().TraceID, inbound.GetTraceMetadata().TraceID; out != in {
+		t.Errorf("trace id not propagated: outbound=%s inbound=%s", out, in)
+	}
+	inbound.End()
+	outbound.End()
+	app.expectNoLoggedErrors(t)
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "Supportability/TraceContext/Accept/Success", Scope: "", Forced: true, Data: nil},
+	})
+}
+
+func TestInsertDistributedTraceHeadersMapNil(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	txn.InsertDistributedTraceHeadersMap(nil)
+	txn.End()
+
+	var nilTxn *Transaction
+	carrier := map[string]string{}
+	nilTxn.InsertDistributedTraceHeadersMap(carrier)
+	nilTxn.AcceptDistributedTraceHeadersMap(TransportQueue, carrier)
+	if len(carrier) != 0 {
+		t.Error("nil transaction inserted headers:", carrier)
+	}
+	app.expectNoLoggedErrors(t)
+}
+
+func TestSetDistributedTracingEnabled(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+
+	txn := app.StartTransaction("disabled")
+	txn.SetDistributedTracingEnabled(false)
+	txn.SetWebRequestHTTP(&http.Request{
+		Header: http.Header{
+			DistributedTraceW3CTraceParentHeader: []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		},
+	})
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if len(hdrs) != 0 {
+		t.Error("headers inserted with distributed tracing disabled:", hdrs)
+	}
+	txn.StartSegment("segment").End()
+	txn.End()
+	app.expectNoLoggedErrors(t)
+	app.ExpectSpanEvents(t, []internal.WantEvent{})
+
+	txn = app.StartTransaction("enabled")
+	txn.SetDistributedTracingEnabled(false)
+	txn.SetDistributedTracingEnabled(true)
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if hdrs.Get(DistributedTraceW3CTraceParentHeader) == "" {
+		t.Error("headers not inserted with distributed tracing enabled again:", hdrs)
+	}
+	txn.End()
+	app.expectNoLoggedErrors(t)
+}
+
+func TestSetDistributedTracingEnabledAccept(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	txn.SetDistributedTracingEnabled(false)
+	txn.AcceptDistributedTraceHeaders(TransportHTTP, http.Header{
+		DistributedTraceW3CTraceParentHeader: []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+	app.expectSingleLoggedError(t, "unable to accept trace payload", map[string]interface{}{
+		"reason": errTxnDTDisabled.Error(),
+	})
+	txn.End()
+}
+
 func TestW3CTraceHeadersDuplicateTraceState(t *testing.T) {
 	app := testApp(distributedTracingReplyFields, enableW3COnly, t)
 	txn := app.StartTransaction("hello")
@@ -2307,3 +2395,213 @@ func TestW3CHeaderCases(t *testing.T) {
 		})
 	}
 }
+
+func TestBaggageRoundTrip(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+
+	outbound := app.StartTransaction("outbound")
+	outbound.SetBaggage("tenant", "acme corp")
+	outbound.SetBaggage("region", "us-east")
+	hdrs := http.Header{}
+	outbound.InsertDistributedTraceHeaders(hdrs)
+	outbound.End()
+
+	if got := hdrs.Get(DistributedTraceW3CBaggageHeader); got != "region=us-east,tenant=acme%20corp" {
+		t.Error(got)
+	}
+
+	inbound := app.StartTransaction("inbound")
+	inbound.AcceptDistributedTraceHeaders(TransportHTTP, hdrs)
+	if got := inbound.GetBaggage("tenant"); got != "acme corp" {
+		t.Error(got)
+	}
+	if got := inbound.GetBaggage("region"); got != "us-east" {
+		t.Error(got)
+	}
+
+	inbound.SetBaggage("region", "eu-west")
+	downstream := http.Header{}
+	inbound.InsertDistributedTraceHeaders(downstream)
+	inbound.End()
+
+	if got := downstream.Get(DistributedTraceW3CBaggageHeader); got != "region=eu-west,tenant=acme%20corp" {
+		t.Error(got)
+	}
+	app.expectNoLoggedErrors(t)
+}
+
+func TestBaggageAcceptedWithoutPayload(t *testing.T) {
+	for name, replyfn := range map[string]func(*internal.ConnectReply){
+		"not connected": nil,
+		"untrusted":     distributedTracingReplyFieldsNeedTrustKey,
+		"no payload":    distributedTracingReplyFields,
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := testApp(replyfn, enableBetterCAT, t)
+			hdrs := http.Header{}
+			if name != "no payload" {
+				hdrs = headersFromString(`{"v":[0,1],"d":{"ty":"App","ap":"456","ac":"321","id":"id","tr":"traceID","ti":1488325987402}}`)
+			}
+			hdrs.Set(DistributedTraceW3CBaggageHeader, "tenant=acme")
+			txn := app.StartTransaction("hello")
+			txn.AcceptDistributedTraceHeaders(TransportHTTP, hdrs)
+			if got := txn.GetBaggage("tenant"); got != "acme" {
+				t.Error(got)
+			}
+			txn.End()
+		})
+	}
+}
+
+func TestSetBaggageInvalidKey(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	txn.SetBaggage("not a token", "value")
+	txn.End()
+	app.expectSingleLoggedError(t, "unable to set baggage", map[string]interface{}{
+		"reason": errBaggageKey.Error(),
+		"key":    "not a token",
+	})
+}
+
+func TestSetPriorityBoost(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	// The first priority created by the generator is 0.437714.
+	txn.SetPriorityBoost(0.25)
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	txn.End()
+	app.expectNoLoggedErrors(t)
+
+	// Sampling adds 1 to the boosted priority.
+	if tracestate := hdrs.Get(DistributedTraceW3CTraceStateHeader); !strings.Contains(tracestate, "-1-1.687714-") {
+		t.Error(tracestate)
+	}
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/hello",
+			"guid":     internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+			"sampled":  true,
+			"priority": 1.687714,
+		},
+	}})
+}
+
+func TestSetPriorityBoostClamped(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	txn.SetPriorityBoost(5)
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if tracestate := hdrs.Get(DistributedTraceW3CTraceStateHeader); !strings.Contains(tracestate, "-1-2-") {
+		t.Error(tracestate)
+	}
+
+	// Once sampled, the priority is not lowered below 1.
+	txn.SetPriorityBoost(-5)
+	hdrs = http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	if tracestate := hdrs.Get(DistributedTraceW3CTraceStateHeader); !strings.Contains(tracestate, "-1-1-") {
+		t.Error(tracestate)
+	}
+	txn.End()
+	app.expectNoLoggedErrors(t)
+}
+
+func TestSetPriorityBoostAfterEnd(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	txn.End()
+	txn.SetPriorityBoost(0.5)
+	app.expectSingleLoggedError(t, "unable to set priority boost", map[string]interface{}{
+		"reason": errAlreadyEnded.Error(),
+	})
+}
+
+func TestLinkDistributedTraceHeaders(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+
+	// Three items, each with the headers of its own upstream trace.
+	var items []http.Header
+	var linked []string
+	for i := 0; i < 3; i++ {
+		child := app.StartTransaction("item")
+		hdrs := http.Header{}
+		child.InsertDistributedTraceHeaders(hdrs)
+		items = append(items, hdrs)
+		linked = append(linked, child.GetTraceMetadata().TraceID)
+		child.End()
+	}
+
+	parent := app.StartTransaction("batch")
+	metadata := parent.GetTraceMetadata()
+	for _, hdrs := range items {
+		parent.LinkDistributedTraceHeaders(TransportQueue, hdrs)
+	}
+	parent.End()
+	app.expectNoLoggedErrors(t)
+
+	var want []internal.WantEvent
+	for _, traceID := range linked {
+		if traceID == metadata.TraceID {
+			t.Error("linked trace accepted", traceID)
+		}
+		want = append(want, internal.WantEvent{
+			Intrinsics: map[string]interface{}{
+				"type":      TraceLinkEventType,
+				"timestamp": internal.MatchAnything,
+			},
+			UserAttributes: map[string]interface{}{
+				"trace.id":         metadata.TraceID,
+				"span.id":          metadata.SpanID,
+				"linked.trace.id":  traceID,
+				"linked.span.id":   internal.MatchAnything,
+				"linked.transport": "Queue",
+			},
+		})
+	}
+	app.ExpectCustomEvents(t, want)
+}
+
+func TestLinkDistributedTraceHeadersErrors(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("batch")
+	txn.LinkDistributedTraceHeaders(TransportQueue, http.Header{})
+	app.expectSingleLoggedError(t, "unable to link trace payload", map[string]interface{}{
+		"reason": errTraceLinkNoPayload.Error(),
+	})
+	txn.End()
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+
+	// The payload of an account which is not trusted.
+	untrusted := `{
+		"v":[0,1],
+		"d":{
+			"ty":"App",
+			"ap":"456",
+			"ac":"321",
+			"id":"id",
+			"tr":"traceID",
+			"ti":1488325987402
+		}
+	}`
+	txn = app.StartTransaction("batch")
+	txn.LinkDistributedTraceHeaders(TransportQueue, headersFromString(untrusted))
+	app.expectSingleLoggedError(t, "unable to link trace payload", map[string]interface{}{
+		"reason": errTrustedAccountKey.Error(),
+	})
+	txn.End()
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+
+	app = testApp(nil, func(cfg *Config) { cfg.DistributedTracer.Enabled = false }, t)
+	txn = app.StartTransaction("batch")
+	txn.LinkDistributedTraceHeaders(TransportQueue, http.Header{
+		DistributedTraceW3CTraceParentHeader: []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+	app.expectSingleLoggedError(t, "unable to link trace payload", map[string]interface{}{
+		"reason": errInboundPayloadDTDisabled.Error(),
+	})
+	txn.End()
+}
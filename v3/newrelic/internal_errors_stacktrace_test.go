@@ -4,6 +4,7 @@
 package newrelic
 
 import (
+	"fmt"
 	"runtime"
 	"strings"
 	"testing"
@@ -36,6 +37,25 @@ func (e withStackAndCause) Unwrap() error         { return e.cause }
 func (e withStack) Error() string         { return "something went wrong" }
 func (e withStack) StackTrace() []uintptr { return e.stack }
 
+// pkgErrorsFrame and pkgErrorsStackTrace mirror the types of the stack traces
+// of github.com/pkg/errors.
+type pkgErrorsFrame uintptr
+
+type pkgErrorsStackTrace []pkgErrorsFrame
+
+type withPkgErrorsStack struct {
+	stack []uintptr
+}
+
+func (e withPkgErrorsStack) Error() string { return "something went wrong" }
+func (e withPkgErrorsStack) StackTrace() pkgErrorsStackTrace {
+	st := make(pkgErrorsStackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		st[i] = pkgErrorsFrame(pc)
+	}
+	return st
+}
+
 func generateStack() []uintptr {
 	skip := 2 // skip runtime.Callers and this function.
 	callers := make([]uintptr, 20)
@@ -47,8 +67,8 @@ func alpha() []uintptr { return generateStack() }
 func beta() []uintptr  { return generateStack() }
 
 func TestStackTrace(t *testing.T) {
-	// First choice is any StackTrace() of the immediate error.
-	// Second choice is any StackTrace() of the error's cause.
+	// First choice is the StackTrace() of the deepest error of the chain
+	// of wrapped errors which has one.
 	// Final choice is stack trace of the current location.
 	getStackTraceFrame := "github.com/newrelic/go-agent/v3/newrelic.getStackTrace"
 	testcases := []struct {
@@ -61,6 +81,11 @@ func TestStackTrace(t *testing.T) {
 		{Error: withStackAndCause{stack: alpha(), cause: basicError{}}, ExpectTopFrame: "alpha"},
 		{Error: withStackAndCause{stack: nil, cause: withStack{stack: beta()}}, ExpectTopFrame: "beta"},
 		{Error: withStackAndCause{stack: nil, cause: withStack{stack: nil}}, ExpectTopFrame: getStackTraceFrame},
+		{Error: withStackAndCause{stack: beta(), cause: withStack{stack: alpha()}}, ExpectTopFrame: "alpha"},
+		{Error: fmt.Errorf("wrapped: %w", withStackAndCause{stack: alpha(), cause: basicError{}}), ExpectTopFrame: "alpha"},
+		{Error: withPkgErrorsStack{stack: alpha()}, ExpectTopFrame: "alpha"},
+		{Error: withPkgErrorsStack{stack: nil}, ExpectTopFrame: getStackTraceFrame},
+		{Error: fmt.Errorf("wrapped: %w", withPkgErrorsStack{stack: beta()}), ExpectTopFrame: "beta"},
 	}
 
 	for idx, tc := range testcases {
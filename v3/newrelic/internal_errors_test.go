@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/newrelic/go-agent/v3/internal"
@@ -590,6 +591,147 @@ func TestTooManyExtraErrorAttributes(t *testing.T) {
 	app.ExpectMetrics(t, backgroundMetrics)
 }
 
+func TestNoticeErrorWithAttributes(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.NoticeErrorWithAttributes(Error{
+		Message:    "my msg",
+		Class:      "my class",
+		Attributes: map[string]interface{}{"zip": "zap", "tier": "silver"},
+	}, map[string]interface{}{
+		"order.id": 123,
+		"tier":     "gold",
+	})
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	expectAttributes := map[string]interface{}{
+		"zip":      "zap",
+		"order.id": 123,
+		"tier":     "gold",
+	}
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName:        "OtherTransaction/Go/hello",
+		Msg:            "my msg",
+		Klass:          "my class",
+		UserAttributes: expectAttributes,
+	}})
+	app.ExpectErrorEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"error.class":     "my class",
+			"error.message":   "my msg",
+			"transactionName": "OtherTransaction/Go/hello",
+		},
+		UserAttributes: expectAttributes,
+	}})
+	app.ExpectMetrics(t, backgroundErrorMetrics)
+}
+
+func TestNoticeErrorWithAttributesInvalid(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.NoticeErrorWithAttributes(basicError{}, map[string]interface{}{
+		"INVALID": struct{}{},
+	})
+	app.expectSingleLoggedError(t, "unable to notice error", map[string]interface{}{
+		"reason": `attribute 'INVALID' value of type struct {} is invalid`,
+	})
+	txn.End()
+	app.ExpectErrors(t, []internal.WantError{})
+	app.ExpectErrorEvents(t, []internal.WantEvent{})
+	app.ExpectMetrics(t, backgroundMetrics)
+}
+
+func TestNoticeErrorWithAttributesTooMany(t *testing.T) {
+	attrs := make(map[string]interface{})
+	for i := 0; i < attributeErrorLimit; i++ {
+		attrs[strconv.Itoa(i)] = i
+	}
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.NoticeErrorWithAttributes(Error{
+		Message:    "my msg",
+		Class:      "my class",
+		Attributes: map[string]interface{}{"zip": "zap"},
+	}, attrs)
+	app.expectSingleLoggedError(t, "unable to notice error", map[string]interface{}{
+		"reason": errTooManyErrorAttributes.Error(),
+	})
+	txn.End()
+	app.ExpectErrors(t, []internal.WantError{})
+	app.ExpectErrorEvents(t, []internal.WantEvent{})
+	app.ExpectMetrics(t, backgroundMetrics)
+}
+
+func TestNoticeErrorWithAttributesHighSecurity(t *testing.T) {
+	cfgFn := func(cfg *Config) {
+		cfg.HighSecurity = true
+		cfg.DistributedTracer.Enabled = false
+	}
+	app := testApp(nil, cfgFn, t)
+	txn := app.StartTransaction("hello")
+	txn.NoticeErrorWithAttributes(Error{
+		Message: "my msg",
+		Class:   "my class",
+	}, map[string]interface{}{"zip": "zap"})
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	app.ExpectErrorEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"error.class":     "my class",
+			"error.message":   "message removed by high security setting",
+			"transactionName": "OtherTransaction/Go/hello",
+		},
+		UserAttributes: map[string]interface{}{},
+	}})
+}
+
+func TestNoticeErrorWithGroupKey(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.NoticeErrorWithGroupKey(Error{
+		Message: "my msg",
+		Class:   "my class",
+	}, "payments/timeout")
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	app.ExpectErrors(t, []internal.WantError{{
+		TxnName: "OtherTransaction/Go/hello",
+		Msg:     "my msg",
+		Klass:   "my class",
+	}})
+	app.ExpectErrorEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"error.class":      "my class",
+			"error.message":    "my msg",
+			"error.group.name": "payments/timeout",
+			"transactionName":  "OtherTransaction/Go/hello",
+		},
+	}})
+	app.ExpectMetrics(t, backgroundErrorMetrics)
+}
+
+func TestNoticeErrorWithGroupKeyInvalid(t *testing.T) {
+	testcases := []struct {
+		groupKey string
+		reason   error
+	}{
+		{groupKey: "", reason: errEmptyErrorGroupKey},
+		{groupKey: strings.Repeat("x", attributeValueLengthLimit+1), reason: errErrorGroupKeyTooLong},
+	}
+	for _, tc := range testcases {
+		app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+		txn := app.StartTransaction("hello")
+		txn.NoticeErrorWithGroupKey(basicError{}, tc.groupKey)
+		app.expectSingleLoggedError(t, "unable to notice error", map[string]interface{}{
+			"reason": tc.reason.Error(),
+		})
+		txn.End()
+		app.ExpectErrors(t, []internal.WantError{})
+		app.ExpectErrorEvents(t, []internal.WantEvent{})
+		app.ExpectMetrics(t, backgroundMetrics)
+	}
+}
+
 type basicError struct{}
 
 func (e basicError) Error() string { return "something went wrong" }
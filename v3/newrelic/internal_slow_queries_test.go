@@ -889,3 +889,144 @@ func TestSlowQueryParamsInvalid(t *testing.T) {
 		Params:       map[string]interface{}{"number": 5},
 	}})
 }
+
+func TestSlowQuerySample(t *testing.T) {
+	var sampled []*DatastoreSegment
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+	}
+	cfgfn := func(cfg *Config) {
+		cfg.DatastoreTracer.SlowQuery.Threshold = 0
+		cfg.DistributedTracer.Enabled = true
+		ConfigDatastoreSlowSample(func(seg *DatastoreSegment) string {
+			sampled = append(sampled, seg)
+			return "Seq Scan on users"
+		})(cfg)
+	}
+	app := testApp(replyfn, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s1 := &DatastoreSegment{
+		StartTime:          txn.StartSegmentNow(),
+		Product:            DatastorePostgres,
+		Collection:         "users",
+		Operation:          "SELECT",
+		ParameterizedQuery: "SELECT * FROM users WHERE id = $1",
+	}
+	s1.End()
+	txn.End()
+
+	if len(sampled) != 1 || sampled[0] != s1 {
+		t.Fatalf("sample function not called with the segment: %v", sampled)
+	}
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId":  internal.MatchAnything,
+				"sampled":   true,
+				"name":      "Datastore/statement/Postgres/users/SELECT",
+				"category":  "datastore",
+				"component": "Postgres",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{
+				"db.statement":  "SELECT * FROM users WHERE id = $1",
+				"db.collection": "users",
+				"db.explain":    "Seq Scan on users",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+				"transaction.name": "OtherTransaction/Go/hello",
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestSlowQuerySampleBelowThreshold(t *testing.T) {
+	var called bool
+	cfgfn := func(cfg *Config) {
+		cfg.DatastoreTracer.SlowQuery.Threshold = time.Hour
+		cfg.DistributedTracer.Enabled = false
+		cfg.DatastoreTracer.SlowQuery.Sample = func(seg *DatastoreSegment) string {
+			called = true
+			return "Seq Scan on users"
+		}
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s1 := DatastoreSegment{
+		StartTime:          txn.StartSegmentNow(),
+		Product:            DatastorePostgres,
+		Collection:         "users",
+		Operation:          "SELECT",
+		ParameterizedQuery: "SELECT * FROM users WHERE id = $1",
+	}
+	s1.End()
+	txn.End()
+
+	if called {
+		t.Error("sample function called for a fast segment")
+	}
+}
+
+func TestSlowQuerySampleHighSecurity(t *testing.T) {
+	var called bool
+	cfgfn := func(cfg *Config) {
+		cfg.DatastoreTracer.SlowQuery.Threshold = 0
+		cfg.DistributedTracer.Enabled = false
+		cfg.HighSecurity = true
+		cfg.DatastoreTracer.SlowQuery.Sample = func(seg *DatastoreSegment) string {
+			called = true
+			return "Seq Scan on users"
+		}
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s1 := DatastoreSegment{
+		StartTime:          txn.StartSegmentNow(),
+		Product:            DatastorePostgres,
+		Collection:         "users",
+		Operation:          "SELECT",
+		ParameterizedQuery: "SELECT * FROM users WHERE id = $1",
+	}
+	s1.End()
+	txn.End()
+
+	if called {
+		t.Error("sample function called in high security mode")
+	}
+}
+
+func TestSlowQuerySampleEndedTransaction(t *testing.T) {
+	var called bool
+	cfgfn := func(cfg *Config) {
+		cfg.DatastoreTracer.SlowQuery.Threshold = 0
+		cfg.DistributedTracer.Enabled = false
+		cfg.DatastoreTracer.SlowQuery.Sample = func(seg *DatastoreSegment) string {
+			called = true
+			return "Seq Scan on users"
+		}
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s1 := DatastoreSegment{
+		StartTime:          txn.StartSegmentNow(),
+		Product:            DatastorePostgres,
+		Collection:         "users",
+		Operation:          "SELECT",
+		ParameterizedQuery: "SELECT * FROM users WHERE id = $1",
+	}
+	txn.End()
+	s1.End()
+
+	if called {
+		t.Error("sample function called after the transaction ended")
+	}
+}
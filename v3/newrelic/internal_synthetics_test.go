@@ -191,3 +191,64 @@ func TestSyntheticsStandalone(t *testing.T) {
 		Intrinsics: expectedIntrinsics,
 	}})
 }
+
+func TestSetSynthetic(t *testing.T) {
+	cfgFn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = false
+		cfg.CrossApplicationTracer.Enabled = false
+	}
+	app := testApp(nil, cfgFn, t)
+	txn := app.StartTransaction("ping")
+	txn.SetSynthetic("internal-ping")
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":                    "OtherTransaction/Go/ping",
+			"nr.syntheticsResourceId": "",
+			"nr.syntheticsJobId":      "",
+			"nr.syntheticsMonitorId":  "internal-ping",
+			"nr.guid":                 internal.MatchAnything,
+		},
+	}})
+}
+
+func TestSetSyntheticKeepsInboundHeader(t *testing.T) {
+	cfgFn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = false
+		cfg.CrossApplicationTracer.Enabled = false
+	}
+	app := testApp(syntheticsConnectReplyFn, cfgFn, t)
+	txn := app.StartTransaction("hello")
+	txn.SetWebRequestHTTP(inboundSyntheticsRequestBuilder(false, false))
+	txn.SetSynthetic("internal-ping")
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":                    "WebTransaction/Go/hello",
+			"nr.syntheticsResourceId": "rrrrrrr-rrrr-1234-rrrr-rrrrrrrrrrrr",
+			"nr.syntheticsJobId":      "jjjjjjj-jjjj-1234-jjjj-jjjjjjjjjjjj",
+			"nr.syntheticsMonitorId":  "internal-ping",
+			"nr.apdexPerfZone":        internal.MatchAnything,
+			"nr.guid":                 internal.MatchAnything,
+		},
+	}})
+}
+
+func TestSetSyntheticEmptyMonitorID(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("ping")
+	txn.SetSynthetic("")
+	txn.End()
+
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/ping",
+			"guid":     internal.MatchAnything,
+			"priority": internal.MatchAnything,
+			"sampled":  internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+		},
+	}})
+}
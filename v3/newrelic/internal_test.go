@@ -8,6 +8,7 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -296,6 +297,32 @@ func TestRecordCustomEventSuccess(t *testing.T) {
 	}})
 }
 
+func TestRecordCustomEventWithTimestamp(t *testing.T) {
+	app := testApp(nil, nil, t)
+	timestamp := time.Now().Add(-2 * time.Hour)
+	app.RecordCustomEventWithTimestamp("myType", validParams, timestamp)
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      "myType",
+			"timestamp": float64(timeToIntMillis(timestamp)),
+		},
+		UserAttributes: validParams,
+	}})
+}
+
+func TestRecordCustomEventWithTimestampOutsideWindow(t *testing.T) {
+	for _, offset := range []time.Duration{-25 * time.Hour, 25 * time.Hour} {
+		app := testApp(nil, nil, t)
+		app.RecordCustomEventWithTimestamp("myType", validParams, time.Now().Add(offset))
+		app.expectSingleLoggedError(t, "unable to record custom event", map[string]interface{}{
+			"event-type": "myType",
+			"reason":     errEventTimestamp.Error(),
+		})
+		app.ExpectCustomEvents(t, []internal.WantEvent{})
+	}
+}
+
 func TestRecordCustomEventHighSecurityEnabled(t *testing.T) {
 	cfgfn := func(cfg *Config) { cfg.HighSecurity = true }
 	app := testApp(nil, cfgfn, t)
@@ -339,6 +366,16 @@ func TestRecordCustomEventBadInput(t *testing.T) {
 	app.ExpectCustomEvents(t, []internal.WantEvent{})
 }
 
+func TestRecordCustomEventBadAttribute(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordCustomEvent("myType", map[string]interface{}{"zip": struct{}{}})
+	app.expectSingleLoggedError(t, "unable to record custom event", map[string]interface{}{
+		"event-type": "myType",
+		"field":      "zip",
+	})
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+}
+
 func TestRecordCustomEventRemoteDisable(t *testing.T) {
 	replyfn := func(reply *internal.ConnectReply) { reply.CollectCustomEvents = false }
 	app := testApp(replyfn, nil, t)
@@ -350,6 +387,213 @@ func TestRecordCustomEventRemoteDisable(t *testing.T) {
 	app.ExpectCustomEvents(t, []internal.WantEvent{})
 }
 
+func TestRecordFeatureFlag(t *testing.T) {
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	metadata := txn.GetTraceMetadata()
+	txn.RecordFeatureFlag("new-checkout", true)
+	txn.End()
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      FeatureFlagEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"flag.key":   "new-checkout",
+			"flag.value": true,
+			"trace.id":   metadata.TraceID,
+			"span.id":    metadata.SpanID,
+		},
+	}})
+	if metadata.TraceID == "" || metadata.SpanID == "" {
+		t.Error("missing trace context", metadata)
+	}
+}
+
+func TestRecordFeatureFlagLimit(t *testing.T) {
+	ea := testApp(nil, nil, t)
+	txn := ea.StartTransaction("hello")
+	for i := 0; i <= featureFlagsPerTransactionLimit; i++ {
+		txn.RecordFeatureFlag("flag", i)
+	}
+	txn.End()
+	ea.expectSingleLoggedError(t, "unable to record feature flag", map[string]interface{}{
+		"key":    "flag",
+		"reason": errFeatureFlagLimit.Error(),
+	})
+	if n := ea.Private.(*app).testHarvest.CustomEvents.NumSeen(); n != featureFlagsPerTransactionLimit {
+		t.Error(n)
+	}
+}
+
+func TestRecordFeatureFlagLimitInvalidNotCounted(t *testing.T) {
+	ea := testApp(nil, nil, t)
+	txn := ea.StartTransaction("hello")
+	for i := 0; i < featureFlagsPerTransactionLimit; i++ {
+		txn.RecordFeatureFlag("flag", struct{}{})
+	}
+	txn.RecordFeatureFlag("flag", true)
+	txn.End()
+	if n := ea.Private.(*app).testHarvest.CustomEvents.NumSeen(); n != 1 {
+		t.Error(n)
+	}
+}
+
+func TestRecordFeatureFlagInvalid(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("hello")
+	txn.RecordFeatureFlag("", true)
+	app.expectSingleLoggedError(t, "unable to record feature flag", map[string]interface{}{
+		"key":    "",
+		"reason": errFeatureFlagKey.Error(),
+	})
+	txn.End()
+	txn.RecordFeatureFlag("flag", true)
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+
+	var nilTxn *Transaction
+	nilTxn.RecordFeatureFlag("flag", true)
+}
+
+func TestRecordSlowQuery(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordSlowQuery(SlowQueryData{
+		Product:   DatastorePostgres,
+		Statement: "SELECT * FROM users WHERE id = $1",
+		Duration:  1500 * time.Millisecond,
+		Host:      "db.example.com",
+		Rows:      3,
+	})
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      SlowQueryEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"product":   "Postgres",
+			"statement": "SELECT * FROM users WHERE id = $?",
+			"duration":  1.5,
+			"host":      "db.example.com",
+			"rows":      3,
+		},
+	}})
+}
+
+func TestRecordSlowQueryObfuscatesStatement(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordSlowQuery(SlowQueryData{
+		Product:   DatastoreMySQL,
+		Statement: `SELECT * FROM users WHERE name = "alice" AND age > 21`,
+		Duration:  time.Second,
+	})
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      SlowQueryEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"product":   "MySQL",
+			"statement": "SELECT * FROM users WHERE name = ? AND age > ?",
+			"duration":  1,
+			"rows":      0,
+		},
+	}})
+}
+
+func TestRecordSlowQueryRecordSQLDisabled(t *testing.T) {
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.SecurityPolicies.RecordSQL.SetEnabled(false)
+	}
+	app := testApp(replyfn, nil, t)
+	app.RecordSlowQuery(SlowQueryData{
+		Product:   DatastorePostgres,
+		Statement: "SELECT * FROM users WHERE id = 1",
+		Duration:  time.Second,
+	})
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      SlowQueryEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"product":  "Postgres",
+			"duration": 1,
+			"rows":     0,
+		},
+	}})
+}
+
+func TestRecordSlowQuerySlowQueriesDisabled(t *testing.T) {
+	cfgfn := func(cfg *Config) { cfg.DatastoreTracer.SlowQuery.Enabled = false }
+	app := testApp(nil, cfgfn, t)
+	app.RecordSlowQuery(SlowQueryData{
+		Product:   DatastorePostgres,
+		Statement: "SELECT * FROM users WHERE id = 1",
+		Duration:  time.Second,
+	})
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      SlowQueryEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"product":  "Postgres",
+			"duration": 1,
+			"rows":     0,
+		},
+	}})
+}
+
+func TestRecordSlowQueryWithoutHost(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordSlowQuery(SlowQueryData{
+		Product:   DatastoreMySQL,
+		Statement: "DELETE FROM sessions",
+		Duration:  time.Second,
+	})
+	app.expectNoLoggedErrors(t)
+	app.ExpectCustomEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"type":      SlowQueryEventType,
+			"timestamp": internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{
+			"product":   "MySQL",
+			"statement": "DELETE FROM sessions",
+			"duration":  1,
+			"rows":      0,
+		},
+	}})
+}
+
+func TestRecordSlowQueryInvalid(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordSlowQuery(SlowQueryData{Statement: "SELECT 1", Duration: time.Second})
+	app.expectSingleLoggedError(t, "unable to record slow query", map[string]interface{}{
+		"reason": errSlowQueryProduct.Error(),
+	})
+	app.RecordSlowQuery(SlowQueryData{Product: DatastorePostgres, Duration: -time.Second})
+	app.expectSingleLoggedError(t, "unable to record slow query", map[string]interface{}{
+		"reason": errSlowQueryDuration.Error(),
+	})
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+}
+
+func TestRecordSlowQueryHighSecurityEnabled(t *testing.T) {
+	cfgfn := func(cfg *Config) { cfg.HighSecurity = true }
+	app := testApp(nil, cfgfn, t)
+	app.RecordSlowQuery(SlowQueryData{Product: DatastorePostgres, Statement: "SELECT 1"})
+	app.expectSingleLoggedError(t, "unable to record slow query", map[string]interface{}{
+		"reason": errHighSecurityEnabled.Error(),
+	})
+	app.ExpectCustomEvents(t, []internal.WantEvent{})
+}
+
 func TestRecordCustomMetricSuccess(t *testing.T) {
 	app := testApp(nil, nil, t)
 	app.RecordCustomMetric("myMetric", 123.0)
@@ -396,6 +640,58 @@ func TestRecordCustomMetricNegativeInf(t *testing.T) {
 	})
 }
 
+func TestRecordDimensionalMetricSuccess(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordDimensionalMetric("checkout.duration", 1.5, map[string]interface{}{
+		"region":  "eu",
+		"premium": true,
+	})
+	app.expectNoLoggedErrors(t)
+	js, err := app.app.testHarvest.DimensionalMetrics.Data("12345", time.Now())
+	if nil != err {
+		t.Fatal(err)
+	}
+	var payload []interface{}
+	if err := json.Unmarshal(js, &payload); nil != err {
+		t.Fatal(err)
+	}
+	if len(payload) != 4 {
+		t.Fatal(string(js))
+	}
+	series, _ := payload[3].([]interface{})
+	if len(series) != 1 {
+		t.Fatal(string(js))
+	}
+	got, _ := json.Marshal(series[0])
+	expect := `[{"attributes":{"premium":true,"region":"eu"},"name":"checkout.duration"},[1,1.5,1.5,1.5,1.5,2.25]]`
+	if string(got) != expect {
+		t.Error(string(got))
+	}
+	app.ExpectMetrics(t, []internal.WantMetric{})
+}
+
+func TestRecordDimensionalMetricInvalid(t *testing.T) {
+	app := testApp(nil, nil, t)
+	app.RecordDimensionalMetric("", 1, nil)
+	app.expectSingleLoggedError(t, "unable to record dimensional metric", map[string]interface{}{
+		"metric-name": "",
+		"reason":      errMetricNameEmpty.Error(),
+	})
+	app.RecordDimensionalMetric("myMetric", math.NaN(), nil)
+	app.expectSingleLoggedError(t, "unable to record dimensional metric", map[string]interface{}{
+		"metric-name": "myMetric",
+		"reason":      errMetricNaN.Error(),
+	})
+	app.RecordDimensionalMetric("myMetric", 1, map[string]interface{}{"zip": struct{}{}})
+	app.expectSingleLoggedError(t, "unable to record dimensional metric", map[string]interface{}{
+		"metric-name": "myMetric",
+		"reason":      `attribute 'zip' value of type struct {} is invalid`,
+	})
+	if n := len(app.app.testHarvest.DimensionalMetrics.metrics); n != 0 {
+		t.Error(n)
+	}
+}
+
 type sampleResponseWriter struct {
 	code    int
 	written int
@@ -844,6 +1140,57 @@ func TestQueueTime(t *testing.T) {
 	}})
 }
 
+func TestSetQueueTime(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		enableBetterCAT(cfg)
+	}
+	app := testApp(distributedTracingReplyFields, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	txn.SetQueueTime(2 * time.Second)
+	txn.End()
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "WorkerQueue/QueueTime", Scope: "", Forced: true, Data: []float64{1, 2, 2, 2, 2, 4}},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":          "OtherTransaction/Go/hello",
+			"queueDuration": 2.0,
+			"guid":          internal.MatchAnything,
+			"priority":      internal.MatchAnything,
+			"sampled":       internal.MatchAnything,
+			"traceId":       internal.MatchAnything,
+		},
+	}})
+	app.ExpectSpanEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":             "OtherTransaction/Go/hello",
+			"transaction.name": "OtherTransaction/Go/hello",
+			"category":         "generic",
+			"nr.entryPoint":    true,
+			"sampled":          true,
+			"priority":         internal.MatchAnything,
+			"guid":             internal.MatchAnything,
+			"transactionId":    internal.MatchAnything,
+			"traceId":          internal.MatchAnything,
+		},
+		UserAttributes: map[string]interface{}{},
+		AgentAttributes: map[string]interface{}{
+			SpanAttributeQueueDuration: 2.0,
+		},
+	}})
+}
+
+func TestSetQueueTimeAlreadyEnded(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.End()
+	txn.SetQueueTime(time.Second)
+	app.expectSingleLoggedError(t, "unable to set queue time", map[string]interface{}{
+		"reason": errAlreadyEnded.Error(),
+	})
+}
+
 func TestIgnore(t *testing.T) {
 	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
 	txn := app.StartTransaction("hello")
@@ -1040,6 +1387,99 @@ func TestTraceSegmentDefer(t *testing.T) {
 	}, webMetrics...))
 }
 
+func TestTraceSegmentSetName(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.SetWebRequestHTTP(helloRequest)
+	s := txn.StartSegment("placeholder")
+	s.SetName("renamed")
+	s.End()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	scope := "WebTransaction/Go/hello"
+	app.ExpectMetrics(t, append([]internal.WantMetric{
+		{Name: "Custom/renamed", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/renamed", Scope: scope, Forced: false, Data: nil},
+	}, webMetrics...))
+}
+
+func TestTraceSegmentSetNameAfterEnd(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.SetWebRequestHTTP(helloRequest)
+	s := txn.StartSegment("segment")
+	s.End()
+	s.SetName("renamed")
+	app.expectSingleLoggedError(t, "unable to set segment name", map[string]interface{}{
+		"reason": errSegmentEnded.Error(),
+		"name":   "segment",
+	})
+	if s.Name != "segment" {
+		t.Error("segment renamed after end", s.Name)
+	}
+	txn.End()
+	scope := "WebTransaction/Go/hello"
+	app.ExpectMetrics(t, append([]internal.WantMetric{
+		{Name: "Custom/segment", Scope: "", Forced: false, Data: nil},
+		{Name: "Custom/segment", Scope: scope, Forced: false, Data: nil},
+		{Name: supportSegmentSetNameAfterEnd, Scope: "", Forced: true, Data: singleCount},
+	}, webMetrics...))
+}
+
+func TestTraceSegmentSetNameNilTxn(t *testing.T) {
+	var txn *Transaction
+	s := txn.StartSegment("segment")
+	s.SetName("renamed")
+	s.End()
+	if s.Name != "renamed" {
+		t.Error("segment not renamed", s.Name)
+	}
+}
+
+func TestTraceSegmentEndWithDuration(t *testing.T) {
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	txn.SetWebRequestHTTP(helloRequest)
+	before := time.Now()
+	s := txn.StartSegment("segment")
+	time.Sleep(10 * time.Millisecond)
+	duration := s.EndWithDuration()
+	elapsed := time.Since(before)
+	app.expectNoLoggedErrors(t)
+	if duration < 10*time.Millisecond || duration > elapsed {
+		t.Errorf("duration %v not within [10ms, %v]", duration, elapsed)
+	}
+	txn.End()
+	scope := "WebTransaction/Go/hello"
+	app.ExpectMetrics(t, append([]internal.WantMetric{
+		{Name: "Custom/segment", Scope: "", Forced: false, Data: []float64{1, duration.Seconds(), duration.Seconds(), duration.Seconds(), duration.Seconds(), duration.Seconds() * duration.Seconds()}},
+		{Name: "Custom/segment", Scope: scope, Forced: false, Data: nil},
+	}, webMetrics...))
+}
+
+func TestTraceSegmentEndWithDurationNotRecorded(t *testing.T) {
+	var nilSegment *Segment
+	if d := nilSegment.EndWithDuration(); d != 0 {
+		t.Error(d)
+	}
+	var nilTxn *Transaction
+	if d := nilTxn.StartSegment("segment").EndWithDuration(); d != 0 {
+		t.Error(d)
+	}
+
+	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
+	txn := app.StartTransaction("hello")
+	s := txn.StartSegment("segment")
+	txn.End()
+	if d := s.EndWithDuration(); d != 0 {
+		t.Error(d)
+	}
+	app.expectSingleLoggedError(t, "unable to end segment", map[string]interface{}{
+		"reason": errAlreadyEnded.Error(),
+		"name":   "segment",
+	})
+}
+
 func TestTraceSegmentNilErr(t *testing.T) {
 	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
 	txn := app.StartTransaction("hello")
@@ -1437,6 +1877,163 @@ func TestExternalSegmentCustomFieldsWithURL(t *testing.T) {
 	})
 }
 
+func TestStartExternalSegmentURL(t *testing.T) {
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+	}
+	cfgfn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = true
+		cfg.CrossApplicationTracer.Enabled = false
+	}
+	app := testApp(replyfn, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	txn.SetWebRequestHTTP(helloRequest)
+	s := txn.StartExternalSegmentURL("POST", "https://otherhost.com:8080/path/zip/zap?secret=ssshhh")
+	if s.Host != "otherhost.com:8080" {
+		t.Error("wrong host", s.Host)
+	}
+	if s.Procedure != "POST" {
+		t.Error("wrong procedure", s.Procedure)
+	}
+	s.End()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	scope := "WebTransaction/Go/hello"
+	app.ExpectMetrics(t, append([]internal.WantMetric{
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/all", Scope: "", Forced: false, Data: nil},
+		{Name: "DurationByCaller/Unknown/Unknown/Unknown/Unknown/allWeb", Scope: "", Forced: false, Data: nil},
+		{Name: "External/all", Scope: "", Forced: true, Data: nil},
+		{Name: "External/allWeb", Scope: "", Forced: true, Data: nil},
+		{Name: "External/otherhost.com:8080/all", Scope: "", Forced: false, Data: nil},
+		{Name: "External/otherhost.com:8080/http/POST", Scope: scope, Forced: false, Data: nil},
+	}, webMetrics...))
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId":  internal.MatchAnything,
+				"name":      "External/otherhost.com:8080/http/POST",
+				"category":  "http",
+				"component": "http",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{
+				"http.url":    "https://otherhost.com:8080/path/zip/zap",
+				"http.method": "POST",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "WebTransaction/Go/hello",
+				"transaction.name": "WebTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestStartExternalSegmentURLDefaults(t *testing.T) {
+	var txn *Transaction
+	s := txn.StartExternalSegmentURL("", "http://example.com")
+	if s.Host != "example.com" || s.Procedure != "GET" {
+		t.Error("wrong host or procedure", s.Host, s.Procedure)
+	}
+	// Ending a segment of a nil transaction is a no-op.
+	s.End()
+
+	s = txn.StartExternalSegmentURL("GET", "http://[::1")
+	if s.Host != "" {
+		t.Error("host set from invalid url", s.Host)
+	}
+}
+
+func TestStartExternalSegmentsConcurrent(t *testing.T) {
+	const numRequests = 20
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	txn := app.StartTransaction("hello")
+	requests := make([]*http.Request, numRequests)
+	for i := range requests {
+		requests[i], _ = http.NewRequest("GET", "http://example.com/"+strconv.Itoa(i), nil)
+	}
+	segments := StartExternalSegments(txn, requests)
+	if len(segments) != numRequests {
+		t.Fatal(len(segments))
+	}
+	var wg sync.WaitGroup
+	for _, s := range segments {
+		wg.Add(1)
+		go func(s *ExternalSegment) {
+			defer wg.Done()
+			s.End()
+		}(s)
+	}
+	wg.Wait()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+
+	parents := make(map[string]bool)
+	for i, req := range requests {
+		if segments[i].Request != req {
+			t.Error("segment not in the order of the requests", i)
+		}
+		traceparent := req.Header.Get(DistributedTraceW3CTraceParentHeader)
+		if traceparent == "" {
+			t.Error("missing traceparent header", i)
+		}
+		parents[traceparent] = true
+	}
+	if len(parents) != numRequests {
+		t.Error("traceparent headers should have a span each", len(parents))
+	}
+
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "External/all", Scope: "", Forced: true, Data: []float64{numRequests}},
+		{Name: "External/example.com/http/GET", Scope: "OtherTransaction/Go/hello", Forced: false, Data: []float64{numRequests}},
+	})
+	spans := make([]internal.WantEvent, 0, numRequests+1)
+	for range requests {
+		spans = append(spans, internal.WantEvent{
+			Intrinsics: map[string]interface{}{
+				"parentId":  internal.MatchAnything,
+				"name":      "External/example.com/http/GET",
+				"category":  "http",
+				"component": "http",
+				"span.kind": "client",
+			},
+		})
+	}
+	spans = append(spans, internal.WantEvent{
+		Intrinsics: map[string]interface{}{
+			"name":             "OtherTransaction/Go/hello",
+			"transaction.name": "OtherTransaction/Go/hello",
+			"category":         "generic",
+			"nr.entryPoint":    true,
+		},
+	})
+	app.ExpectSpanEvents(t, spans)
+}
+
+func TestStartExternalSegmentsNilTransaction(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("hello")
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	req = RequestWithTransactionContext(req, txn)
+	segments := StartExternalSegments(nil, []*http.Request{req, nil})
+	if len(segments) != 2 || segments[0].StartTime.thread == nil {
+		t.Fatal("segment of the request context transaction not started")
+	}
+	for _, s := range segments {
+		s.End()
+	}
+	txn.End()
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "External/example.com/http/GET", Scope: "OtherTransaction/Go/hello", Forced: false, Data: []float64{1}},
+	})
+}
+
 func TestExternalSegmentCustomFieldsWithRequest(t *testing.T) {
 	replyfn := func(reply *internal.ConnectReply) {
 		reply.SetSampleEverything()
@@ -2055,6 +2652,104 @@ func TestMessageProducerSegmentBasic(t *testing.T) {
 	})
 }
 
+func TestMessageProducerSegmentRoutingKeyAndHeaders(t *testing.T) {
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+	}
+	cfgfn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = true
+	}
+	app := testApp(replyfn, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s := MessageProducerSegment{
+		StartTime:       txn.StartSegmentNow(),
+		Library:         "RabbitMQ",
+		DestinationType: MessageExchange,
+		DestinationName: "myExchange",
+		RoutingKey:      "users.created",
+		Headers: map[string]string{
+			"tenant": "acme",
+		},
+	}
+	s.End()
+	app.expectNoLoggedErrors(t)
+	txn.End()
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId": internal.MatchAnything,
+				"name":     "MessageBroker/RabbitMQ/Exchange/Produce/Named/myExchange",
+				"category": "generic",
+			},
+			UserAttributes: map[string]interface{}{
+				"message.headers.tenant": "acme",
+			},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "users.created",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"transaction.name": "OtherTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
+func TestMessageProducerSegmentHeadersHighSecurity(t *testing.T) {
+	replyfn := func(reply *internal.ConnectReply) {
+		reply.SetSampleEverything()
+	}
+	cfgfn := func(cfg *Config) {
+		cfg.DistributedTracer.Enabled = true
+		cfg.HighSecurity = true
+	}
+	app := testApp(replyfn, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	s := MessageProducerSegment{
+		StartTime:       txn.StartSegmentNow(),
+		Library:         "RabbitMQ",
+		DestinationType: MessageExchange,
+		DestinationName: "myExchange",
+		RoutingKey:      "users.created",
+		Headers: map[string]string{
+			"tenant": "acme",
+		},
+	}
+	s.End()
+	txn.End()
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"parentId": internal.MatchAnything,
+				"name":     "MessageBroker/RabbitMQ/Exchange/Produce/Named/myExchange",
+				"category": "generic",
+			},
+			UserAttributes: map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{
+				"message.routingKey": "users.created",
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"transaction.name": "OtherTransaction/Go/hello",
+				"sampled":          true,
+				"category":         "generic",
+				"nr.entryPoint":    true,
+			},
+			UserAttributes:  map[string]interface{}{},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
+
 func TestMessageProducerSegmentMissingDestinationType(t *testing.T) {
 	app := testApp(nil, ConfigDistributedTracerEnabled(false), t)
 	txn := app.StartTransaction("hello")
@@ -2159,3 +2854,75 @@ func TestMessageProducerSegmentNilSegment(t *testing.T) {
 	var s *MessageProducerSegment
 	s.End()
 }
+
+func TestEndWithTimestamps(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("hello")
+	start := time.Now().Add(-time.Hour)
+	txn.EndWithTimestamps(start, start.Add(2500*time.Millisecond))
+	app.expectNoLoggedErrors(t)
+	app.ExpectMetricsPresent(t, []internal.WantMetric{
+		{Name: "OtherTransaction/Go/hello", Scope: "", Forced: true, Data: []float64{1, 2.5, 0, 2.5, 2.5, 6.25}},
+		{Name: "OtherTransactionTotalTime/Go/hello", Scope: "", Forced: false, Data: []float64{1, 2.5, 2.5, 2.5, 2.5, 6.25}},
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":      "OtherTransaction/Go/hello",
+			"timestamp": float64(start.UnixNano() / int64(time.Millisecond)),
+			"duration":  2.5,
+			"totalTime": 2.5,
+			"guid":      internal.MatchAnything,
+			"traceId":   internal.MatchAnything,
+			"priority":  internal.MatchAnything,
+			"sampled":   internal.MatchAnything,
+		},
+	}})
+}
+
+func TestEndWithTimestampsWithSegments(t *testing.T) {
+	cfgfn := func(cfg *Config) {
+		cfg.TransactionTracer.Threshold.IsApdexFailing = false
+		cfg.TransactionTracer.Threshold.Duration = 0
+		cfg.TransactionTracer.Segments.Threshold = 0
+	}
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("hello")
+	txn.StartSegment("segment").End()
+	start := time.Now().Add(-time.Hour)
+	txn.EndWithTimestamps(start, start.Add(time.Second))
+	app.expectSingleLoggedError(t, "unable to end transaction", map[string]interface{}{
+		"reason": errEndWithSegments.Error(),
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{})
+
+	// The transaction has not ended, and its segment is recorded once it is
+	// ended with its actual start.
+	txn.End()
+	app.ExpectTxnTraces(t, []internal.WantTxnTrace{{
+		MetricName:  "OtherTransaction/Go/hello",
+		NumSegments: 1,
+	}})
+}
+
+func TestEndWithTimestampsEndBeforeStart(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("hello")
+	start := time.Now()
+	txn.EndWithTimestamps(start, start.Add(-time.Second))
+	app.expectSingleLoggedError(t, "unable to end transaction", map[string]interface{}{
+		"reason": errEndBeforeStart.Error(),
+	})
+	app.ExpectTxnEvents(t, []internal.WantEvent{})
+
+	// The transaction has not ended.
+	txn.End()
+	app.ExpectTxnEvents(t, []internal.WantEvent{{
+		Intrinsics: map[string]interface{}{
+			"name":     "OtherTransaction/Go/hello",
+			"guid":     internal.MatchAnything,
+			"traceId":  internal.MatchAnything,
+			"priority": internal.MatchAnything,
+			"sampled":  internal.MatchAnything,
+		},
+	}})
+}
@@ -32,10 +32,20 @@ type txn struct {
 
 	ignore bool
 
+	// dtDisabled disables distributed tracing for this transaction only:
+	// trace headers are neither inserted nor accepted, and no span events
+	// are collected.
+	dtDisabled bool
+
 	// wroteHeader prevents capturing multiple response code errors if the
 	// user erroneously calls WriteHeader multiple times.
 	wroteHeader bool
 
+	// numFeatureFlags is the number of feature flag evaluations recorded.
+	numFeatureFlags int
+	// numTraceLinks is the number of traces linked.
+	numTraceLinks int
+
 	txnData
 
 	mainThread   tracingThread
@@ -95,6 +105,21 @@ func (txn *txn) setOption(opts ...TraceOption) {
 		// any previous code-level metrics information in the transaction.
 		reportCodeLevelMetrics(txnOpts, txn.appRun, txn.Attrs.Agent.Add)
 	}
+
+	if txnOpts.WorkerID != "" {
+		txn.Lock()
+		txn.addWorkerID(txnOpts.WorkerID)
+		txn.Unlock()
+	}
+}
+
+// addWorkerID records the worker identifier given by WithWorkerID, subject to
+// the same restrictions as custom attributes.  The caller must hold the lock.
+func (txn *txn) addWorkerID(id string) {
+	if txn.appRun == nil || txn.Config.HighSecurity || !txn.Reply.SecurityPolicies.CustomParameters.Enabled() || txn.finished {
+		return
+	}
+	addUserAttribute(txn.Attrs, WorkerIDAttribute, id, destAll)
 }
 
 func newTxn(app *app, run *appRun, name string, opts ...TraceOption) *thread {
@@ -106,7 +131,7 @@ func newTxn(app *app, run *appRun, name string, opts ...TraceOption) *thread {
 	for _, o := range opts {
 		o(&txnOpts)
 	}
-	txn.markStart(time.Now())
+	txn.markStart(run.Config.now())
 
 	txn.Name = name
 	txn.Attrs = newAttributes(run.AttributeConfig)
@@ -115,6 +140,10 @@ func newTxn(app *app, run *appRun, name string, opts ...TraceOption) *thread {
 		reportCodeLevelMetrics(txnOpts, run, txn.Attrs.Agent.Add)
 	}
 
+	if txnOpts.WorkerID != "" {
+		txn.addWorkerID(txnOpts.WorkerID)
+	}
+
 	if run.Config.DistributedTracer.Enabled {
 		txn.BetterCAT.Enabled = true
 		txn.TraceIDGenerator = run.Reply.TraceIDGenerator
@@ -160,7 +189,7 @@ func (thd *thread) logAPIError(err error, operation string, extraDetails map[str
 }
 
 func (txn *txn) shouldCollectSpanEvents() bool {
-	if !txn.Config.DistributedTracer.Enabled {
+	if !txn.Config.DistributedTracer.Enabled || txn.dtDisabled {
 		return false
 	}
 	if !txn.Config.SpanEvents.Enabled {
@@ -201,6 +230,36 @@ func (txn *txn) lazilyCalculateSampled() bool {
 	return txn.BetterCAT.Sampled
 }
 
+func (txn *txn) SetPriorityBoost(delta float32) error {
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+
+	// Once the sampling decision has been made, the priority is kept in
+	// the range matching the decision.
+	sampled := txn.sampledCalculated && txn.BetterCAT.Sampled
+	txn.BetterCAT.Priority = txn.BetterCAT.Priority.boost(delta, sampled)
+	return nil
+}
+
+func (txn *txn) SetSynthetic(monitorID string) error {
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+	if monitorID == "" {
+		return errEmptyMonitorID
+	}
+
+	txn.CrossProcess.setSyntheticsMonitor(monitorID)
+	return nil
+}
+
 func (txn *txn) SetWebRequest(r WebRequest) error {
 	txn.Lock()
 	defer txn.Unlock()
@@ -278,6 +337,22 @@ func (txn *txn) getsApdex() bool {
 	return txn.IsWeb
 }
 
+// apdexThreshold returns the Apdex threshold of the transaction.  Key
+// transaction thresholds take precedence over those set with
+// ConfigApdexForTransaction, which take precedence over the application
+// threshold.
+func (txn *txn) apdexThreshold() time.Duration {
+	if _, ok := txn.Reply.KeyTxnApdex[txn.FinalName]; !ok {
+		if t, ok := txn.Config.ApdexThresholds[txn.FinalName]; ok {
+			return t
+		}
+		if t, ok := txn.Config.ApdexThresholds[txn.Name]; ok {
+			return t
+		}
+	}
+	return internal.CalculateApdexThreshold(txn.Reply, txn.FinalName)
+}
+
 func (txn *txn) shouldSaveTrace() bool {
 	if !txn.Config.TransactionTracer.Enabled {
 		return false
@@ -364,7 +439,7 @@ func headersJustWritten(thd *thread, code int, hdr http.Header) {
 	responseCodeAttribute(txn.Attrs, code)
 
 	if txn.appRun.responseCodeIsError(code) {
-		e := txnErrorFromResponseCode(time.Now(), code)
+		e := txnErrorFromResponseCode(txn.Config.now(), code)
 		e.Stack = getStackTrace()
 		expect := txn.appRun.responseCodeIsExpected(code)
 		thd.noticeErrorInternal(e, expect)
@@ -412,7 +487,26 @@ func addCrossProcessHeaders(txn *txn, hdr http.Header) {
 	}
 }
 
+// txnBounds are the explicit start and end times of a transaction.
+type txnBounds struct {
+	start time.Time
+	end   time.Time
+}
+
 func (thd *thread) End(recovered interface{}) error {
+	return thd.end(recovered, nil)
+}
+
+// EndWithTimestamps ends the transaction using the bounds given in place of
+// the time the transaction was started and the current time.
+func (thd *thread) EndWithTimestamps(start, end time.Time) error {
+	if end.Before(start) {
+		return errEndBeforeStart
+	}
+	return thd.end(nil, &txnBounds{start: start, end: end})
+}
+
+func (thd *thread) end(recovered interface{}, bounds *txnBounds) error {
 	txn := thd.txn
 	txn.Lock()
 	defer txn.Unlock()
@@ -420,17 +514,30 @@ func (thd *thread) End(recovered interface{}) error {
 	if txn.finished {
 		return errAlreadyEnded
 	}
+	// The times of the segments are relative to the time the transaction
+	// was started, they would be off if it was replaced.
+	if nil != bounds && 0 != txn.stamp {
+		return errEndWithSegments
+	}
 
 	txn.finished = true
 
 	if nil != recovered {
-		e := txnErrorFromPanic(time.Now(), recovered)
+		e := txnErrorFromPanic(txn.Config.now(), recovered)
 		e.Stack = getStackTrace()
 		thd.noticeErrorInternal(e, false)
 		log.Println(string(debug.Stack()))
 	}
 
-	txn.markEnd(time.Now(), thd.thread)
+	if nil != bounds {
+		txn.Start = bounds.start
+		txn.markEnd(bounds.end, thd.thread)
+		// The activity of the threads was recorded using the current
+		// time, so it does not relate to the bounds given.
+		txn.TotalTime = txn.Duration
+	} else {
+		txn.markEnd(txn.Config.now(), thd.thread)
+	}
 	txn.freezeName()
 	// Make a sampling decision if there have been no segments or outbound
 	// payloads.
@@ -445,7 +552,7 @@ func (thd *thread) End(recovered interface{}) error {
 
 	// Assign apdexThreshold regardless of whether or not the transaction
 	// gets apdex since it may be used to calculate the trace threshold.
-	txn.ApdexThreshold = internal.CalculateApdexThreshold(txn.Reply, txn.FinalName)
+	txn.ApdexThreshold = txn.apdexThreshold()
 
 	if txn.getsApdex() {
 		if txn.HasErrors() && txn.NoticeErrors() {
@@ -478,7 +585,13 @@ func (thd *thread) End(recovered interface{}) error {
 		}
 		root.AgentAttributes.addAgentAttrs(txn.Attrs.Agent)
 		root.UserAttributes.addUserAttrs(txn.Attrs.user)
+		for key, val := range txn.rootSpanUserAttributes {
+			root.UserAttributes.add(key, val)
+		}
 
+		if txn.Queuing > 0 {
+			root.AgentAttributes.addFloat(SpanAttributeQueueDuration, txn.Queuing.Seconds())
+		}
 		if txn.rootSpanErrData != nil {
 			root.AgentAttributes.addString(SpanAttributeErrorClass, txn.rootSpanErrData.Klass)
 			root.AgentAttributes.addString(SpanAttributeErrorMessage, txn.rootSpanErrData.Msg)
@@ -550,9 +663,13 @@ var (
 	errorsDisabled        = errors.New("errors disabled")
 	errNilError           = errors.New("nil error")
 	errAlreadyEnded       = errors.New("transaction has already ended")
+	errEndBeforeStart     = errors.New("transaction end is before its start")
+	errEndWithSegments    = errors.New("transaction has segments, its start cannot be replaced")
+	errSegmentEnded       = errors.New("segment has already ended")
 	errSecurityPolicy     = errors.New("disabled by security policy")
 	errTransactionIgnored = errors.New("transaction has been ignored")
 	errBrowserDisabled    = errors.New("browser disabled by local configuration")
+	errEmptyMonitorID     = errors.New("synthetics monitor id is empty")
 )
 
 const (
@@ -614,6 +731,9 @@ func addErrorAttrs(t *thread, err errorData) {
 var (
 	errTooManyErrorAttributes = fmt.Errorf("too many extra attributes: limit is %d",
 		attributeErrorLimit)
+	errEmptyErrorGroupKey   = errors.New("error group key is empty")
+	errErrorGroupKeyTooLong = fmt.Errorf("error group key exceeds length limit of %d",
+		attributeValueLengthLimit)
 )
 
 // errorCause returns the error's deepest wrapped ancestor.
@@ -636,11 +756,50 @@ func errorClassMethod(err error) string {
 	return ""
 }
 
+// errorStackTraceMethod returns the stack trace provided by the error, either
+// with the StackTrace() []uintptr method or with the StackTrace method of the
+// errors of github.com/pkg/errors, which returns a slice of program counters
+// of a named type.  The latter is detected with reflection so that the
+// package need not be imported.
 func errorStackTraceMethod(err error) stackTrace {
 	if st, ok := err.(stackTracer); ok {
 		return st.StackTrace()
 	}
-	return nil
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	if typ := m.Type(); typ.NumIn() != 0 || typ.NumOut() != 1 ||
+		typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	if 0 == frames.Len() {
+		return nil
+	}
+	st := make(stackTrace, frames.Len())
+	for i := range st {
+		st[i] = uintptr(frames.Index(i).Uint())
+	}
+	return st
+}
+
+// errorOriginStackTrace returns the stack trace of the deepest error of the
+// chain of wrapped errors providing one, which points to where the error
+// originated rather than to where it was wrapped.
+func errorOriginStackTrace(err error) stackTrace {
+	var origin stackTrace
+	for nil != err {
+		if st := errorStackTraceMethod(err); nil != st {
+			origin = st
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = unwrapper.Unwrap()
+	}
+	return origin
 }
 
 func errorAttributesMethod(err error) map[string]interface{} {
@@ -670,11 +829,9 @@ func errDataFromError(input error, expect bool) (data errorData, err error) {
 		data.Klass = reflect.TypeOf(cause).String()
 	}
 
-	if st := errorStackTraceMethod(input); nil != st {
-		// If the error implements StackTracer, use that.
-		data.Stack = st
-	} else if st := errorStackTraceMethod(cause); nil != st {
-		// Otherwise, if the error's cause implements StackTracer, use that.
+	if st := errorOriginStackTrace(input); nil != st {
+		// If the error or one of the errors it wraps implements
+		// StackTracer, use the stack trace of the deepest one.
 		data.Stack = st
 	} else {
 		// As a final fallback, generate a StackTrace here.
@@ -708,7 +865,32 @@ func errDataFromError(input error, expect bool) (data errorData, err error) {
 	return data, nil
 }
 
-func (thd *thread) NoticeError(input error, expect bool) error {
+// addErrorAttributes validates the attributes and adds them to the error data,
+// replacing the attributes of the error which have the same key.
+func addErrorAttributes(data *errorData, attrs map[string]interface{}) error {
+	n := len(data.ExtraAttributes)
+	for key := range attrs {
+		if _, ok := data.ExtraAttributes[key]; !ok {
+			n++
+		}
+	}
+	if n > attributeErrorLimit {
+		return errTooManyErrorAttributes
+	}
+	if nil == data.ExtraAttributes {
+		data.ExtraAttributes = make(map[string]interface{}, len(attrs))
+	}
+	for key, val := range attrs {
+		val, err := validateUserAttribute(key, val)
+		if nil != err {
+			return err
+		}
+		data.ExtraAttributes[key] = val
+	}
+	return nil
+}
+
+func (thd *thread) NoticeError(input error, expect bool, attrs map[string]interface{}, groupKey string) error {
 	txn := thd.txn
 	txn.Lock()
 	defer txn.Unlock()
@@ -725,6 +907,13 @@ func (thd *thread) NoticeError(input error, expect bool) error {
 	if nil != err {
 		return err
 	}
+	data.GroupKey = groupKey
+
+	if len(attrs) > 0 {
+		if err := addErrorAttributes(&data, attrs); nil != err {
+			return err
+		}
+	}
 
 	if txn.Config.HighSecurity || !txn.Reply.SecurityPolicies.CustomParameters.Enabled() {
 		data.ExtraAttributes = nil
@@ -733,6 +922,21 @@ func (thd *thread) NoticeError(input error, expect bool) error {
 	return thd.noticeErrorInternal(data, expect)
 }
 
+func (txn *txn) SetQueueTime(d time.Duration) error {
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+
+	if d < 0 {
+		d = 0
+	}
+	txn.Queuing = d
+	return nil
+}
+
 func (txn *txn) SetName(name string) error {
 	txn.Lock()
 	defer txn.Unlock()
@@ -756,6 +960,17 @@ func (txn *txn) Ignore() error {
 	return nil
 }
 
+func (txn *txn) SetDistributedTracingEnabled(enabled bool) error {
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+	txn.dtDisabled = !enabled
+	return nil
+}
+
 func (thd *thread) startSegmentAt(at time.Time) SegmentStartTime {
 	var s segmentStartTime
 	txn := thd.txn
@@ -770,6 +985,38 @@ func (thd *thread) startSegmentAt(at time.Time) SegmentStartTime {
 	}
 }
 
+// startExternalSegments starts n segments, each on a new thread so that they
+// are siblings, and creates the outbound headers of each, holding the
+// transaction lock once for them all.
+func (thd *thread) startExternalSegments(at time.Time, n int) ([]SegmentStartTime, []http.Header) {
+	txn := thd.txn
+	txn.Lock()
+	defer txn.Unlock()
+
+	starts := make([]SegmentStartTime, n)
+	hdrs := make([]http.Header, n)
+	for i := range starts {
+		// Like NewGoroutine, use the same thread once the transaction
+		// has finished.
+		segThd := thd
+		var s segmentStartTime
+		if !txn.finished {
+			segThd = &thread{
+				thread: createThread(txn),
+				txn:    txn,
+			}
+			s = startSegment(&txn.txnData, segThd.thread, at)
+		}
+		starts[i] = SegmentStartTime{
+			start:  s,
+			thread: segThd,
+		}
+		hdrs[i] = oldCATOutboundHeadersLocked(txn)
+		segThd.createDistributedTracePayloadLocked(hdrs[i])
+	}
+	return starts, hdrs
+}
+
 const (
 	// Browser fields are encoded using the first digits of the license
 	// key.
@@ -810,7 +1057,14 @@ func (txn *txn) BrowserTimingHeader() (*BrowserTimingHeader, error) {
 		return nil, errTransactionIgnored
 	}
 
-	encodingKey := browserEncodingKey(txn.Config.License)
+	var static *browserStaticHeader
+	var encodingKey []byte
+	if txn.Config.BrowserMonitoring.CacheStaticHeader {
+		static = txn.appRun.browserStaticHeader()
+		encodingKey = static.encodingKey
+	} else {
+		encodingKey = browserEncodingKey(txn.Config.License)
+	}
 
 	attrs, err := obfuscate(browserAttributes(txn.Attrs), encodingKey)
 	if err != nil {
@@ -835,6 +1089,7 @@ func (txn *txn) BrowserTimingHeader() (*BrowserTimingHeader, error) {
 			ErrorBeacon:           txn.Reply.ErrorBeacon,
 			Agent:                 txn.Reply.JSAgentFile,
 		},
+		static: static,
 	}, nil
 }
 
@@ -859,21 +1114,49 @@ func (thd *thread) NewGoroutine() *Transaction {
 	})
 }
 
-func endBasic(s *Segment) error {
+// segmentEnded returns whether the segment started at start has ended, or
+// its transaction has.
+func segmentEnded(start SegmentStartTime) bool {
+	thd := start.thread
+	if nil == thd {
+		return false
+	}
+	txn := thd.txn
+	txn.Lock()
+	defer txn.Unlock()
+	if txn.finished {
+		return true
+	}
+	_, open := segmentStartedAt(thd.thread, start.start)
+	return !open
+}
+
+// recordSupportability records a count of the supportability metric in the
+// harvest of the application, rather than in the transaction since it may
+// have ended.
+func (thd *thread) recordSupportability(name string) {
+	if nil == thd {
+		return
+	}
+	thd.txn.app.Consume(thd.txn.Reply.RunID, supportabilityCount(name))
+}
+
+func endBasic(s *Segment) (time.Duration, error) {
 	thd := s.StartTime.thread
 	if nil == thd {
-		return nil
+		return 0, nil
 	}
 	txn := thd.txn
+	var duration time.Duration
 	var err error
 	txn.Lock()
 	if txn.finished {
 		err = errAlreadyEnded
 	} else {
-		err = endBasicSegment(&txn.txnData, thd.thread, s.StartTime.start, time.Now(), s.Name)
+		duration, err = endBasicSegment(&txn.txnData, thd.thread, s.StartTime.start, txn.Config.now(), s.Name)
 	}
 	txn.Unlock()
-	return err
+	return duration, err
 }
 
 func endDatastore(s *DatastoreSegment) error {
@@ -881,6 +1164,8 @@ func endDatastore(s *DatastoreSegment) error {
 	if nil == thd {
 		return nil
 	}
+	now := thd.txn.Config.now()
+	explain := datastoreSlowSample(s, now)
 	txn := thd.txn
 	txn.Lock()
 	defer txn.Unlock()
@@ -911,7 +1196,7 @@ func endDatastore(s *DatastoreSegment) error {
 		TxnData:            &txn.txnData,
 		Thread:             thd.thread,
 		Start:              s.StartTime.start,
-		Now:                time.Now(),
+		Now:                now,
 		Product:            string(s.Product),
 		Collection:         s.Collection,
 		Operation:          s.Operation,
@@ -921,9 +1206,37 @@ func endDatastore(s *DatastoreSegment) error {
 		PortPathOrID:       s.PortPathOrID,
 		Database:           s.DatabaseName,
 		ThisHost:           txn.appRun.Config.hostname,
+		Explain:            explain,
 	})
 }
 
+// datastoreSlowSample calls the DatastoreTracer.SlowQuery.Sample function of
+// the configuration if the datastore segment ending now is slow.  The
+// function is called without holding the transaction lock since it may take
+// a while, running an EXPLAIN query for example.
+func datastoreSlowSample(s *DatastoreSegment, now time.Time) string {
+	thd := s.StartTime.thread
+	txn := thd.txn
+	txn.Lock()
+	sample := txn.Config.DatastoreTracer.SlowQuery.Sample
+	if nil == sample || txn.finished || txn.Config.HighSecurity {
+		txn.Unlock()
+		return ""
+	}
+	if txn.Reply.SecurityPolicies.RecordSQL.IsSet() && !txn.Reply.SecurityPolicies.RecordSQL.Enabled() {
+		txn.Unlock()
+		return ""
+	}
+	start, ok := segmentStartedAt(thd.thread, s.StartTime.start)
+	slow := ok && txn.slowQueryWorthy(now.Sub(start))
+	txn.Unlock()
+
+	if !slow {
+		return ""
+	}
+	return sample(s)
+}
+
 func externalSegmentMethod(s *ExternalSegment) string {
 	if s.Procedure != "" {
 		return s.Procedure
@@ -979,7 +1292,7 @@ func endExternal(s *ExternalSegment) error {
 		TxnData:    &txn.txnData,
 		Thread:     thd.thread,
 		Start:      s.StartTime.start,
-		Now:        time.Now(),
+		Now:        txn.Config.now(),
 		Logger:     txn.Config.Logger,
 		Response:   s.Response,
 		URL:        u,
@@ -1011,12 +1324,13 @@ func endMessage(s *MessageProducerSegment) error {
 		TxnData:         &txn.txnData,
 		Thread:          thd.thread,
 		Start:           s.StartTime.start,
-		Now:             time.Now(),
+		Now:             txn.Config.now(),
 		Library:         s.Library,
 		Logger:          txn.Config.Logger,
 		DestinationName: s.DestinationName,
 		DestinationType: string(s.DestinationType),
 		DestinationTemp: s.DestinationTemporary,
+		RoutingKey:      s.RoutingKey,
 	})
 }
 
@@ -1027,6 +1341,12 @@ func oldCATOutboundHeaders(txn *txn) http.Header {
 	txn.Lock()
 	defer txn.Unlock()
 
+	return oldCATOutboundHeadersLocked(txn)
+}
+
+// oldCATOutboundHeadersLocked works like oldCATOutboundHeaders, the
+// transaction lock being held by the caller.
+func oldCATOutboundHeadersLocked(txn *txn) http.Header {
 	if txn.finished {
 		return http.Header{}
 	}
@@ -1070,7 +1390,16 @@ func (thd *thread) CreateDistributedTracePayload(hdrs http.Header) {
 	txn.Lock()
 	defer txn.Unlock()
 
-	if !txn.BetterCAT.Enabled {
+	thd.createDistributedTracePayloadLocked(hdrs)
+}
+
+// createDistributedTracePayloadLocked works like
+// CreateDistributedTracePayload, the transaction lock being held by the
+// caller.
+func (thd *thread) createDistributedTracePayloadLocked(hdrs http.Header) {
+	txn := thd.txn
+
+	if !txn.BetterCAT.Enabled || txn.dtDisabled {
 		return
 	}
 
@@ -1147,12 +1476,17 @@ func (thd *thread) CreateDistributedTracePayload(hdrs http.Header) {
 		p.TransactionID = ""
 	}
 	hdrs.Set(DistributedTraceW3CTraceStateHeader, p.W3CTraceState())
+
+	if len(txn.baggage) > 0 {
+		hdrs.Set(DistributedTraceW3CBaggageHeader, txn.baggage.header())
+	}
 }
 
 var (
 	errOutboundPayloadCreated   = errors.New("outbound payload already created")
 	errAlreadyAccepted          = errors.New("AcceptDistributedTraceHeaders has already been called")
 	errInboundPayloadDTDisabled = errors.New("DistributedTracer must be enabled to accept an inbound payload")
+	errTxnDTDisabled            = errors.New("distributed tracing is disabled for this transaction")
 	errTrustedAccountKey        = errors.New("trusted account key missing or does not match")
 )
 
@@ -1169,6 +1503,10 @@ func (txn *txn) acceptDistributedTraceHeadersLocked(t TransportType, hdrs http.H
 		return errInboundPayloadDTDisabled
 	}
 
+	if txn.dtDisabled {
+		return errTxnDTDisabled
+	}
+
 	if txn.finished {
 		return errAlreadyEnded
 	}
@@ -1190,6 +1528,15 @@ func (txn *txn) acceptDistributedTraceHeadersLocked(t TransportType, hdrs http.H
 		return nil
 	}
 
+	// The baggage is kept whether or not the trace payload is accepted:
+	// it does not depend on the trust of the caller.
+	if inbound := hdrs[DistributedTraceW3CBaggageHeader]; len(inbound) > 0 {
+		if nil == txn.baggage {
+			txn.baggage = make(baggage)
+		}
+		txn.baggage.merge(inbound)
+	}
+
 	if txn.Reply.AccountID == "" || txn.Reply.TrustedAccountKey == "" {
 		// We can't accept a payload:  The application is not yet
 		// connected or serverless distributed tracing configuration was
@@ -1209,15 +1556,7 @@ func (txn *txn) acceptDistributedTraceHeadersLocked(t TransportType, hdrs http.H
 	}
 
 	// and let's also do our trustedKey check
-	receivedTrustKey := payload.TrustedAccountKey
-	if receivedTrustKey == "" {
-		receivedTrustKey = payload.Account
-	}
-
-	// If the trust key doesn't match but we don't have any New Relic trace info, this means
-	// we just got the TraceParent header, and we still need to save that info to BetterCAT
-	// farther down.
-	if receivedTrustKey != txn.Reply.TrustedAccountKey && payload.HasNewRelicTraceInfo {
+	if payloadUntrusted(payload, txn.Reply.TrustedAccountKey) {
 		support.AcceptPayloadUntrustedAccount = true
 		return errTrustedAccountKey
 	}
@@ -1242,6 +1581,59 @@ func (txn *txn) acceptDistributedTraceHeadersLocked(t TransportType, hdrs http.H
 	return nil
 }
 
+// payloadUntrusted reports whether the payload comes from an account which is
+// not trusted.
+func payloadUntrusted(payload *payload, trustedAccountKey string) bool {
+	receivedTrustKey := payload.TrustedAccountKey
+	if receivedTrustKey == "" {
+		receivedTrustKey = payload.Account
+	}
+
+	// If the trust key doesn't match but we don't have any New Relic trace info, this means
+	// we just got the TraceParent header, and we still need to save that info to BetterCAT
+	// farther down.
+	return receivedTrustKey != trustedAccountKey && payload.HasNewRelicTraceInfo
+}
+
+// LinkDistributedTraceHeaders records a custom event linking the trace of
+// the transaction to the trace of the inbound headers, which are not
+// accepted: the transaction does not become part of their trace.  Like
+// accepted headers, the headers of untrusted accounts are rejected.
+func (thd *thread) LinkDistributedTraceHeaders(t TransportType, hdrs http.Header) error {
+	txn := thd.txn
+	txn.Lock()
+	if !txn.BetterCAT.Enabled {
+		txn.Unlock()
+		return errInboundPayloadDTDisabled
+	}
+	if txn.dtDisabled {
+		txn.Unlock()
+		return errTxnDTDisabled
+	}
+	if txn.finished {
+		txn.Unlock()
+		return errAlreadyEnded
+	}
+	// The parsing outcome is not reported in the supportability metrics
+	// of the payloads accepted.
+	linked, err := acceptPayload(hdrs, txn.Reply.TrustedAccountKey, &distributedTracingSupport{})
+	if nil == err && nil == linked {
+		err = errTraceLinkNoPayload
+	}
+	if nil == err && payloadUntrusted(linked, txn.Reply.TrustedAccountKey) {
+		err = errTrustedAccountKey
+	}
+	if nil != err {
+		txn.Unlock()
+		return err
+	}
+	metadata := thd.traceMetadataLocked()
+	txn.Unlock()
+
+	return txn.recordLimitedEvent(TraceLinkEventType, traceLinkEventParams(metadata, linked, t.toString()),
+		&txn.numTraceLinks, traceLinksPerTransactionLimit, errTraceLinkLimit)
+}
+
 func (txn *txn) Application() *Application {
 	return newApplication(txn.app)
 }
@@ -1276,6 +1668,40 @@ func (thd *thread) AddUserSpanAttribute(key string, val interface{}) error {
 	return nil
 }
 
+// AddSpanAttribute adds a user attribute to the span of the segment currently
+// active on the thread, or to the root span if there is none.
+func (thd *thread) AddSpanAttribute(key string, val interface{}) error {
+	txn := thd.txn
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+
+	if outputDests := applyAttributeConfig(thd.Attrs.config, key, destSpan); outputDests == 0 {
+		return nil
+	}
+
+	if txn.Config.HighSecurity {
+		return errHighSecurityEnabled
+	}
+
+	if !txn.Reply.SecurityPolicies.CustomParameters.Enabled() {
+		return errSecurityPolicy
+	}
+
+	attrs := &txn.rootSpanUserAttributes
+	if n := len(thd.thread.stack); n > 0 {
+		attrs = &thd.thread.stack[n-1].userAttributes
+	}
+	if _, exists := (*attrs)[key]; !exists && len(*attrs) >= attributeUserLimit {
+		return userAttributeLimitErr{key}
+	}
+	addAttr(attrs, key, val)
+	return nil
+}
+
 var (
 	// Ensure that txn implements AddAgentAttributer to avoid breaking
 	// integration package type assertions.
@@ -1292,6 +1718,28 @@ func (txn *txn) AddAgentAttribute(name string, stringVal string, otherVal interf
 	txn.Attrs.Agent.Add(name, stringVal, otherVal)
 }
 
+func (thd *thread) SetBaggage(key, value string) error {
+	txn := thd.txn
+	txn.Lock()
+	defer txn.Unlock()
+
+	if txn.finished {
+		return errAlreadyEnded
+	}
+	if nil == txn.baggage {
+		txn.baggage = make(baggage)
+	}
+	return txn.baggage.set(key, value)
+}
+
+func (thd *thread) GetBaggage(key string) string {
+	txn := thd.txn
+	txn.Lock()
+	defer txn.Unlock()
+
+	return txn.baggage[key]
+}
+
 func (thd *thread) GetTraceMetadata() (metadata TraceMetadata) {
 	txn := thd.txn
 	txn.Lock()
@@ -1300,7 +1748,13 @@ func (thd *thread) GetTraceMetadata() (metadata TraceMetadata) {
 	if txn.finished {
 		return
 	}
+	return thd.traceMetadataLocked()
+}
 
+// traceMetadataLocked returns the trace metadata of the thread.  It must be
+// called with the transaction locked.
+func (thd *thread) traceMetadataLocked() (metadata TraceMetadata) {
+	txn := thd.txn
 	if txn.BetterCAT.Enabled {
 		metadata.TraceID = txn.BetterCAT.TraceID
 		if txn.shouldCollectSpanEvents() {
@@ -1311,6 +1765,51 @@ func (thd *thread) GetTraceMetadata() (metadata TraceMetadata) {
 	return
 }
 
+// RecordFeatureFlag records the evaluation of a feature flag as a custom
+// event holding the trace context of the thread.
+func (thd *thread) RecordFeatureFlag(key string, value interface{}) error {
+	if key == "" {
+		return errFeatureFlagKey
+	}
+	txn := thd.txn
+	txn.Lock()
+	if txn.finished {
+		txn.Unlock()
+		return errAlreadyEnded
+	}
+	metadata := thd.traceMetadataLocked()
+	txn.Unlock()
+
+	return txn.recordLimitedEvent(FeatureFlagEventType, featureFlagEventParams(key, value, metadata),
+		&txn.numFeatureFlags, featureFlagsPerTransactionLimit, errFeatureFlagLimit)
+}
+
+// recordLimitedEvent records a custom event of the transaction, unless the
+// count of the events of its kind has reached the limit, in which case
+// errLimit is returned.  The event is validated first so that the events
+// which are not recorded do not count towards the limit.
+func (txn *txn) recordLimitedEvent(eventType string, params eventParams, count *int, limit int, errLimit error) error {
+	event, run, err := txn.app.newCustomEvent(eventType, params, time.Now())
+	if nil != err {
+		return err
+	}
+
+	txn.Lock()
+	if txn.finished {
+		txn.Unlock()
+		return errAlreadyEnded
+	}
+	if *count >= limit {
+		txn.Unlock()
+		return errLimit
+	}
+	*count++
+	txn.Unlock()
+
+	txn.app.Consume(run.Reply.RunID, event)
+	return nil
+}
+
 func (thd *thread) GetLinkingMetadata() (metadata LinkingMetadata) {
 	txn := thd.txn
 	metadata.EntityName = txn.appRun.firstAppName
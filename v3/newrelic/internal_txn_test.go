@@ -941,3 +941,73 @@ func TestErrAttrsAddedWhenPanic(t *testing.T) {
 		},
 	})
 }
+
+func TestApdexForTransaction(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		txnName    string
+		thresholds map[string]time.Duration
+		keyTxn     float64
+		duration   time.Duration
+		zone       string
+	}{
+		{
+			name:     "application threshold",
+			txnName:  "hello",
+			duration: 100 * time.Millisecond,
+			zone:     "S",
+		},
+		{
+			name:       "tolerating by given name",
+			txnName:    "hello",
+			thresholds: map[string]time.Duration{"hello": 50 * time.Millisecond},
+			duration:   100 * time.Millisecond,
+			zone:       "T",
+		},
+		{
+			name:       "failing by full name",
+			txnName:    "hello",
+			thresholds: map[string]time.Duration{"WebTransaction/Go/hello": 10 * time.Millisecond},
+			duration:   100 * time.Millisecond,
+			zone:       "F",
+		},
+		{
+			name:       "other transaction unaffected",
+			txnName:    "goodbye",
+			thresholds: map[string]time.Duration{"hello": 10 * time.Millisecond},
+			duration:   100 * time.Millisecond,
+			zone:       "S",
+		},
+		{
+			name:       "key transaction takes precedence",
+			txnName:    "hello",
+			thresholds: map[string]time.Duration{"hello": 10 * time.Millisecond},
+			keyTxn:     1,
+			duration:   100 * time.Millisecond,
+			zone:       "S",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			replyfn := func(reply *internal.ConnectReply) {
+				if tc.keyTxn > 0 {
+					reply.KeyTxnApdex = map[string]float64{"WebTransaction/Go/" + tc.txnName: tc.keyTxn}
+				}
+			}
+			cfgfn := func(cfg *Config) {
+				cfg.DistributedTracer.Enabled = false
+				ConfigApdexForTransaction(tc.thresholds)(cfg)
+			}
+			app := testApp(replyfn, cfgfn, t)
+			txn := app.StartTransaction(tc.txnName)
+			txn.SetWebRequestHTTP(helloRequest)
+			txn.thread.txn.Start = time.Now().Add(-tc.duration)
+			txn.End()
+			app.ExpectTxnEvents(t, []internal.WantEvent{{
+				Intrinsics: map[string]interface{}{
+					"name":             "WebTransaction/Go/" + tc.txnName,
+					"nr.apdexPerfZone": tc.zone,
+				},
+			}})
+		})
+	}
+}
@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	expectErrorAttr = "error.expected"
+	expectErrorAttr   = "error.expected"
+	errorGroupKeyAttr = "error.group.name"
 )
 
 func addOptionalStringField(w *jsonFieldsWriter, key, value string) {
@@ -17,7 +18,7 @@ func addOptionalStringField(w *jsonFieldsWriter, key, value string) {
 	}
 }
 
-func intrinsicsJSON(e *txnEvent, buf *bytes.Buffer, expect bool) {
+func intrinsicsJSON(e *txnEvent, buf *bytes.Buffer, expect bool, groupKey string) {
 	w := jsonFieldsWriter{buf: buf}
 
 	buf.WriteByte('{')
@@ -35,6 +36,8 @@ func intrinsicsJSON(e *txnEvent, buf *bytes.Buffer, expect bool) {
 		w.stringField(expectErrorAttr, "true")
 	}
 
+	addOptionalStringField(&w, errorGroupKeyAttr, groupKey)
+
 	if e.CrossProcess.Used() {
 		addOptionalStringField(&w, "client_cross_process_id", e.CrossProcess.ClientID)
 		addOptionalStringField(&w, "trip_id", e.CrossProcess.TripID)
@@ -36,6 +36,11 @@ const (
 	maxSyntheticsTraces = 20
 	maxHarvestErrors    = 20
 	maxHarvestSlowSQLs  = 10
+	// maxDimensionalMetrics is the maximum number of dimensional metric
+	// series per harvest, and maxDimensionalMetricCardinality the maximum
+	// number of series of a single dimensional metric name.
+	maxDimensionalMetrics           = 2 * 1000
+	maxDimensionalMetricCardinality = 100
 
 	errorEventMessageLengthLimit = 4096
 	// attributes
@@ -46,6 +51,9 @@ const (
 	// provided when noticing an error.
 	attributeErrorLimit       = 32
 	customEventAttributeLimit = 64
+	// dimensionalMetricAttributeLimit limits the number of attributes of
+	// a dimensional metric.
+	dimensionalMetricAttributeLimit = 32
 
 	// Limits affecting Config validation are found in the config package.
 
@@ -17,7 +17,8 @@ import (
 // https://godoc.org/github.com/newrelic/go-agent/v3/integrations/nrlogrus,
 // https://godoc.org/github.com/newrelic/go-agent/v3/integrations/nrlogxi,
 // and https://godoc.org/github.com/newrelic/go-agent/v3/integrations/nrzap
-// respectively.
+// respectively.  The log/slog package is supported by the ConfigSlogLogger
+// ConfigOption.
 type Logger interface {
 	Error(msg string, context map[string]interface{})
 	Warn(msg string, context map[string]interface{})
@@ -20,6 +20,7 @@ type commonAttributes struct {
 
 type logEvents struct {
 	numSeen        int
+	numWithTrace   int
 	failedHarvests int
 	severityCount  map[string]int
 	commonAttributes
@@ -49,6 +50,9 @@ func (events *logEvents) RecordLoggingMetrics(metrics *metricTable) {
 			severitySeen := logsSeen + "/" + k
 			metrics.addCount(severitySeen, float64(v), forced)
 		}
+		withTrace := float64(events.numWithTrace)
+		metrics.addCount(logsWithTrace, withTrace, forced)
+		metrics.addCount(logsWithoutTrace, seen-withTrace, forced)
 	}
 
 	if events.config.collectEvents {
@@ -110,6 +114,9 @@ func (events *logEvents) Add(e *logEvent) {
 	// always collect this but do not report logging metrics when disabled
 	events.numSeen++
 	events.severityCount[e.severity]++
+	if e.traceID != "" {
+		events.numWithTrace++
+	}
 
 	// Do not collect log events when the harvest capacity is intentionally set to 0
 	// or the collection of events is explicitly disabled
@@ -134,11 +141,13 @@ func (events *logEvents) mergeFailed(other *logEvents) {
 // Merge two logEvents together
 func (events *logEvents) Merge(other *logEvents) {
 	allSeen := events.NumSeen() + other.NumSeen()
+	allWithTrace := events.numWithTrace + other.numWithTrace
 	for _, e := range other.logs {
 		events.Add(&e)
 	}
 
 	events.numSeen = int(allSeen)
+	events.numWithTrace = allWithTrace
 }
 
 func (events *logEvents) CollectorJSON(agentRunID string) ([]byte, error) {
@@ -197,6 +206,7 @@ func (events *logEvents) split() (*logEvents, *logEvents) {
 	sc1, sc2 := splitSeverityCount(events.severityCount)
 	e1 := &logEvents{
 		numSeen:          len(events.logs) / 2,
+		numWithTrace:     events.numWithTrace / 2,
 		failedHarvests:   events.failedHarvests / 2,
 		severityCount:    sc1,
 		commonAttributes: events.commonAttributes,
@@ -204,6 +214,7 @@ func (events *logEvents) split() (*logEvents, *logEvents) {
 	}
 	e2 := &logEvents{
 		numSeen:          events.numSeen - e1.numSeen,
+		numWithTrace:     events.numWithTrace - e1.numWithTrace,
 		failedHarvests:   events.failedHarvests - e1.failedHarvests,
 		severityCount:    sc2,
 		commonAttributes: events.commonAttributes,
@@ -385,6 +385,28 @@ func TestLogEventCollectionDisabled(t *testing.T) {
 	}
 }
 
+func TestLogEventsTraceCorrelationMetrics(t *testing.T) {
+	events := newLogEvents(testCommonAttributes, loggingConfigEnabled(5))
+	withTrace := sampleLogEvent(0.5, infoLevel, "in a transaction")
+	withTrace.traceID = "trace-id"
+	events.Add(withTrace)
+	events.Add(sampleLogEvent(0.5, infoLevel, "outside of a transaction"))
+
+	// Failed harvests keep their counts, even for the dropped events.
+	other := newLogEvents(testCommonAttributes, loggingConfigEnabled(1))
+	other.Add(withTrace)
+	other.Add(withTrace)
+	events.Merge(other)
+
+	metrics := newMetricTable(100, time.Now())
+	events.RecordLoggingMetrics(metrics)
+	expectMetricsPresent(t, metrics, []internal.WantMetric{
+		{Name: logsSeen, Scope: "", Forced: true, Data: []float64{4, 0, 0, 0, 0, 0}},
+		{Name: logsWithTrace, Scope: "", Forced: true, Data: []float64{3, 0, 0, 0, 0, 0}},
+		{Name: logsWithoutTrace, Scope: "", Forced: true, Data: []float64{1, 0, 0, 0, 0, 0}},
+	})
+}
+
 func BenchmarkLogEventsAdd(b *testing.B) {
 	events := newLogEvents(testCommonAttributes, loggingConfigEnabled(internal.MaxLogEvents))
 	event := &logEvent{
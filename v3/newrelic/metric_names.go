@@ -23,6 +23,9 @@ const (
 	dispatcherMetric = "HttpDispatcher"
 
 	queueMetric = "WebFrontend/QueueTime"
+	// workerQueueMetric is the queue time of background transactions,
+	// set with Transaction.SetQueueTime.
+	workerQueueMetric = "WorkerQueue/QueueTime"
 
 	// Transaction name prefixes are located in connect_reply.go.
 
@@ -46,6 +49,10 @@ const (
 
 	supportabilityDropped = "Supportability/MetricsDropped"
 
+	supportDimensionalMetricsDropped = "Supportability/DimensionalMetrics/Dropped"
+
+	supportSegmentSetNameAfterEnd = "Supportability/API/Segment/SetName/AfterEnd"
+
 	// Runtime/System Metrics
 	memoryPhysical       = "Memory/Physical"
 	heapObjectsAllocated = "Memory/Heap/AllocatedObjects"
@@ -70,6 +77,11 @@ const (
 	logsSeen    = "Logging/lines"
 	logsDropped = "Logging/Forwarding/Dropped"
 
+	// Trace context correlation: the lines recorded with and without the
+	// trace context of a transaction.
+	logsWithTrace    = "Logging/lines/with_trace"
+	logsWithoutTrace = "Logging/lines/without_trace"
+
 	// Supportability (at connect)
 	supportLogging        = "Supportability/Logging/Golang"
 	supportLoggingMetrics = "Supportability/Logging/Metrics/Golang"
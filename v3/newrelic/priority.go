@@ -39,6 +39,23 @@ func (p priority) Float32() float32 {
 	return float32(p)
 }
 
+// boost adds delta to the priority, keeping it within the range of priorities
+// of unsampled, [0, 1], or sampled, [1, 2], transactions.
+func (p priority) boost(delta float32, sampled bool) priority {
+	lo, hi := priority(0.0), priority(1.0)
+	if sampled {
+		lo, hi = 1.0, 2.0
+	}
+	p += priority(delta)
+	if p < lo {
+		return lo
+	}
+	if p > hi {
+		return hi
+	}
+	return p
+}
+
 func (p priority) isLowerPriority(y priority) bool {
 	return p < y
 }
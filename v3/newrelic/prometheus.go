@@ -0,0 +1,92 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	supportabilityPrefix = "Supportability/"
+
+	// prometheusSupportabilityName is the name of the Prometheus summary
+	// holding the supportability metrics, the name of each metric being
+	// in its "metric" label.
+	prometheusSupportabilityName = "newrelic_agent_supportability"
+	prometheusContentType        = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// supportabilityMetrics accumulates the supportability metrics harvested by
+// the application, for Application.PrometheusHandler.
+type supportabilityMetrics struct {
+	sync.Mutex
+	counts map[string]float64
+	sums   map[string]float64
+}
+
+// record adds the supportability metrics of the table.  It must only be
+// called with tables which were sent successfully, so that the metrics of the
+// tables lost or merged into a later harvest are not counted.
+func (sm *supportabilityMetrics) record(mt *metricTable) {
+	if nil == mt {
+		return
+	}
+	sm.Lock()
+	defer sm.Unlock()
+
+	for id, m := range mt.metrics {
+		if "" != id.Scope || !strings.HasPrefix(id.Name, supportabilityPrefix) {
+			continue
+		}
+		if nil == sm.counts {
+			sm.counts = make(map[string]float64)
+			sm.sums = make(map[string]float64)
+		}
+		sm.counts[id.Name] += m.data.countSatisfied
+		sm.sums[id.Name] += m.data.totalTolerated
+	}
+}
+
+// writePrometheus writes the metrics in the Prometheus text format, as a
+// summary without quantiles.
+func (sm *supportabilityMetrics) writePrometheus(buf *bytes.Buffer) {
+	sm.Lock()
+	defer sm.Unlock()
+
+	names := make([]string, 0, len(sm.counts))
+	for name := range sm.counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf.WriteString("# HELP " + prometheusSupportabilityName + " Supportability metrics harvested by the New Relic agent.\n")
+	buf.WriteString("# TYPE " + prometheusSupportabilityName + " summary\n")
+	for _, name := range names {
+		label := `{metric="` + escapePrometheusLabel(name) + `"} `
+		buf.WriteString(prometheusSupportabilityName + "_sum" + label)
+		buf.WriteString(strconv.FormatFloat(sm.sums[name], 'g', -1, 64) + "\n")
+		buf.WriteString(prometheusSupportabilityName + "_count" + label)
+		buf.WriteString(strconv.FormatFloat(sm.counts[name], 'g', -1, 64) + "\n")
+	}
+}
+
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapePrometheusLabel(value string) string {
+	return prometheusLabelReplacer.Replace(value)
+}
+
+func (app *app) prometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		app.supportability.writePrometheus(&buf)
+		w.Header().Set("Content-Type", prometheusContentType)
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrapePrometheus(t *testing.T, app *Application) string {
+	w := httptest.NewRecorder()
+	app.PrometheusHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Error(w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != prometheusContentType {
+		t.Error(ct)
+	}
+	return w.Body.String()
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	app := testApp(nil, nil, t)
+	h := app.app.testHarvest
+	h.CreateFinalMetrics(app.app.placeholderRun, nil)
+	app.app.supportability.record(h.Metrics)
+	app.app.supportability.record(h.Metrics)
+
+	body := scrapePrometheus(t, app.Application)
+	for _, line := range []string{
+		"# TYPE newrelic_agent_supportability summary",
+		`newrelic_agent_supportability_count{metric="Supportability/EventHarvest/ReportPeriod"} 2`,
+		`newrelic_agent_supportability_count{metric="Supportability/EventHarvest/AnalyticEventData/HarvestLimit"} 2`,
+		`newrelic_agent_supportability_sum{metric="Supportability/EventHarvest/AnalyticEventData/HarvestLimit"} 20000`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, instanceReporting) {
+		t.Errorf("metric other than supportability in:\n%s", body)
+	}
+}
+
+func TestPrometheusHandlerEscapesNames(t *testing.T) {
+	app := testApp(nil, nil, t)
+	mt := newMetricTable(100, time.Now())
+	mt.addSingleCount(`Supportability/a"b\c`, forced)
+	mt.add("Supportability/scoped", "WebTransaction/Go/hello", metricData{countSatisfied: 1}, forced)
+	app.app.supportability.record(mt)
+
+	expect := "# HELP newrelic_agent_supportability Supportability metrics harvested by the New Relic agent.\n" +
+		"# TYPE newrelic_agent_supportability summary\n" +
+		`newrelic_agent_supportability_sum{metric="Supportability/a\"b\\c"} 0` + "\n" +
+		`newrelic_agent_supportability_count{metric="Supportability/a\"b\\c"} 1` + "\n"
+	if body := scrapePrometheus(t, app.Application); body != expect {
+		t.Error(body)
+	}
+}
+
+func TestPrometheusHandlerNilApplication(t *testing.T) {
+	var app *Application
+	if body := scrapePrometheus(t, app); body != "" {
+		t.Error(body)
+	}
+}
+
+func TestPrometheusHandlerHarvestFailure(t *testing.T) {
+	// The metrics are not retained for the next harvest on a 413 response.
+	app, _ := shutdownTestApp(t, cmdMetrics, 413)
+	app.ShutdownWithResult(5 * time.Second)
+	if body := scrapePrometheus(t, app); strings.Contains(body, supportabilityPrefix) {
+		t.Errorf("metrics of a failed harvest in:\n%s", body)
+	}
+
+	app, _ = shutdownTestApp(t, "", 0)
+	app.ShutdownWithResult(5 * time.Second)
+	if body := scrapePrometheus(t, app); !strings.Contains(body, supportabilityPrefix) {
+		t.Errorf("metrics of a successful harvest missing in:\n%s", body)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import "fmt"
+
+// Region is a New Relic data center, to which the data of an application is
+// sent.  Use ConfigRegion to send the data of an application to a region.
+type Region string
+
+// The regions of New Relic.
+const (
+	// RegionUS is the United States region, the default.
+	RegionUS Region = "US"
+	// RegionEU is the European Union region.
+	RegionEU Region = "EU"
+	// RegionFedRAMP is the region of the FedRAMP compliant endpoints.
+	RegionFedRAMP Region = "FedRAMP"
+)
+
+// regionEndpoints holds the collector host and the OTLP endpoint of each
+// region.
+var regionEndpoints = map[Region]struct {
+	collector string
+	otlp      string
+}{
+	RegionUS:      {collector: preconnectHostDefault, otlp: "https://otlp.nr-data.net"},
+	RegionEU:      {collector: "collector.eu01.nr-data.net", otlp: "https://otlp.eu01.nr-data.net"},
+	RegionFedRAMP: {collector: "gov-collector.newrelic.com", otlp: "https://gov-otlp.nr-data.net"},
+}
+
+// Valid returns an error if the region is not one of the regions of New
+// Relic.
+func (r Region) Valid() error {
+	if _, ok := regionEndpoints[r]; !ok {
+		return fmt.Errorf("invalid region %q", string(r))
+	}
+	return nil
+}
+
+// CollectorHost returns the host of the collector of the region, to which
+// the agent sends the data of the application.  It returns an empty string
+// if the region is not valid.
+func (r Region) CollectorHost() string {
+	return regionEndpoints[r].collector
+}
+
+// OTLPEndpoint returns the OpenTelemetry protocol endpoint of the region,
+// for the OpenTelemetry exporters of a service also instrumented with the
+// agent.  It returns an empty string if the region is not valid.
+func (r Region) OTLPEndpoint() string {
+	return regionEndpoints[r].otlp
+}
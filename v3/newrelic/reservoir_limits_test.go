@@ -73,6 +73,37 @@ func TestCustomLimitEnthusiast(t *testing.T) {
 	}
 }
 
+func TestCustomLimitRetainsBursts(t *testing.T) {
+	// Record a burst of events larger than the capacity of the default
+	// reservoir for a harvest.
+	burst := internal.MaxCustomEvents / (60 / internal.CustomEventHarvestsPerMinute) * 2
+	retained := func(limit int) int {
+		limits := &internal.RequestEventLimits{
+			CustomEvents: limit,
+		}
+		mockReplyFunction := func(reply *internal.ConnectReply) {
+			reply.MockConnectReplyEventLimits(limits)
+		}
+		testApp := newTestApp(
+			mockReplyFunction,
+			ConfigCustomInsightsEventsMaxSamplesStored(limit),
+		)
+		for i := 0; i < burst; i++ {
+			testApp.RecordCustomEvent("burst", map[string]interface{}{"i": i})
+		}
+		return int(testApp.app.testHarvest.CustomEvents.NumSaved())
+	}
+
+	dflt := retained(internal.MaxCustomEvents)
+	larger := retained(internal.MaxCustomEvents * 3)
+	if dflt != burst/2 {
+		t.Errorf("default reservoir retained %d events, expected %d", dflt, burst/2)
+	}
+	if larger != burst {
+		t.Errorf("larger reservoir retained %d events, expected %d", larger, burst)
+	}
+}
+
 func TestCustomLimitsTypo(t *testing.T) {
 	limit := 1000000
 	limits := &internal.RequestEventLimits{
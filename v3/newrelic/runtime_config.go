@@ -0,0 +1,82 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"time"
+
+	"github.com/newrelic/go-agent/v3/internal"
+)
+
+// RuntimeConfig contains the settings in effect for the current connection to
+// New Relic.  These are the local Config values after the settings from the
+// connect reply have been applied, and are useful to verify that server-side
+// configuration is taking effect.
+type RuntimeConfig struct {
+	// RunID identifies the current connection.
+	RunID string
+	// EntityGUID is the entity identifier assigned to the application.
+	EntityGUID string
+	// ApdexThreshold is the apdex threshold used for transactions.
+	ApdexThreshold time.Duration
+
+	// SamplingTarget is the number of transactions that the adaptive
+	// sampler aims to sample every SamplingTargetPeriod.
+	SamplingTarget       uint64
+	SamplingTargetPeriod time.Duration
+
+	// EventHarvestPeriod is the report period of the event types whose
+	// harvest limits were set by the connect reply.
+	EventHarvestPeriod time.Duration
+	// Event limits are the maximum number of each event type collected
+	// per harvest.
+	MaxTxnEvents    int
+	MaxCustomEvents int
+	MaxErrorEvents  int
+	MaxSpanEvents   int
+	MaxLogEvents    int
+
+	// TransactionTracer contains the transaction trace settings.
+	TransactionTracer struct {
+		Enabled bool
+		// Threshold is the duration above which transactions are
+		// traced.  It is not used if IsApdexFailing is true.
+		Threshold      time.Duration
+		IsApdexFailing bool
+		// StackTraceThreshold is the duration above which segments
+		// record a stack trace.
+		StackTraceThreshold time.Duration
+	}
+
+	// Enabled settings for the other data types.
+	ErrorCollectorEnabled    bool
+	ErrorEventsEnabled       bool
+	TransactionEventsEnabled bool
+	SpanEventsEnabled        bool
+}
+
+func newRuntimeConfig(run *appRun) RuntimeConfig {
+	rc := RuntimeConfig{
+		RunID:                    run.Reply.RunID.String(),
+		EntityGUID:               run.Reply.EntityGUID,
+		ApdexThreshold:           internal.FloatSecondsToDuration(run.Reply.ApdexThresholdSeconds),
+		SamplingTarget:           run.Reply.SamplingTarget,
+		SamplingTargetPeriod:     time.Duration(run.Reply.SamplingTargetPeriodInSeconds) * time.Second,
+		EventHarvestPeriod:       run.Reply.ConfigurablePeriod(),
+		MaxTxnEvents:             run.harvestConfig.MaxTxnEvents,
+		MaxCustomEvents:          run.harvestConfig.MaxCustomEvents,
+		MaxErrorEvents:           run.harvestConfig.MaxErrorEvents,
+		MaxSpanEvents:            run.harvestConfig.MaxSpanEvents,
+		MaxLogEvents:             run.harvestConfig.LoggingConfig.maxLogEvents,
+		ErrorCollectorEnabled:    run.Config.ErrorCollector.Enabled,
+		ErrorEventsEnabled:       run.Config.ErrorCollector.Enabled && run.Config.ErrorCollector.CaptureEvents,
+		TransactionEventsEnabled: run.Config.TransactionEvents.Enabled,
+		SpanEventsEnabled:        run.Config.DistributedTracer.Enabled && run.Config.SpanEvents.Enabled,
+	}
+	rc.TransactionTracer.Enabled = run.Config.TransactionTracer.Enabled
+	rc.TransactionTracer.Threshold = run.Config.TransactionTracer.Threshold.Duration
+	rc.TransactionTracer.IsApdexFailing = run.Config.TransactionTracer.Threshold.IsApdexFailing
+	rc.TransactionTracer.StackTraceThreshold = run.Config.TransactionTracer.Segments.StackTraceThreshold
+	return rc
+}
@@ -5,6 +5,7 @@ package newrelic
 
 import (
 	"net/http"
+	"time"
 )
 
 // SegmentStartTime is created by Transaction.StartSegmentNow and marks the
@@ -120,6 +121,18 @@ type MessageProducerSegment struct {
 	// DestinationTemporary must be set to true if destination is temporary
 	// to improve metric grouping.
 	DestinationTemporary bool
+
+	// RoutingKey is the routing key of the message, such as the AMQP
+	// routing key or the Kafka message key.  It is recorded as the
+	// "message.routingKey" span attribute.
+	RoutingKey string
+
+	// Headers are message headers to record as span attributes, each
+	// header being recorded as "message.headers.<name>".  Only populate
+	// the headers you want recorded: header values may hold sensitive
+	// data.  Like attributes added with AddAttribute, they are not
+	// recorded when high security mode is enabled.
+	Headers map[string]string
 }
 
 // MessageDestinationType is used for the MessageSegment.DestinationType field.
@@ -144,16 +157,45 @@ func (s *Segment) AddAttribute(key string, val interface{}) {
 	addSpanAttr(s.StartTime, key, val)
 }
 
+// SetName sets the name of the segment.  Use it when the name is only known
+// once the segment has started, rather than ending a placeholder segment and
+// starting a new one.  SetName has no effect once the segment has ended: the
+// call is logged and counted in the
+// "Supportability/API/Segment/SetName/AfterEnd" metric.
+func (s *Segment) SetName(name string) {
+	if nil == s {
+		return
+	}
+	if segmentEnded(s.StartTime) {
+		s.StartTime.thread.logAPIError(errSegmentEnded, "set segment name", map[string]interface{}{
+			"name": s.Name,
+		})
+		s.StartTime.thread.recordSupportability(supportSegmentSetNameAfterEnd)
+		return
+	}
+	s.Name = name
+}
+
 // End finishes the segment.
 func (s *Segment) End() {
+	s.EndWithDuration()
+}
+
+// EndWithDuration finishes the segment like End and returns its duration, as
+// recorded in the segment metric.  Zero is returned if the segment was not
+// recorded, for instance because it is not part of a transaction or the
+// transaction has already ended.
+func (s *Segment) EndWithDuration() time.Duration {
 	if s == nil {
-		return
+		return 0
 	}
-	if err := endBasic(s); err != nil {
+	duration, err := endBasic(s)
+	if err != nil {
 		s.StartTime.thread.logAPIError(err, "end segment", map[string]interface{}{
 			"name": s.Name,
 		})
 	}
+	return duration
 }
 
 // AddAttribute adds a key value pair to the current DatastoreSegment.
@@ -226,6 +268,9 @@ func (s *MessageProducerSegment) End() {
 	if nil == s {
 		return
 	}
+	for key, val := range s.Headers {
+		addSpanAttr(s.StartTime, MessageHeaderAttributePrefix+key, val)
+	}
 	if err := endMessage(s); err != nil {
 		s.StartTime.thread.logAPIError(err, "end message producer segment", map[string]interface{}{
 			"library":          s.Library,
@@ -299,6 +344,48 @@ func StartExternalSegment(txn *Transaction, request *http.Request) *ExternalSegm
 	return s
 }
 
+// StartExternalSegments starts an ExternalSegment for each request, like
+// StartExternalSegment does, for clients firing many concurrent external
+// calls such as the requests multiplexed over a single HTTP/2 connection.
+// The transaction lock is acquired once for all the segments, rather than
+// several times for each, so that the calls do not serialize on it.  Each
+// segment is started on its own goroutine of the transaction, as if by
+// Transaction.NewGoroutine: the segments are siblings and each may be ended
+// from the goroutine making its request.  The segments are returned in the
+// order of the requests.
+//
+//	segments := newrelic.StartExternalSegments(txn, requests)
+//	for i, req := range requests {
+//		go func(s *newrelic.ExternalSegment, req *http.Request) {
+//			defer s.End()
+//			s.Response, _ = client.Do(req)
+//		}(segments[i], req)
+//	}
+func StartExternalSegments(txn *Transaction, requests []*http.Request) []*ExternalSegment {
+	segments := make([]*ExternalSegment, len(requests))
+	if nil == txn || nil == txn.thread {
+		for i, request := range requests {
+			segments[i] = StartExternalSegment(txn, request)
+		}
+		return segments
+	}
+	starts, hdrs := txn.thread.startExternalSegments(txn.thread.Config.now(), len(requests))
+	for i, request := range requests {
+		segments[i] = &ExternalSegment{
+			StartTime: starts[i],
+			Request:   request,
+		}
+		if request != nil && request.Header != nil {
+			for key, values := range hdrs[i] {
+				for _, value := range values {
+					request.Header.Set(key, value)
+				}
+			}
+		}
+	}
+	return segments
+}
+
 func addSpanAttr(start SegmentStartTime, key string, val interface{}) {
 	if nil == start.thread {
 		return
@@ -0,0 +1,71 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+// +build go1.21
+
+package newrelic
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// ConfigSlogLogger populates the Config's Logger with an adapter routing the
+// agent's log messages to the slog.Logger.  The error, warn, info and debug
+// messages of the agent are logged at the slog.LevelError, slog.LevelWarn,
+// slog.LevelInfo and slog.LevelDebug levels respectively, with the context of
+// each message as attributes.  Debug messages are only built when the handler
+// of the logger is enabled at the debug level.  slog.Default is used if l is
+// nil.
+//
+//	newrelic.ConfigSlogLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+func ConfigSlogLogger(l *slog.Logger) ConfigOption {
+	if nil == l {
+		l = slog.Default()
+	}
+	return ConfigLogger(&slogLogger{logger: l})
+}
+
+// slogLogger adapts a slog.Logger to the Logger interface.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogLogger) log(level slog.Level, msg string, fields map[string]interface{}) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (l *slogLogger) Error(msg string, fields map[string]interface{}) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields map[string]interface{}) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields map[string]interface{}) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+func (l *slogLogger) Debug(msg string, fields map[string]interface{}) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+func (l *slogLogger) DebugEnabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelDebug)
+}
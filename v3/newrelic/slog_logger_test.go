@@ -0,0 +1,62 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+// +build go1.21
+
+package newrelic
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLoggerLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cfg := defaultConfig()
+	ConfigSlogLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})))(&cfg)
+
+	if cfg.Logger.DebugEnabled() {
+		t.Error("debug enabled for an info handler")
+	}
+	cfg.Logger.Error("error message", map[string]interface{}{"b": 2, "a": "one"})
+	cfg.Logger.Warn("warn message", nil)
+	cfg.Logger.Info("info message", nil)
+	cfg.Logger.Debug("debug message", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expect := []string{
+		`level=ERROR msg="error message" a=one b=2`,
+		`level=WARN msg="warn message"`,
+		`level=INFO msg="info message"`,
+	}
+	if len(lines) != len(expect) {
+		t.Fatal(buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expect[i]) {
+			t.Errorf("line %d: %s", i, line)
+		}
+	}
+}
+
+func TestSlogLoggerAgentDebugMessages(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app, err := NewApplication(
+		ConfigAppName("my app"),
+		ConfigLicense(testLicenseKey),
+		ConfigEnabled(false),
+		ConfigSlogLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	if nil != err {
+		t.Fatal(err)
+	}
+	app.StartTransaction("hello").End()
+
+	if out := buf.String(); !strings.Contains(out, `level=DEBUG msg="transaction ended"`) ||
+		!strings.Contains(out, "name=OtherTransaction/Go/hello") {
+		t.Error(out)
+	}
+}
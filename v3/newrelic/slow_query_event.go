@@ -0,0 +1,86 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"errors"
+	"time"
+)
+
+// SlowQueryEventType is the type of the custom events recorded by
+// Application.RecordSlowQuery.
+const SlowQueryEventType = "SlowQuery"
+
+// Attributes of the SlowQuery custom events.
+const (
+	slowQueryProductAttr   = "product"
+	slowQueryStatementAttr = "statement"
+	slowQueryDurationAttr  = "duration"
+	slowQueryHostAttr      = "host"
+	slowQueryRowsAttr      = "rows"
+)
+
+var (
+	errSlowQueryProduct  = errors.New("slow query product is required")
+	errSlowQueryDuration = errors.New("slow query duration must not be negative")
+)
+
+// SlowQueryData contains the fields of a slow query event recorded with
+// Application.RecordSlowQuery.
+type SlowQueryData struct {
+	// Product is the datastore product, such as DatastorePostgres.  It is
+	// required.
+	Product DatastoreProduct
+	// Statement is the query run.  Its literals are replaced with '?'
+	// placeholders, like the values of the parameters of the statement.
+	// It is omitted from the event when slow query collection is disabled,
+	// in Config.DatastoreTracer.SlowQuery or by the server, and when the
+	// record_sql security policy forbids recording SQL.  Like custom event
+	// attributes, it is truncated to 255 bytes.
+	Statement string
+	// Duration is the time spent running the query.  It is recorded in
+	// seconds.
+	Duration time.Duration
+	// Host is the name of the datastore host.  It is omitted from the event
+	// when empty.
+	Host string
+	// Rows is the number of rows returned or affected by the query.
+	Rows int64
+}
+
+// eventParams returns the attributes of the custom event recording the slow
+// query with the settings of the run.
+func (q *SlowQueryData) eventParams(run *appRun) (eventParams, error) {
+	if q.Product == "" {
+		return nil, errSlowQueryProduct
+	}
+	if q.Duration < 0 {
+		return nil, errSlowQueryDuration
+	}
+	params := eventParams{
+		slowQueryProductAttr:  string(q.Product),
+		slowQueryDurationAttr: q.Duration.Seconds(),
+		slowQueryRowsAttr:     q.Rows,
+	}
+	params.addString(slowQueryHostAttr, q.Host)
+	params.addString(slowQueryStatementAttr, q.statement(run))
+	return params, nil
+}
+
+// statement returns the obfuscated statement of the query, or "" when it
+// must not be recorded.
+func (q *SlowQueryData) statement(run *appRun) string {
+	if !run.Config.DatastoreTracer.SlowQuery.Enabled {
+		return ""
+	}
+	if policy := run.Reply.SecurityPolicies.RecordSQL; policy.IsSet() && !policy.Enabled() {
+		return ""
+	}
+	switch q.Product {
+	case DatastoreMySQL, DatastoreMSSQL:
+		return obfuscateSQL(q.Statement, true)
+	default:
+		return obfuscateSQL(q.Statement, false)
+	}
+}
@@ -8,6 +8,7 @@ package newrelic
 import (
 	"context"
 	"database/sql/driver"
+	"reflect"
 	"time"
 )
 
@@ -86,6 +87,45 @@ type wrapStmt struct {
 	original driver.Stmt
 }
 
+// wrapRows records a segment each time the query advances to another result
+// set, such as when a stored procedure returns several.  resultSet is the
+// 1-based index of the current result set.
+type wrapRows struct {
+	bld       SQLDriverSegmentBuilder
+	ctx       context.Context
+	original  driver.Rows
+	resultSet int
+}
+
+// The driver.Rows* interfaces embed driver.Rows.  These interfaces declare
+// only the methods they add so that they can be embedded alongside
+// driver.Rows by optionalMethodsRows.
+type (
+	rowsNextResultSet interface {
+		HasNextResultSet() bool
+		NextResultSet() error
+	}
+	rowsColumnTypeDatabaseTypeName interface {
+		ColumnTypeDatabaseTypeName(index int) string
+	}
+	rowsColumnTypeLength interface {
+		ColumnTypeLength(index int) (length int64, ok bool)
+	}
+	rowsColumnTypeNullable interface {
+		ColumnTypeNullable(index int) (nullable, ok bool)
+	}
+	rowsColumnTypePrecisionScale interface {
+		ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool)
+	}
+	rowsColumnTypeScanType interface {
+		ColumnTypeScanType(index int) reflect.Type
+	}
+)
+
+// sqlResultSetAttribute is the span attribute holding the index of the
+// result set a segment was recorded for.
+const sqlResultSetAttribute = "db.resultSet"
+
 func (w *wrapDriver) Open(name string) (driver.Conn, error) {
 	original, err := w.original.Open(name)
 	if err != nil {
@@ -137,6 +177,21 @@ func prepare(original driver.Stmt, err error, bld SQLDriverSegmentBuilder, query
 	}), nil
 }
 
+func queryRows(ctx context.Context, original driver.Rows, err error, bld SQLDriverSegmentBuilder) (driver.Rows, error) {
+	if nil != err {
+		return original, err
+	}
+	if _, ok := original.(driver.RowsNextResultSet); !ok {
+		return original, nil
+	}
+	return optionalMethodsRows(&wrapRows{
+		bld:       bld,
+		ctx:       ctx,
+		original:  original,
+		resultSet: 1,
+	}), nil
+}
+
 func (w *wrapConn) Prepare(query string) (driver.Stmt, error) {
 	original, err := w.original.Prepare(query)
 	return prepare(original, err, w.bld, query)
@@ -195,11 +250,13 @@ func (w *wrapConn) Query(query string, args []driver.Value) (driver.Rows, error)
 func (w *wrapConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
 	startTime := time.Now()
 	rows, err := w.original.(driver.QueryerContext).QueryContext(ctx, query, args)
-	if err != driver.ErrSkip {
-		seg := w.bld.useQuery(query).startSegmentAt(ctx, startTime)
-		seg.End()
+	if err == driver.ErrSkip {
+		return rows, err
 	}
-	return rows, err
+	bld := w.bld.useQuery(query)
+	seg := bld.startSegmentAt(ctx, startTime)
+	seg.End()
+	return queryRows(ctx, rows, err, bld)
 }
 
 // ResetSession implements SessionResetter.
@@ -246,7 +303,67 @@ func (w *wrapStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (
 	segment := w.bld.startSegment(ctx)
 	rows, err := w.original.(driver.StmtQueryContext).QueryContext(ctx, args)
 	segment.End()
-	return rows, err
+	return queryRows(ctx, rows, err, w.bld)
+}
+
+func (w *wrapRows) Columns() []string {
+	return w.original.Columns()
+}
+
+func (w *wrapRows) Close() error {
+	return w.original.Close()
+}
+
+func (w *wrapRows) Next(dest []driver.Value) error {
+	return w.original.Next(dest)
+}
+
+// HasNextResultSet implements RowsNextResultSet.
+func (w *wrapRows) HasNextResultSet() bool {
+	return w.original.(driver.RowsNextResultSet).HasNextResultSet()
+}
+
+// NextResultSet implements RowsNextResultSet.
+func (w *wrapRows) NextResultSet() error {
+	startTime := time.Now()
+	err := w.original.(driver.RowsNextResultSet).NextResultSet()
+	if nil != err {
+		return err
+	}
+	w.resultSet++
+	seg := w.bld.startSegmentAt(w.ctx, startTime)
+	// The result set index is recorded by the agent rather than the user,
+	// so security policy errors are not logged.
+	if thd := seg.StartTime.thread; nil != thd {
+		thd.AddUserSpanAttribute(sqlResultSetAttribute, w.resultSet)
+	}
+	seg.End()
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName implements RowsColumnTypeDatabaseTypeName.
+func (w *wrapRows) ColumnTypeDatabaseTypeName(index int) string {
+	return w.original.(driver.RowsColumnTypeDatabaseTypeName).ColumnTypeDatabaseTypeName(index)
+}
+
+// ColumnTypeLength implements RowsColumnTypeLength.
+func (w *wrapRows) ColumnTypeLength(index int) (int64, bool) {
+	return w.original.(driver.RowsColumnTypeLength).ColumnTypeLength(index)
+}
+
+// ColumnTypeNullable implements RowsColumnTypeNullable.
+func (w *wrapRows) ColumnTypeNullable(index int) (bool, bool) {
+	return w.original.(driver.RowsColumnTypeNullable).ColumnTypeNullable(index)
+}
+
+// ColumnTypePrecisionScale implements RowsColumnTypePrecisionScale.
+func (w *wrapRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	return w.original.(driver.RowsColumnTypePrecisionScale).ColumnTypePrecisionScale(index)
+}
+
+// ColumnTypeScanType implements RowsColumnTypeScanType.
+func (w *wrapRows) ColumnTypeScanType(index int) reflect.Type {
+	return w.original.(driver.RowsColumnTypeScanType).ColumnTypeScanType(index)
 }
 
 var (
@@ -275,4 +392,13 @@ var (
 		driver.StmtExecContext
 		driver.StmtQueryContext
 	} = &wrapStmt{}
+	_ interface {
+		driver.Rows
+		driver.RowsNextResultSet
+		driver.RowsColumnTypeDatabaseTypeName
+		driver.RowsColumnTypeLength
+		driver.RowsColumnTypeNullable
+		driver.RowsColumnTypePrecisionScale
+		driver.RowsColumnTypeScanType
+	} = &wrapRows{}
 )
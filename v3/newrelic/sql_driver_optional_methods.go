@@ -2241,3 +2241,273 @@ func optionalMethodsConn(conn *wrapConn) driver.Conn {
 		}{conn, conn, conn, conn, conn, conn, conn, conn, conn}
 	}
 }
+
+func optionalMethodsRows(rows *wrapRows) driver.Rows {
+	// GENERATED CODE DO NOT MODIFY
+	// This code generated by internal/tools/interface-wrapping
+	var (
+		i0 int32 = 1 << 0
+		i1 int32 = 1 << 1
+		i2 int32 = 1 << 2
+		i3 int32 = 1 << 3
+		i4 int32 = 1 << 4
+	)
+	var interfaceSet int32
+	if _, ok := rows.original.(rowsColumnTypeDatabaseTypeName); ok {
+		interfaceSet |= i0
+	}
+	if _, ok := rows.original.(rowsColumnTypeLength); ok {
+		interfaceSet |= i1
+	}
+	if _, ok := rows.original.(rowsColumnTypeNullable); ok {
+		interfaceSet |= i2
+	}
+	if _, ok := rows.original.(rowsColumnTypePrecisionScale); ok {
+		interfaceSet |= i3
+	}
+	if _, ok := rows.original.(rowsColumnTypeScanType); ok {
+		interfaceSet |= i4
+	}
+	switch interfaceSet {
+	default: // No optional interfaces implemented
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+		}{rows, rows}
+	case i0:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+		}{rows, rows, rows}
+	case i1:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+		}{rows, rows, rows}
+	case i0 | i1:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+		}{rows, rows, rows, rows}
+	case i2:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeNullable
+		}{rows, rows, rows}
+	case i0 | i2:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeNullable
+		}{rows, rows, rows, rows}
+	case i1 | i2:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+		}{rows, rows, rows, rows}
+	case i0 | i1 | i2:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+		}{rows, rows, rows, rows, rows}
+	case i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows}
+	case i0 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows}
+	case i1 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows}
+	case i0 | i1 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows, rows}
+	case i2 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows}
+	case i0 | i2 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows, rows}
+	case i1 | i2 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows, rows}
+	case i0 | i1 | i2 | i3:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+		}{rows, rows, rows, rows, rows, rows}
+	case i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeScanType
+		}{rows, rows, rows}
+	case i0 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows}
+	case i1 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows}
+	case i0 | i1 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i2 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeNullable
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows}
+	case i0 | i2 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeNullable
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i1 | i2 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i0 | i1 | i2 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows, rows}
+	case i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows}
+	case i0 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i1 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i0 | i1 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows, rows}
+	case i2 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows}
+	case i0 | i2 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows, rows}
+	case i1 | i2 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows, rows}
+	case i0 | i1 | i2 | i3 | i4:
+		return struct {
+			driver.Rows
+			rowsNextResultSet
+			rowsColumnTypeDatabaseTypeName
+			rowsColumnTypeLength
+			rowsColumnTypeNullable
+			rowsColumnTypePrecisionScale
+			rowsColumnTypeScanType
+		}{rows, rows, rows, rows, rows, rows, rows}
+	}
+}
@@ -8,6 +8,7 @@ package newrelic
 import (
 	"context"
 	"database/sql/driver"
+	"io"
 	"strings"
 	"testing"
 
@@ -278,3 +279,105 @@ func TestSQLNoTxnNoCry(t *testing.T) {
 	conn, _ := connector.Connect(nil)
 	conn.(driver.QueryerContext).QueryContext(context.Background(), "myoperation,mycollection", nil)
 }
+
+type testConnectorResultSets struct {
+	testConnector
+}
+
+func (c testConnectorResultSets) Connect(context.Context) (driver.Conn, error) {
+	return testConnResultSets{}, nil
+}
+
+type testConnResultSets struct {
+	testConn
+}
+
+func (c testConnResultSets) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &testResultSets{remaining: 1}, nil
+}
+
+// testResultSets mimics a stored procedure call returning two result sets.
+type testResultSets struct {
+	remaining int
+}
+
+func (r *testResultSets) Columns() []string              { return nil }
+func (r *testResultSets) Close() error                   { return nil }
+func (r *testResultSets) Next(dest []driver.Value) error { return io.EOF }
+func (r *testResultSets) HasNextResultSet() bool         { return r.remaining > 0 }
+func (r *testResultSets) NextResultSet() error {
+	if r.remaining == 0 {
+		return io.EOF
+	}
+	r.remaining--
+	return nil
+}
+
+func TestQueryContextMultipleResultSets(t *testing.T) {
+	// Test that each additional result set returned by a query is
+	// recorded as its own segment.
+	app := testApp(distributedTracingReplyFields, enableBetterCAT, t)
+	connector := InstrumentSQLConnector(testConnectorResultSets{}, testBuilder)
+	txn := app.StartTransaction("hello")
+	conn, _ := connector.Connect(nil)
+	ctx := NewContext(context.Background(), txn)
+	rows, err := conn.(driver.QueryerContext).QueryContext(ctx, "call,myprocedure", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	next, ok := rows.(driver.RowsNextResultSet)
+	if !ok {
+		t.Fatal("rows do not implement driver.RowsNextResultSet")
+	}
+	if _, ok := rows.(driver.RowsColumnTypeScanType); ok {
+		t.Error("rows implement optional interfaces missing from the driver")
+	}
+	if !next.HasNextResultSet() {
+		t.Fatal("expected a second result set")
+	}
+	if err := next.NextResultSet(); nil != err {
+		t.Fatal(err)
+	}
+	if err := next.NextResultSet(); err != io.EOF {
+		t.Fatal("expected io.EOF after the last result set, got", err)
+	}
+	rows.Close()
+	txn.End()
+	app.expectNoLoggedErrors(t)
+
+	parentGUID := "4981855ad8681d0d"
+	app.ExpectSpanEvents(t, []internal.WantEvent{
+		{
+			Intrinsics: map[string]interface{}{
+				"name":      "Datastore/statement/MySQL/myprocedure/call",
+				"parentId":  parentGUID,
+				"category":  "datastore",
+				"component": "MySQL",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":      "Datastore/statement/MySQL/myprocedure/call",
+				"parentId":  parentGUID,
+				"category":  "datastore",
+				"component": "MySQL",
+				"span.kind": "client",
+			},
+			UserAttributes: map[string]interface{}{
+				"db.resultSet": 2,
+			},
+		},
+		{
+			Intrinsics: map[string]interface{}{
+				"name":             "OtherTransaction/Go/hello",
+				"guid":             parentGUID,
+				"nr.entryPoint":    true,
+				"category":         "generic",
+				"transaction.name": "OtherTransaction/Go/hello",
+			},
+			AgentAttributes: map[string]interface{}{},
+		},
+	})
+}
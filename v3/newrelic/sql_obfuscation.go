@@ -0,0 +1,217 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"strings"
+)
+
+// obfuscateSQL replaces the literals of the SQL statement, such as strings,
+// numbers, booleans and UUIDs, with a '?' placeholder, and removes its
+// comments.  Double quotes delimit strings when doubleQuotedStrings is true,
+// as in MySQL, and identifiers otherwise.  A statement which cannot be
+// scanned, because a string is not terminated for instance, is replaced
+// entirely with a placeholder so that no literal is leaked.
+func obfuscateSQL(sql string, doubleQuotedStrings bool) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || (c == '"' && doubleQuotedStrings):
+			end, ok := scanSQLString(sql, i)
+			if !ok {
+				return "?"
+			}
+			b.WriteByte('?')
+			i = end
+		case c == '"' || c == '`':
+			// Quoted identifiers are kept.
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				return "?"
+			}
+			end += i + 2
+			b.WriteString(sql[i:end])
+			i = end
+		case c == '$' && i+1 < len(sql) && !isSQLDigit(sql[i+1]):
+			end, ok := scanSQLDollarQuote(sql, i)
+			if !ok {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			b.WriteByte('?')
+			i = end
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "--")):
+			end := strings.IndexAny(sql[i:], "\r\n")
+			if end < 0 {
+				end = len(sql) - i
+			}
+			b.WriteByte('?')
+			i += end
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			b.WriteByte('?')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case isSQLWordStart(c):
+			end := i + 1
+			for end < len(sql) && isSQLWordPart(sql[end]) {
+				end++
+			}
+			switch strings.ToLower(sql[i:end]) {
+			case "true", "false", "null":
+				b.WriteByte('?')
+			default:
+				b.WriteString(sql[i:end])
+			}
+			i = end
+		case c == '{' || isSQLDigit(c) ||
+			(c == '-' && i+1 < len(sql) && isSQLDigit(sql[i+1]) && !followsSQLOperand(b.String())):
+			if end, ok := scanSQLUUID(sql, i); ok {
+				b.WriteByte('?')
+				i = end
+				continue
+			}
+			if c == '{' {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			b.WriteByte('?')
+			i = scanSQLNumber(sql, i)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// scanSQLString returns the end of the string starting with the quote at
+// start.  A quote is escaped by doubling it.  A quote escaped with a
+// backslash makes the end of the string ambiguous: the rest of the
+// statement is then considered part of the string.
+func scanSQLString(sql string, start int) (int, bool) {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if i+1 < len(sql) && sql[i+1] == quote {
+				return len(sql), true
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// scanSQLDollarQuote returns the end of the PostgreSQL dollar-quoted string,
+// such as $tag$text$tag$, starting at start.
+func scanSQLDollarQuote(sql string, start int) (int, bool) {
+	tagEnd := start + 1
+	for tagEnd < len(sql) && isSQLWordPart(sql[tagEnd]) {
+		tagEnd++
+	}
+	if tagEnd >= len(sql) || sql[tagEnd] != '$' {
+		return 0, false
+	}
+	tag := sql[start : tagEnd+1]
+	end := strings.Index(sql[tagEnd+1:], tag)
+	if end < 0 {
+		return 0, false
+	}
+	return tagEnd + 1 + end + len(tag), true
+}
+
+// scanSQLUUID returns the end of the UUID starting at start: 32 hexadecimal
+// digits, with any dashes, optionally enclosed in braces.
+func scanSQLUUID(sql string, start int) (int, bool) {
+	i := start
+	if sql[i] == '{' {
+		i++
+	}
+	for digits := 0; digits < 32; digits++ {
+		if i >= len(sql) || !isSQLHexDigit(sql[i]) {
+			return 0, false
+		}
+		i++
+		for i < len(sql) && sql[i] == '-' {
+			i++
+		}
+	}
+	if i < len(sql) && isSQLWordPart(sql[i]) {
+		return 0, false
+	}
+	if i < len(sql) && sql[i] == '}' {
+		i++
+	}
+	return i, true
+}
+
+// scanSQLNumber returns the end of the number starting at start, which may
+// be negative, hexadecimal, decimal or in exponent notation.
+func scanSQLNumber(sql string, start int) int {
+	i := start
+	if sql[i] == '-' {
+		i++
+	}
+	if strings.HasPrefix(sql[i:], "0x") || strings.HasPrefix(sql[i:], "0X") {
+		i += 2
+		for i < len(sql) && isSQLHexDigit(sql[i]) {
+			i++
+		}
+		return i
+	}
+	for i < len(sql) && (isSQLDigit(sql[i]) || sql[i] == '.') {
+		i++
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && isSQLDigit(sql[j]) {
+			i = j
+			for i < len(sql) && isSQLDigit(sql[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+// followsSQLOperand reports whether the last non-space character written is
+// the end of an operand, in which case a dash is a minus operator rather
+// than the sign of a number.
+func followsSQLOperand(written string) bool {
+	s := strings.TrimRight(written, " \t\r\n")
+	if s == "" {
+		return false
+	}
+	c := s[len(s)-1]
+	return isSQLWordPart(c) || c == ')' || c == '?'
+}
+
+func isSQLDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isSQLHexDigit(c byte) bool {
+	return isSQLDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isSQLWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSQLWordPart(c byte) bool { return isSQLWordStart(c) || isSQLDigit(c) }
@@ -0,0 +1,51 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/internal/crossagent"
+)
+
+func TestObfuscateSQLCrossAgent(t *testing.T) {
+	var tcs []struct {
+		Name         string   `json:"name"`
+		SQL          string   `json:"sql"`
+		Obfuscated   []string `json:"obfuscated"`
+		Dialects     []string `json:"dialects"`
+		Pathological bool     `json:"pathological"`
+	}
+	if err := crossagent.ReadJSON("sql_obfuscation/sql_obfuscation.json", &tcs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range tcs {
+		// Pathological cases mixing comments and quotes are not
+		// supported.
+		if tc.Pathological {
+			continue
+		}
+		for _, dialect := range tc.Dialects {
+			var doubleQuotedStrings bool
+			switch dialect {
+			case "mysql":
+				doubleQuotedStrings = true
+			case "postgres":
+			default:
+				continue
+			}
+			out := obfuscateSQL(tc.SQL, doubleQuotedStrings)
+			found := false
+			for _, want := range tc.Obfuscated {
+				if out == want {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s (%s): got %q, want one of %q", tc.Name, dialect, out, tc.Obfuscated)
+			}
+		}
+	}
+}
@@ -11,7 +11,13 @@ import (
 )
 
 func extractTable(s string) string {
-	s = extractTableRegex.ReplaceAllString(s, "")
+	// Backtick-quoted identifiers may contain whitespace, which would
+	// otherwise be stripped along with the enclosing characters.
+	if m := backtickTableRegex.FindStringSubmatch(s); len(m) > 1 {
+		s = m[1]
+	} else {
+		s = extractTableRegex.ReplaceAllString(s, "")
+	}
 	if idx := strings.Index(s, "."); idx > 0 {
 		s = s[idx+1:]
 	}
@@ -19,15 +25,20 @@ func extractTable(s string) string {
 }
 
 var (
-	basicTable        = `[^)(\]\[\}\{\s,;]+`
+	basicTable        = "(?:`[^`]*`|" + `[^)(\]\[\}\{\s,;` + "`" + `])+`
 	enclosedTable     = `[\[\(\{]` + `\s*` + basicTable + `\s*` + `[\]\)\}]`
 	tablePattern      = `(` + `\s+` + basicTable + `|` + `\s*` + enclosedTable + `)`
 	extractTableRegex = regexp.MustCompile(`[\s` + "`" + `"'\(\)\{\}\[\]]*`)
-	updateRegex       = regexp.MustCompile(`(?is)^update(?:\s+(?:low_priority|ignore|or|rollback|abort|replace|fail|only))*` + tablePattern)
-	sqlOperations     = map[string]*regexp.Regexp{
-		"select":   regexp.MustCompile(`(?is)^.*\sfrom` + tablePattern),
-		"delete":   regexp.MustCompile(`(?is)^.*\sfrom` + tablePattern),
-		"insert":   regexp.MustCompile(`(?is)^.*\sinto?` + tablePattern),
+	// backtickTableRegex matches a trailing backtick-quoted table name,
+	// such as the `my table` in `database`.`my table`.
+	backtickTableRegex = regexp.MustCompile("(?:^|[.\\s\\[\\(\\{])`([^`]+)`" + `[\s\]\)\}]*$`)
+	updateRegex        = regexp.MustCompile(`(?is)^update(?:\s+(?:low_priority|ignore|or|rollback|abort|replace|fail|only))*` + tablePattern)
+	sqlOperations      = map[string]*regexp.Regexp{
+		"select": regexp.MustCompile(`(?is)^.*\sfrom` + tablePattern),
+		"delete": regexp.MustCompile(`(?is)^.*\sfrom` + tablePattern),
+		// The first INTO names the table: later occurrences may belong to
+		// string values or to an ON DUPLICATE KEY UPDATE clause.
+		"insert":   regexp.MustCompile(`(?is)^.*?\sinto?` + tablePattern),
 		"update":   updateRegex,
 		"call":     nil,
 		"create":   nil,
@@ -46,6 +57,25 @@ var (
 	sqlPrefixRegex   = regexp.MustCompile(`^[\s;]*`)
 )
 
+// firstStatement returns the first statement of a multi-statement query.
+// Semicolons within quoted strings and identifiers are ignored.
+func firstStatement(s string) string {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			return s[:i]
+		}
+	}
+	return s
+}
+
 // ParseQuery parses table and operation from the SQL query string.  It is
 // a helper meant to be used when writing database/sql driver instrumentation.
 // Check out full example usage here:
@@ -58,6 +88,7 @@ func ParseQuery(segment *newrelic.DatastoreSegment, query string) {
 	s := cCommentRegex.ReplaceAllString(query, "")
 	s = lineCommentRegex.ReplaceAllString(s, "")
 	s = sqlPrefixRegex.ReplaceAllString(s, "")
+	s = firstStatement(s)
 	op := strings.ToLower(firstWordRegex.FindString(s))
 	if rg, ok := sqlOperations[op]; ok {
 		segment.Operation = op
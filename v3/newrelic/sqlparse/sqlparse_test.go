@@ -196,3 +196,50 @@ func TestExtractTable(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSQLBacktickTable(t *testing.T) {
+	for _, tc := range []sqlTestcase{
+		{Input: "SELECT * FROM `users` WHERE id = ?", Operation: "select", Table: "users"},
+		{Input: "SELECT * FROM `my table` WHERE id = ?", Operation: "select", Table: "my table"},
+		{Input: "SELECT * FROM `db`.`my table`", Operation: "select", Table: "my table"},
+		{Input: "SELECT * FROM db.`users`", Operation: "select", Table: "users"},
+		{Input: "UPDATE `order items` SET qty = ?", Operation: "update", Table: "order items"},
+		{Input: "DELETE FROM `order items` WHERE id = ?", Operation: "delete", Table: "order items"},
+		{Input: "INSERT INTO `order items` (id) VALUES (?)", Operation: "insert", Table: "order items"},
+	} {
+		tc.test(t)
+	}
+}
+
+func TestParseSQLInsertOnDuplicateKeyUpdate(t *testing.T) {
+	for _, tc := range []sqlTestcase{
+		{
+			Input:     "INSERT INTO users (id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)",
+			Operation: "insert",
+			Table:     "users",
+		},
+		{
+			Input:     "INSERT INTO `users` (id, note) VALUES (?, 'moved into storage') ON DUPLICATE KEY UPDATE note = ?",
+			Operation: "insert",
+			Table:     "users",
+		},
+		{
+			Input:     "INSERT IGNORE INTO totals (id, n) SELECT id, n FROM counts ON DUPLICATE KEY UPDATE n = n + 1",
+			Operation: "insert",
+			Table:     "totals",
+		},
+	} {
+		tc.test(t)
+	}
+}
+
+func TestParseSQLMultiStatement(t *testing.T) {
+	for _, tc := range []sqlTestcase{
+		{Input: "SELECT * FROM foo; SELECT * FROM bar", Operation: "select", Table: "foo"},
+		{Input: "INSERT INTO foo VALUES (?); DELETE FROM bar", Operation: "insert", Table: "foo"},
+		{Input: "SELECT * FROM foo WHERE x = ';'; SELECT * FROM bar", Operation: "select", Table: "foo"},
+		{Input: "SELECT * FROM `foo;bar`; SELECT * FROM baz", Operation: "select", Table: "foo;bar"},
+	} {
+		tc.test(t)
+	}
+}
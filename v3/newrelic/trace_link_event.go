@@ -0,0 +1,41 @@
+// Copyright 2020 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"errors"
+)
+
+// TraceLinkEventType is the type of the custom events recorded by
+// Transaction.LinkDistributedTraceHeaders.
+const TraceLinkEventType = "TraceLink"
+
+// Attributes of the TraceLink custom events, along with the trace context.
+const (
+	traceLinkLinkedTraceIDAttr = "linked.trace.id"
+	traceLinkLinkedSpanIDAttr  = "linked.span.id"
+	traceLinkTransportAttr     = "linked.transport"
+)
+
+// traceLinksPerTransactionLimit is the maximum number of traces linked by a
+// transaction.
+const traceLinksPerTransactionLimit = 100
+
+var (
+	errTraceLinkNoPayload = errors.New("no distributed trace headers to link")
+	errTraceLinkLimit     = newEventLimitError("trace links", traceLinksPerTransactionLimit)
+)
+
+// traceLinkEventParams returns the attributes of the custom event linking
+// the trace of the transaction, whose metadata is given, to the trace of the
+// inbound payload.  The span IDs are omitted when empty.
+func traceLinkEventParams(metadata TraceMetadata, linked *payload, transport string) eventParams {
+	params := eventParams{
+		traceLinkLinkedTraceIDAttr: linked.TracedID,
+		traceLinkTransportAttr:     transport,
+	}
+	params.addTraceMetadata(metadata)
+	params.addString(traceLinkLinkedSpanIDAttr, linked.ID)
+	return params
+}
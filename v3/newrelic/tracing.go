@@ -91,6 +91,8 @@ type txnData struct {
 	ShouldCollectSpanEvents func() bool
 	ShouldCreateSpanGUID    func() bool
 	rootSpanErrData         *errorData
+	rootSpanUserAttributes  spanAttributeMap
+	baggage                 baggage   // Lazily initialized.
 	Errors                  txnErrors // Lazily initialized.
 	SpanEvents              []*spanEvent
 	logs                    logEventHeap
@@ -426,6 +428,19 @@ var (
 		`use https://godoc.org/github.com/newrelic/go-agent/v3/newrelic#Transaction.NewGoroutine to use the transaction in multiple goroutines`)
 )
 
+// segmentStartedAt returns the time the segment started, provided it is
+// still open.
+func segmentStartedAt(thread *tracingThread, start segmentStartTime) (time.Time, bool) {
+	if start.Stamp == 0 || start.Depth < 0 || start.Depth >= len(thread.stack) {
+		return time.Time{}, false
+	}
+	frame := thread.stack[start.Depth]
+	if start.Stamp != frame.Stamp {
+		return time.Time{}, false
+	}
+	return frame.segmentTime.Time, true
+}
+
 func endSegment(t *txnData, thread *tracingThread, start segmentStartTime, now time.Time) (segmentEnd, error) {
 	if start.Stamp == 0 {
 		return segmentEnd{}, errMalformedSegment
@@ -491,10 +506,10 @@ func endSegment(t *txnData, thread *tracingThread, start segmentStartTime, now t
 }
 
 // endBasicSegment ends a basic segment.
-func endBasicSegment(t *txnData, thread *tracingThread, start segmentStartTime, now time.Time, name string) error {
+func endBasicSegment(t *txnData, thread *tracingThread, start segmentStartTime, now time.Time, name string) (time.Duration, error) {
 	end, err := endSegment(t, thread, start, now)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if nil == t.customSegments {
 		t.customSegments = make(map[string]*metricData)
@@ -521,7 +536,7 @@ func endBasicSegment(t *txnData, thread *tracingThread, start segmentStartTime,
 		t.saveSpanEvent(evt)
 	}
 
-	return nil
+	return end.duration, nil
 }
 
 // endExternalParams contains the parameters for endExternalSegment.
@@ -643,6 +658,7 @@ type endMessageParams struct {
 	Library         string
 	DestinationType string
 	DestinationTemp bool
+	RoutingKey      string
 }
 
 // endMessageSegment ends an external segment.
@@ -676,12 +692,14 @@ func endMessageSegment(p endMessageParams) error {
 
 	if t.TxnTrace.considerNode(end) {
 		attributes := end.agentAttributes.copy()
+		attributes.addString(AttributeMessageRoutingKey, p.RoutingKey)
 		t.saveTraceSegment(end, key.Name(), attributes, "")
 	}
 
 	if evt := end.spanEvent(); evt != nil {
 		evt.Name = key.Name()
 		evt.Category = spanCategoryGeneric
+		evt.AgentAttributes.addString(AttributeMessageRoutingKey, p.RoutingKey)
 		t.saveSpanEvent(evt)
 	}
 
@@ -703,6 +721,7 @@ type endDatastoreParams struct {
 	PortPathOrID       string
 	Database           string
 	ThisHost           string
+	Explain            string
 }
 
 const (
@@ -803,6 +822,7 @@ func endDatastoreSegment(p endDatastoreParams) error {
 		attributes.addString(SpanAttributeDBInstance, p.Database)
 		attributes.addString(SpanAttributePeerAddress, datastoreSpanAddress(p.Host, p.PortPathOrID))
 		attributes.addString(SpanAttributePeerHostname, p.Host)
+		attributes.addString(SpanAttributeDBExplain, p.Explain)
 		if len(queryParams) > 0 {
 			attributes.add(spanAttributeQueryParameters, queryParams)
 		}
@@ -835,6 +855,7 @@ func endDatastoreSegment(p endDatastoreParams) error {
 		evt.AgentAttributes.addString(SpanAttributePeerAddress, datastoreSpanAddress(p.Host, p.PortPathOrID))
 		evt.AgentAttributes.addString(SpanAttributePeerHostname, p.Host)
 		evt.AgentAttributes.addString(SpanAttributeDBCollection, p.Collection)
+		evt.AgentAttributes.addString(SpanAttributeDBExplain, p.Explain)
 		p.TxnData.saveSpanEvent(evt)
 	}
 
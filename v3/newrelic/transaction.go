@@ -43,6 +43,25 @@ func (txn *Transaction) End() {
 	txn.thread.logAPIError(txn.thread.End(r), "end transaction", nil)
 }
 
+// EndWithTimestamps finishes the Transaction like End, except that the
+// transaction is recorded as having run from start to end rather than from
+// the time it was started to the current time.  Use it to record past
+// occurrences, for instance when backfilling from historical data.  The
+// Transaction is not ended and an error is logged if end is before start, or
+// if segments have been started in the Transaction since their times would
+// not match the start given.
+//
+// Unlike End, EndWithTimestamps does not recover panics.
+func (txn *Transaction) EndWithTimestamps(start, end time.Time) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.EndWithTimestamps(start, end), "end transaction", nil)
+}
+
 // SetOption allows the setting of some transaction TraceOption parameters
 // after the transaction has already been started, such as specifying a new
 // source code location for code-level metrics.
@@ -67,6 +86,27 @@ func (txn *Transaction) Ignore() {
 	txn.thread.logAPIError(txn.thread.Ignore(), "ignore transaction", nil)
 }
 
+// SetDistributedTracingEnabled enables or disables distributed tracing for
+// this transaction only, overriding Config.DistributedTracer.Enabled for
+// instance for a noisy internal endpoint.  While it is disabled, no
+// distributed trace headers are inserted by InsertDistributedTraceHeaders or
+// accepted by AcceptDistributedTraceHeaders, and no span events are collected.
+// Distributed tracing cannot be enabled for a transaction if it is disabled in
+// the Config.
+//
+// Call SetDistributedTracingEnabled before SetWebRequest or
+// SetWebRequestHTTP to prevent the headers of the request from being
+// accepted.
+func (txn *Transaction) SetDistributedTracingEnabled(enabled bool) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.SetDistributedTracingEnabled(enabled), "set distributed tracing enabled", nil)
+}
+
 // SetName names the transaction.  Use a limited set of unique names to
 // ensure that Transactions are grouped usefully.
 func (txn *Transaction) SetName(name string) {
@@ -79,6 +119,71 @@ func (txn *Transaction) SetName(name string) {
 	txn.thread.logAPIError(txn.thread.SetName(name), "set transaction name", nil)
 }
 
+// SetQueueTime records the time the Transaction waited in a queue before it
+// started, such as the time a job spent in the queue of a worker pool before
+// a worker picked it up.  It is the counterpart, for background
+// transactions, of the queue time read from the X-Queue-Start header of web
+// requests: the queue time is recorded in the "WorkerQueue/QueueTime" metric,
+// in the queueDuration attribute of the transaction event and in the
+// SpanAttributeQueueDuration attribute of the root span.  On a web
+// transaction, it replaces the queue time read from the request and is
+// recorded in the "WebFrontend/QueueTime" metric.
+//
+//	txn := app.StartTransaction("process-job")
+//	txn.SetQueueTime(time.Since(job.EnqueuedAt))
+func (txn *Transaction) SetQueueTime(d time.Duration) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.SetQueueTime(d), "set queue time", nil)
+}
+
+// SetPriorityBoost adds delta to the priority of the Transaction, which is
+// used to decide whether it is sampled and which of its events are kept when
+// limits are reached.  A positive delta makes the Transaction, and the
+// downstream transactions of its distributed trace, more likely to be
+// sampled.  The priority is clamped to the valid range.
+//
+// The sampling decision is made the first time it is needed: when the first
+// segment ends or an error is noticed if span events are enabled, when
+// distributed trace headers are inserted or accepted, when the trace metadata
+// or IsSampled is queried, or at the latest when the Transaction ends.  Call
+// SetPriorityBoost right after starting the Transaction to change the
+// decision.  Afterwards, only the priority within the decided range is
+// adjusted.
+func (txn *Transaction) SetPriorityBoost(delta float32) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.SetPriorityBoost(delta), "set priority boost", nil)
+}
+
+// SetSynthetic marks the Transaction as synthetic, as if it had been started
+// by a request from a New Relic Synthetics monitor, and records monitorID as
+// its monitor id.  Use it for internal synthetic checks and pings, so that
+// their transactions are told apart from real user traffic: the
+// nr.syntheticsMonitorId attribute is added to the transaction event and its
+// transaction trace is always collected.
+//
+// The resource and job ids of an inbound Synthetics header, if any, are kept.
+// The Synthetics header is not added to outbound requests, as it is only
+// propagated when received.
+func (txn *Transaction) SetSynthetic(monitorID string) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.SetSynthetic(monitorID), "set synthetic", nil)
+}
+
 // NoticeError records an error.  The Transaction saves the first five
 // errors.  For more control over the recorded error fields, see the
 // newrelic.Error type.
@@ -111,7 +216,51 @@ func (txn *Transaction) NoticeError(err error) {
 	if nil == txn.thread {
 		return
 	}
-	txn.thread.logAPIError(txn.thread.NoticeError(err, false), "notice error", nil)
+	txn.thread.logAPIError(txn.thread.NoticeError(err, false, nil, ""), "notice error", nil)
+}
+
+// NoticeErrorWithAttributes records an error with additional attributes, such
+// as request specific context, which are added to the error event and error
+// trace.  It otherwise works like NoticeError.  The attributes are validated
+// like those of the ErrorAttributes method: each value must be a number,
+// string, or boolean, and at most 32 attributes may be recorded, including
+// those returned by ErrorAttributes.  When a key is also returned by
+// ErrorAttributes, the value given here is used.  The attributes are not
+// recorded in high security mode or when custom parameters are disabled by
+// security policy.
+func (txn *Transaction) NoticeErrorWithAttributes(err error, attrs map[string]interface{}) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.NoticeError(err, false, attrs, ""), "notice error", nil)
+}
+
+// NoticeErrorWithGroupKey records an error with a key controlling how New
+// Relic groups it with other errors: errors recorded with the same group key
+// are grouped together, whatever their messages and classes.  The key is
+// recorded as the "error.group.name" attribute of the error event and trace.
+// It otherwise works like NoticeError.  The group key must be non-empty and
+// contain at most 255 bytes, or the error is not recorded and an error is
+// logged.
+func (txn *Transaction) NoticeErrorWithGroupKey(err error, groupKey string) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	if "" == groupKey {
+		txn.thread.logAPIError(errEmptyErrorGroupKey, "notice error", nil)
+		return
+	}
+	if len(groupKey) > attributeValueLengthLimit {
+		txn.thread.logAPIError(errErrorGroupKeyTooLong, "notice error", nil)
+		return
+	}
+	txn.thread.logAPIError(txn.thread.NoticeError(err, false, nil, groupKey), "notice error", nil)
 }
 
 // NoticeExpectedError records an error that was expected to occur. Errors recoreded with this
@@ -148,7 +297,7 @@ func (txn *Transaction) NoticeExpectedError(err error) {
 	if nil == txn.thread {
 		return
 	}
-	txn.thread.logAPIError(txn.thread.NoticeError(err, true), "notice error", nil)
+	txn.thread.logAPIError(txn.thread.NoticeError(err, true, nil, ""), "notice error", nil)
 }
 
 // AddAttribute adds a key value pair to the transaction event, errors,
@@ -169,6 +318,84 @@ func (txn *Transaction) AddAttribute(key string, value interface{}) {
 	txn.thread.logAPIError(txn.thread.AddAttribute(key, value), "add attribute", nil)
 }
 
+// AddSpanAttribute adds a key value pair to the span of the segment currently
+// active on this goroutine, or to the root span of the transaction if no
+// segment is active.  Unlike AddAttribute, the attribute is only added to
+// that span event, not to the transaction events, transaction traces, or
+// errors.
+//
+// The key must contain fewer than 255 bytes.  The value must be a
+// number, string, or boolean.  At most 64 such attributes may be added to a
+// span.
+func (txn *Transaction) AddSpanAttribute(key string, value interface{}) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	validatedVal, err := validateUserAttribute(key, value)
+	if nil == err {
+		err = txn.thread.AddSpanAttribute(key, validatedVal)
+	}
+	txn.thread.logAPIError(err, "add span attribute", nil)
+}
+
+// SetBaggage sets a key value pair which is propagated to downstream
+// services in the W3C baggage header by InsertDistributedTraceHeaders.
+// Entries received by AcceptDistributedTraceHeaders are available through
+// GetBaggage, and are propagated further unless replaced, even when the trace
+// payload of the headers is not accepted.
+//
+// The key must be a valid HTTP token.  At most 64 entries, with a total
+// encoded size of 8192 bytes, may be set.
+func (txn *Transaction) SetBaggage(key, value string) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.SetBaggage(key, value), "set baggage", map[string]interface{}{
+		"key": key,
+	})
+}
+
+// GetBaggage returns the value of the baggage entry with the given key, or
+// the empty string if there is no such entry.
+func (txn *Transaction) GetBaggage(key string) string {
+	if nil == txn {
+		return ""
+	}
+	if nil == txn.thread {
+		return ""
+	}
+	return txn.thread.GetBaggage(key)
+}
+
+// RecordFeatureFlag records the evaluation of a feature flag as a custom
+// event of type FeatureFlagEventType, with the key and the value of the flag
+// in its "flag.key" and "flag.value" attributes.  When distributed tracing is
+// enabled, the event also holds the "trace.id" and "span.id" of the
+// transaction, so that the behavior of the trace can be correlated with the
+// state of the flag.
+//
+// The value must be a number, string, or boolean.  At most 64 evaluations
+// are recorded per transaction, and the event is subject to the same
+// restrictions as the events recorded with Application.RecordCustomEvent.
+// An error is logged if the evaluation cannot be recorded.
+func (txn *Transaction) RecordFeatureFlag(key string, value interface{}) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.RecordFeatureFlag(key, value), "record feature flag", map[string]interface{}{
+		"key": key,
+	})
+}
+
 // RecordLog records the data from a single log line.
 // This consumes a LogData object that should be configured
 // with data taken from a logging framework.
@@ -266,7 +493,15 @@ func (txn *Transaction) SetWebResponse(w http.ResponseWriter) http.ResponseWrite
 // ExternalSegment.  The returned SegmentStartTime is safe to use even  when the
 // Transaction receiver is nil.  In this case, the segment will have no effect.
 func (txn *Transaction) StartSegmentNow() SegmentStartTime {
-	return txn.startSegmentAt(time.Now())
+	return txn.startSegmentAt(txn.now())
+}
+
+// now returns the current time according to the application's Clock.
+func (txn *Transaction) now() time.Time {
+	if nil == txn || nil == txn.thread {
+		return time.Now()
+	}
+	return txn.thread.Config.now()
 }
 
 func (txn *Transaction) startSegmentAt(at time.Time) SegmentStartTime {
@@ -299,6 +534,36 @@ func (txn *Transaction) StartSegment(name string) *Segment {
 	}
 }
 
+// StartExternalSegmentURL starts the instrumentation of an external call made
+// without an http.Request, such as with a non standard library client.  The
+// Host of the segment is set from the URL, which must include the protocol
+// scheme (eg. "http://"), and its Procedure is set to the method, which
+// defaults to "GET" when empty.  An error is logged when the segment is ended
+// if the URL cannot be parsed.
+//
+// Unlike StartExternalSegment, no distributed tracing headers are added to the
+// request: use InsertDistributedTraceHeaders to add them.
+//
+//	seg := txn.StartExternalSegmentURL("POST", "https://example.com/users")
+//	hdrs := http.Header{}
+//	txn.InsertDistributedTraceHeaders(hdrs)
+//	// ... make the call with the headers here ...
+//	seg.End()
+func (txn *Transaction) StartExternalSegmentURL(method, rawURL string) *ExternalSegment {
+	if method == "" {
+		method = "GET"
+	}
+	s := &ExternalSegment{
+		StartTime: txn.StartSegmentNow(),
+		URL:       rawURL,
+		Procedure: method,
+	}
+	if u, err := url.Parse(rawURL); nil == err {
+		s.Host = u.Host
+	}
+	return s
+}
+
 // InsertDistributedTraceHeaders adds the Distributed Trace headers used to
 // link transactions.  InsertDistributedTraceHeaders should be called every
 // time an outbound call is made since the payload contains a timestamp.
@@ -344,6 +609,65 @@ func (txn *Transaction) AcceptDistributedTraceHeaders(t TransportType, hdrs http
 	txn.thread.logAPIError(txn.thread.AcceptDistributedTraceHeaders(t, hdrs), "accept trace payload", nil)
 }
 
+// LinkDistributedTraceHeaders links the trace of the transaction to the
+// trace of the distributed trace headers of another transaction, without
+// accepting them: the transaction remains the root of its own trace.  Use it
+// in a transaction processing a batch of items, each with its own upstream
+// trace, to link the transaction to all of them:
+//
+//	txn := app.StartTransaction("processBatch")
+//	defer txn.End()
+//	for _, msg := range batch {
+//		txn.LinkDistributedTraceHeaders(newrelic.TransportQueue, msg.Headers)
+//	}
+//
+// Each link is recorded as a custom event of type TraceLinkEventType, with
+// the "trace.id" and "span.id" of the transaction and the
+// "linked.trace.id" and "linked.span.id" of the headers, so that the traces
+// can be queried together.  Like AcceptDistributedTraceHeaders, it first
+// looks for the W3C trace context headers, and rejects the headers of
+// accounts which are not trusted.  At most 100 traces are linked per
+// transaction, and distributed tracing must be enabled.  An error is logged
+// if the headers cannot be linked.
+func (txn *Transaction) LinkDistributedTraceHeaders(t TransportType, hdrs http.Header) {
+	if nil == txn {
+		return
+	}
+	if nil == txn.thread {
+		return
+	}
+	txn.thread.logAPIError(txn.thread.LinkDistributedTraceHeaders(t, hdrs), "link trace payload", nil)
+}
+
+// InsertDistributedTraceHeadersMap works just like
+// InsertDistributedTraceHeaders, except that it writes the headers into a map
+// rather than an http.Header.  Use it to propagate distributed tracing in
+// non-HTTP carriers such as message headers.  The keys written are the
+// lowercase header names, eg. "traceparent", "tracestate", and "newrelic".
+// AcceptDistributedTraceHeadersMap accepts the headers on the receiving side.
+func (txn *Transaction) InsertDistributedTraceHeadersMap(m map[string]string) {
+	if nil == m {
+		return
+	}
+	hdrs := http.Header{}
+	txn.InsertDistributedTraceHeaders(hdrs)
+	for key := range hdrs {
+		m[strings.ToLower(key)] = hdrs.Get(key)
+	}
+}
+
+// AcceptDistributedTraceHeadersMap works just like
+// AcceptDistributedTraceHeaders, except that it reads the headers from a map
+// rather than an http.Header.  Keys are matched regardless of case.  Use it to
+// accept the headers written by InsertDistributedTraceHeadersMap.
+func (txn *Transaction) AcceptDistributedTraceHeadersMap(t TransportType, m map[string]string) {
+	hdrs := make(http.Header, len(m))
+	for key, val := range m {
+		hdrs.Set(key, val)
+	}
+	txn.AcceptDistributedTraceHeaders(t, hdrs)
+}
+
 // AcceptDistributedTraceHeadersFromJSON works just like AcceptDistributedTraceHeaders(), except
 // that it takes the header data as a JSON string à la DistributedTraceHeadersFromJSON(). Additionally
 // (unlike AcceptDistributedTraceHeaders()) it returns an error if it was unable to successfully
@@ -540,6 +864,9 @@ const (
 	// DistributedTraceW3CTraceParentHeader is one of two headers used by W3C
 	// trace context
 	DistributedTraceW3CTraceParentHeader = "Traceparent"
+	// DistributedTraceW3CBaggageHeader is the header used by W3C baggage to
+	// propagate entries set with Transaction.SetBaggage
+	DistributedTraceW3CBaggageHeader = "Baggage"
 )
 
 // TransportType is used in Transaction.AcceptDistributedTraceHeaders to
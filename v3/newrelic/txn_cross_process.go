@@ -338,6 +338,21 @@ func (txp *txnCrossProcess) handleInboundRequestSynthetics(raw []byte) error {
 	return nil
 }
 
+// setSyntheticsMonitor marks the transaction as synthetic without an inbound
+// Synthetics header, keeping the resource and job ids of an inbound header if
+// there was one.
+func (txp *txnCrossProcess) setSyntheticsMonitor(monitorID string) {
+	synthetics := &cat.SyntheticsHeader{}
+	if txp.IsSynthetics() {
+		*synthetics = *txp.Synthetics
+	}
+	synthetics.MonitorID = monitorID
+
+	txp.SetSynthetics(true)
+	txp.setRequireGUID()
+	txp.Synthetics = synthetics
+}
+
 func (txp *txnCrossProcess) outboundID() (string, error) {
 	return obfuscate(txp.CrossProcessID, txp.EncodingKey)
 }